@@ -0,0 +1,22 @@
+package apierror
+
+import "net/http"
+
+// Error is an error carrying the HTTP status GoFr responds with. GoFr picks
+// the status up through the StatusCode method.
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) StatusCode() int {
+	return e.Status
+}
+
+func Forbidden(message string) error {
+	return &Error{Status: http.StatusForbidden, Message: message}
+}
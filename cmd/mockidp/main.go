@@ -0,0 +1,35 @@
+// Command mockidp runs the mock OpenID Connect provider from oidc/oidctest
+// so single sign-on can be tried locally without a real identity provider.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/sksmagr23/url-shortener-gofr/oidc/oidctest"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:9000", "address to listen on")
+	clientID := flag.String("client-id", "url-shortener", "client ID to accept")
+	groups := flag.String("groups", "", "comma-separated groups claim added to every ID token")
+	flag.Parse()
+
+	server, err := oidctest.Listen(*addr, *clientID)
+	if err != nil {
+		fmt.Println("Error starting mock identity provider:", err)
+		os.Exit(1)
+	}
+	defer server.Close()
+	if *groups != "" {
+		server.Claims["groups"] = strings.Split(*groups, ",")
+	}
+	fmt.Println("Mock identity provider listening, issuer:", server.URL)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	<-stop
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"slices"
+
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/http/response"
+
+	gofrHttp "gofr.dev/pkg/gofr/http"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+)
+
+type AnalyticsHandler struct {
+	Service service.AnalyticsService
+}
+
+func NewAnalyticsHandler(service service.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{Service: service}
+}
+
+// GET /urls/{short_code}/analytics?from=&to=&granularity=minute|hour|day&group_by=
+func (h *AnalyticsHandler) Link(ctx *gofr.Context) (interface{}, error) {
+	query, err := parseAnalyticsQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query.GroupBy = ctx.Param("group_by")
+	if query.GroupBy != "" && !slices.Contains(model.Dimensions, query.GroupBy) {
+		return nil, gofrHttp.ErrorInvalidParam{Params: []string{"group_by"}}
+	}
+
+	analytics, err := h.Service.Link(ctx, ctx.PathParam("short_code"), query)
+	if err != nil {
+		return nil, err
+	}
+	return analytics, nil
+}
+
+// GET /analytics/export?short_code=|tag=|campaign_id=&from=&to=&granularity=&format=csv|json
+func (h *AnalyticsHandler) Export(ctx *gofr.Context) (interface{}, error) {
+	query, err := parseAnalyticsQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	format := ctx.Param("format")
+	if format != "" && format != model.FormatJSON && format != model.FormatCSV {
+		return nil, gofrHttp.ErrorInvalidParam{Params: []string{"format"}}
+	}
+	scope := model.ReportScope{
+		ShortCode:  ctx.Param("short_code"),
+		Tag:        ctx.Param("tag"),
+		CampaignID: ctx.Param("campaign_id"),
+	}
+
+	rows, err := h.Service.Export(ctx, scope, query)
+	if err != nil {
+		return nil, err
+	}
+	if format != model.FormatCSV {
+		return rows, nil
+	}
+	content, err := service.RenderExport(rows)
+	if err != nil {
+		return nil, err
+	}
+	return response.File{Content: content, ContentType: "text/csv"}, nil
+}
+
+// parseAnalyticsQuery reads the from, to and granularity query parameters.
+func parseAnalyticsQuery(ctx *gofr.Context) (model.AnalyticsQuery, error) {
+	query := model.AnalyticsQuery{Granularity: ctx.Param("granularity")}
+	switch query.Granularity {
+	case "", model.GranularityMinute, model.GranularityHour, model.GranularityDay:
+	default:
+		return query, gofrHttp.ErrorInvalidParam{Params: []string{"granularity"}}
+	}
+	var err error
+	if query.From, err = parseTimeParam(ctx, "from"); err != nil {
+		return query, err
+	}
+	if query.To, err = parseTimeParam(ctx, "to"); err != nil {
+		return query, err
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && query.From.After(query.To) {
+		return query, gofrHttp.ErrorInvalidParam{Params: []string{"from", "to"}}
+	}
+	return query, nil
+}
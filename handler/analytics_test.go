@@ -0,0 +1,106 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	gorillamux "github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	gofrHttp "gofr.dev/pkg/gofr/http"
+
+	"github.com/sksmagr23/url-shortener-gofr/handler"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+type MockAnalyticsService struct {
+	mock.Mock
+}
+
+func (m *MockAnalyticsService) Link(ctx *gofr.Context, code string, query model.AnalyticsQuery) (*model.LinkAnalytics, error) {
+	args := m.Called(ctx, code, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.LinkAnalytics), args.Error(1)
+}
+
+func (m *MockAnalyticsService) Export(ctx *gofr.Context, scope model.ReportScope, query model.AnalyticsQuery) ([]model.ExportRow, error) {
+	args := m.Called(ctx, scope, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.ExportRow), args.Error(1)
+}
+
+func TestAnalyticsLinkHandler(t *testing.T) {
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		query         string
+		expectedQuery *model.AnalyticsQuery
+		expectedError error
+	}{
+		{
+			name:          "Success",
+			query:         "?from=2024-05-01T00:00:00Z&granularity=hour&group_by=browser",
+			expectedQuery: &model.AnalyticsQuery{From: from, Granularity: model.GranularityHour, GroupBy: model.DimensionBrowser},
+		},
+		{name: "Success - Defaults", expectedQuery: &model.AnalyticsQuery{}},
+		{
+			name:          "Failure - Invalid Granularity",
+			query:         "?granularity=week",
+			expectedError: gofrHttp.ErrorInvalidParam{Params: []string{"granularity"}},
+		},
+		{
+			name:          "Failure - Invalid Group By",
+			query:         "?group_by=country",
+			expectedError: gofrHttp.ErrorInvalidParam{Params: []string{"group_by"}},
+		},
+		{
+			name:          "Failure - Invalid From",
+			query:         "?from=yesterday",
+			expectedError: gofrHttp.ErrorInvalidParam{Params: []string{"from"}},
+		},
+		{
+			name:          "Failure - From After To",
+			query:         "?from=2024-05-02T00:00:00Z&to=2024-05-01T00:00:00Z",
+			expectedError: gofrHttp.ErrorInvalidParam{Params: []string{"from", "to"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContainer, _ := container.NewMockContainer(t)
+			mockService := &MockAnalyticsService{}
+			expected := &model.LinkAnalytics{ShortCode: "abc123", Clicks: 3}
+			if tt.expectedQuery != nil {
+				mockService.On("Link", mock.Anything, "abc123", *tt.expectedQuery).Return(expected, nil)
+			}
+			analyticsHandler := handler.NewAnalyticsHandler(mockService)
+
+			req := httptest.NewRequest(http.MethodGet, "/urls/abc123/analytics"+tt.query, nil)
+			req = gorillamux.SetURLVars(req, map[string]string{"short_code": "abc123"})
+			ctx := &gofr.Context{
+				Context:   context.Background(),
+				Request:   gofrHttp.NewRequest(req),
+				Container: mockContainer,
+			}
+
+			result, err := analyticsHandler.Link(ctx)
+
+			assert.Equal(t, tt.expectedError, err)
+			if tt.expectedError == nil {
+				assert.Equal(t, expected, result)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
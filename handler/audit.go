@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/http/response"
+
+	gofrHttp "gofr.dev/pkg/gofr/http"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+)
+
+type AuditHandler struct {
+	Service    service.AuditService
+	AdminToken string
+}
+
+func NewAuditHandler(service service.AuditService, adminToken string) *AuditHandler {
+	return &AuditHandler{Service: service, AdminToken: adminToken}
+}
+
+// GET /admin/audit?actor=&action=&resource=&from=&to=&format=jsonl
+func (h *AuditHandler) Query(ctx *gofr.Context) (interface{}, error) {
+	if !middleware.IsAdmin(ctx, h.AdminToken) {
+		return nil, apierror.Forbidden("admin token required")
+	}
+	filter := model.AuditFilter{
+		Actor:    ctx.Param("actor"),
+		Action:   ctx.Param("action"),
+		Resource: ctx.Param("resource"),
+	}
+	var err error
+	if filter.From, err = parseTimeParam(ctx, "from"); err != nil {
+		return nil, err
+	}
+	if filter.To, err = parseTimeParam(ctx, "to"); err != nil {
+		return nil, err
+	}
+
+	entries, err := h.Service.Query(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Param("format") != "jsonl" {
+		return entries, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return nil, err
+		}
+	}
+	return response.File{Content: buf.Bytes(), ContentType: "application/x-ndjson"}, nil
+}
+
+// parseTimeParam reads an optional RFC 3339 query parameter.
+func parseTimeParam(ctx *gofr.Context, name string) (time.Time, error) {
+	raw := ctx.Param(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, gofrHttp.ErrorInvalidParam{Params: []string{name}}
+	}
+	return parsed, nil
+}
@@ -0,0 +1,102 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+	"gofr.dev/pkg/gofr/http/response"
+
+	gofrHttp "gofr.dev/pkg/gofr/http"
+
+	"github.com/sksmagr23/url-shortener-gofr/handler"
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+type MockAuditService struct {
+	mock.Mock
+}
+
+func (m *MockAuditService) Query(ctx *gofr.Context, filter model.AuditFilter) ([]model.AuditEntry, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.AuditEntry), args.Error(1)
+}
+
+func adminContext(t *testing.T, target, token string) *gofr.Context {
+	mockContainer, _ := container.NewMockContainer(t)
+	meta := middleware.GetRequestMeta(context.Background())
+	if token != "" {
+		meta.Header.Set(middleware.AdminHeader, token)
+	}
+	return &gofr.Context{
+		Context:   middleware.WithRequestMeta(context.Background(), meta),
+		Request:   gofrHttp.NewRequest(httptest.NewRequest(http.MethodGet, target, nil)),
+		Container: mockContainer,
+	}
+}
+
+func TestAuditQueryHandler(t *testing.T) {
+	entries := []model.AuditEntry{
+		{ID: "1", Action: model.AuditLinkCreate, Actor: "alice"},
+		{ID: "2", Action: model.AuditLinkUpdate, Actor: "alice"},
+	}
+
+	tests := []struct {
+		name        string
+		target      string
+		token       string
+		expectCall  bool
+		expectError bool
+		expectJSONL bool
+	}{
+		{name: "Failure - Missing Token", target: "/admin/audit", expectError: true},
+		{name: "Failure - Wrong Token", target: "/admin/audit", token: "nope", expectError: true},
+		{name: "Failure - Invalid From", target: "/admin/audit?from=yesterday", token: "secret", expectError: true},
+		{name: "Success - JSON", target: "/admin/audit?actor=alice", token: "secret", expectCall: true},
+		{
+			name:        "Success - JSON Lines",
+			target:      "/admin/audit?actor=alice&format=jsonl",
+			token:       "secret",
+			expectCall:  true,
+			expectJSONL: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockAuditService{}
+			if tt.expectCall {
+				mockService.On("Query", mock.Anything, model.AuditFilter{Actor: "alice"}).Return(entries, nil)
+			}
+			auditHandler := handler.NewAuditHandler(mockService, "secret")
+
+			result, err := auditHandler.Query(adminContext(t, tt.target, tt.token))
+			if tt.expectError {
+				assert.Error(t, err)
+				mockService.AssertNotCalled(t, "Query", mock.Anything, mock.Anything)
+				return
+			}
+
+			assert.NoError(t, err)
+			mockService.AssertExpectations(t)
+			if !tt.expectJSONL {
+				assert.Equal(t, entries, result)
+				return
+			}
+			file, ok := result.(response.File)
+			assert.True(t, ok, "Expected result to be response.File")
+			assert.Equal(t, "application/x-ndjson", file.ContentType)
+			assert.Len(t, strings.Split(strings.TrimSpace(string(file.Content)), "\n"), 2)
+		})
+	}
+}
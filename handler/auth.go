@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/http/response"
+
+	gofrHttp "gofr.dev/pkg/gofr/http"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+)
+
+type AuthHandler struct {
+	Service service.SSOService
+}
+
+func NewAuthHandler(service service.SSOService) *AuthHandler {
+	return &AuthHandler{Service: service}
+}
+
+// GET /auth/login?login_hint=
+func (h *AuthHandler) Login(ctx *gofr.Context) (interface{}, error) {
+	url, err := h.Service.Login(ctx, ctx.Param("login_hint"))
+	if err != nil {
+		return nil, err
+	}
+	return response.Redirect{URL: url}, nil
+}
+
+// GET /auth/callback?code=&state=
+func (h *AuthHandler) Callback(ctx *gofr.Context) (interface{}, error) {
+	if reason := ctx.Param("error"); reason != "" {
+		return nil, apierror.Forbidden("sign-in was refused by the identity provider: " + reason)
+	}
+	code, state := ctx.Param("code"), ctx.Param("state")
+	if code == "" || state == "" {
+		return nil, gofrHttp.ErrorMissingParam{Params: []string{"code", "state"}}
+	}
+	session, err := h.Service.Callback(ctx, code, state)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+)
+
+type CampaignHandler struct {
+	Service service.CampaignService
+}
+
+func NewCampaignHandler(service service.CampaignService) *CampaignHandler {
+	return &CampaignHandler{Service: service}
+}
+
+// POST /campaigns
+func (h *CampaignHandler) Create(ctx *gofr.Context) (interface{}, error) {
+	var req model.CreateCampaignRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+	campaign, err := h.Service.Create(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	return campaign, nil
+}
+
+// GET /campaigns?workspace=
+func (h *CampaignHandler) List(ctx *gofr.Context) (interface{}, error) {
+	campaigns, err := h.Service.List(ctx, ctx.Param("workspace"))
+	if err != nil {
+		return nil, err
+	}
+	return campaigns, nil
+}
+
+// GET /campaigns/{id}
+func (h *CampaignHandler) Get(ctx *gofr.Context) (interface{}, error) {
+	campaign, err := h.Service.Get(ctx, ctx.PathParam("id"))
+	if err != nil {
+		return nil, err
+	}
+	return campaign, nil
+}
+
+// GET /campaigns/{id}/analytics
+func (h *CampaignHandler) Analytics(ctx *gofr.Context) (interface{}, error) {
+	analytics, err := h.Service.Analytics(ctx, ctx.PathParam("id"))
+	if err != nil {
+		return nil, err
+	}
+	return analytics, nil
+}
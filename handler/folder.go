@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+)
+
+type FolderHandler struct {
+	Service service.FolderService
+}
+
+func NewFolderHandler(service service.FolderService) *FolderHandler {
+	return &FolderHandler{Service: service}
+}
+
+// POST /folders
+func (h *FolderHandler) Create(ctx *gofr.Context) (interface{}, error) {
+	var req model.CreateFolderRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+	folder, err := h.Service.Create(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+// GET /folders
+func (h *FolderHandler) List(ctx *gofr.Context) (interface{}, error) {
+	folders, err := h.Service.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return folders, nil
+}
+
+// DELETE /folders/{id}
+func (h *FolderHandler) Delete(ctx *gofr.Context) (interface{}, error) {
+	if err := h.Service.Delete(ctx, ctx.PathParam("id")); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
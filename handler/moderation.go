@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+)
+
+type ModerationHandler struct {
+	Service    service.ModerationService
+	AdminToken string
+}
+
+func NewModerationHandler(service service.ModerationService, adminToken string) *ModerationHandler {
+	return &ModerationHandler{Service: service, AdminToken: adminToken}
+}
+
+// POST /{short_code}/report
+func (h *ModerationHandler) Report(ctx *gofr.Context) (interface{}, error) {
+	var req model.ReportAbuseRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+	report, err := h.Service.Report(ctx, ctx.PathParam("short_code"), &req)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// GET /admin/moderation
+func (h *ModerationHandler) Queue(ctx *gofr.Context) (interface{}, error) {
+	if !middleware.IsAdmin(ctx, h.AdminToken) {
+		return nil, apierror.Forbidden("admin token required")
+	}
+	items, err := h.Service.Queue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// POST /admin/moderation/{short_code}
+func (h *ModerationHandler) Act(ctx *gofr.Context) (interface{}, error) {
+	if !middleware.IsAdmin(ctx, h.AdminToken) {
+		return nil, apierror.Forbidden("admin token required")
+	}
+	var req model.ModerationRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+	link, err := h.Service.Act(ctx, ctx.PathParam("short_code"), &req)
+	if err != nil {
+		return nil, err
+	}
+	return link, nil
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+)
+
+type PolicyHandler struct {
+	Service    service.PolicyService
+	AdminToken string
+}
+
+func NewPolicyHandler(service service.PolicyService, adminToken string) *PolicyHandler {
+	return &PolicyHandler{Service: service, AdminToken: adminToken}
+}
+
+// POST /admin/policies
+func (h *PolicyHandler) Create(ctx *gofr.Context) (interface{}, error) {
+	if !middleware.IsAdmin(ctx, h.AdminToken) {
+		return nil, apierror.Forbidden("admin token required")
+	}
+	var req model.CreatePolicyRuleRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+	rule, err := h.Service.Create(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// GET /admin/policies?workspace=
+func (h *PolicyHandler) List(ctx *gofr.Context) (interface{}, error) {
+	if !middleware.IsAdmin(ctx, h.AdminToken) {
+		return nil, apierror.Forbidden("admin token required")
+	}
+	rules, err := h.Service.List(ctx, ctx.Param("workspace"))
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// DELETE /admin/policies/{id}
+func (h *PolicyHandler) Delete(ctx *gofr.Context) (interface{}, error) {
+	if !middleware.IsAdmin(ctx, h.AdminToken) {
+		return nil, apierror.Forbidden("admin token required")
+	}
+	if err := h.Service.Delete(ctx, ctx.PathParam("id")); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
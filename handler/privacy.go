@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"gofr.dev/pkg/gofr"
+
+	gofrHttp "gofr.dev/pkg/gofr/http"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+)
+
+type PrivacyHandler struct {
+	Service    service.ErasureService
+	AdminToken string
+}
+
+func NewPrivacyHandler(service service.ErasureService, adminToken string) *PrivacyHandler {
+	return &PrivacyHandler{Service: service, AdminToken: adminToken}
+}
+
+// POST /admin/erasures
+func (h *PrivacyHandler) Erase(ctx *gofr.Context) (interface{}, error) {
+	if !middleware.IsAdmin(ctx, h.AdminToken) {
+		return nil, apierror.Forbidden("admin token required")
+	}
+	var req model.ErasureRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+	if req.UserID == "" {
+		return nil, gofrHttp.ErrorInvalidParam{Params: []string{"user_id"}}
+	}
+	report, err := h.Service.Erase(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
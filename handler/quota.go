@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+)
+
+// meteredPaths are the routes counted as API calls. Redirects and abuse
+// reports serve the visitors of links rather than API clients, and callers
+// can always check their usage and sign in again, so those are not metered.
+var meteredPaths = []string{
+	"/urls", "/tags", "/folders", "/campaigns", "/analytics", "/reports", "/webhooks", "/workspaces", "/invitations",
+}
+
+type QuotaHandler struct {
+	Service    service.QuotaService
+	Meter      *service.Meter
+	AdminToken string
+
+	container atomic.Pointer[container.Container]
+}
+
+func NewQuotaHandler(service service.QuotaService, meter *service.Meter, adminToken string) *QuotaHandler {
+	return &QuotaHandler{Service: service, Meter: meter, AdminToken: adminToken}
+}
+
+// Init keeps the app container for the metering middleware. Register it with app.OnStart.
+func (h *QuotaHandler) Init(ctx *gofr.Context) error {
+	h.container.Store(ctx.Container)
+	return nil
+}
+
+// GET /plans
+func (h *QuotaHandler) Plans(ctx *gofr.Context) (interface{}, error) {
+	return h.Service.Plans(ctx), nil
+}
+
+// GET /usage?workspace=
+func (h *QuotaHandler) Usage(ctx *gofr.Context) (interface{}, error) {
+	usage, err := h.Service.Usage(ctx, ctx.Param("workspace"))
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// PUT /admin/subscriptions/{account}
+func (h *QuotaHandler) SetPlan(ctx *gofr.Context) (interface{}, error) {
+	if !middleware.IsAdmin(ctx, h.AdminToken) {
+		return nil, apierror.Forbidden("admin token required")
+	}
+	var req model.SetPlanRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+	subscription, err := h.Service.SetPlan(ctx, ctx.PathParam("account"), &req)
+	if err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// Middleware meters every API request made by a signed-in user against their
+// monthly API call quota, and answers 429 once it is used up. Anonymous and
+// admin requests are not metered. It must run after CaptureRequest.
+func (h *QuotaHandler) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c := h.container.Load()
+			actor := middleware.Actor(r.Context())
+			if c == nil || actor == "" || !metered(r.URL.Path) || middleware.IsAdmin(r.Context(), h.AdminToken) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx := &gofr.Context{Context: r.Context(), Container: c}
+			err := h.Meter.Take(ctx, actor, model.MetricAPICalls)
+			var exceeded *service.QuotaExceeded
+			if errors.As(err, &exceeded) {
+				retry := int(time.Until(exceeded.ResetsAt).Seconds()) + 1
+				w.Header().Set("Retry-After", strconv.Itoa(retry))
+				middleware.WriteError(w, exceeded.StatusCode(), exceeded)
+				return
+			}
+			if err != nil {
+				// Failing to meter must not take the API down.
+				ctx.Logger.Errorf("metering API call by %s: %v", actor, err)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func metered(path string) bool {
+	for _, prefix := range meteredPaths {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/handler"
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+func TestQuotaMiddleware(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+	plans := []model.Plan{{Name: "free", APICalls: 1}}
+	meter, err := service.NewMeter(store.NewQuotaStore(), store.NewURLStore(), plans, "free")
+	assert.NoError(t, err)
+	mocks.Mongo.EXPECT().FindOne(gomock.Any(), "subscriptions", bson.M{"_id": "alice"}, gomock.Any()).Return(mongo.ErrNoDocuments)
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "usage", gomock.Any(), gomock.Any()).Return(nil)
+	_, err = meter.PlanOf(ctx, "alice")
+	assert.NoError(t, err)
+
+	quotaHandler := handler.NewQuotaHandler(nil, meter, "")
+	assert.NoError(t, quotaHandler.Init(ctx))
+	served := 0
+	next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { served++ })
+	serve := func(path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set(middleware.ActorHeader, "alice")
+		r = r.WithContext(middleware.WithRequestMeta(r.Context(), middleware.RequestMeta{Method: r.Method, Header: r.Header}))
+		w := httptest.NewRecorder()
+		quotaHandler.Middleware()(next).ServeHTTP(w, r)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/urls").Code)
+	// Redirects are not API calls, even for a signed-in visitor.
+	assert.Equal(t, http.StatusOK, serve("/abc123").Code)
+	assert.Equal(t, http.StatusOK, serve("/abc123/docs/page").Code)
+
+	w := serve("/urls/abc123")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), `"message":"the free plan allows 1 API calls a month`)
+	assert.Equal(t, 3, served)
+}
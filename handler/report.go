@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+)
+
+type ReportHandler struct {
+	Service service.ReportService
+}
+
+func NewReportHandler(service service.ReportService) *ReportHandler {
+	return &ReportHandler{Service: service}
+}
+
+// POST /reports
+func (h *ReportHandler) Create(ctx *gofr.Context) (interface{}, error) {
+	var req model.CreateReportRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+	report, err := h.Service.Create(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// GET /reports
+func (h *ReportHandler) List(ctx *gofr.Context) (interface{}, error) {
+	reports, err := h.Service.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// DELETE /reports/{id}
+func (h *ReportHandler) Delete(ctx *gofr.Context) (interface{}, error) {
+	if err := h.Service.Delete(ctx, ctx.PathParam("id")); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
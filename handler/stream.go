@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+// StreamHandler serves GET /urls/{short_code}/analytics/stream as Server-Sent
+// Events. GoFr handlers cannot stream a response, so the stream is served by
+// a middleware in front of the route; the route itself must still be
+// registered for the middleware to run.
+type StreamHandler struct {
+	URLs        *store.URLStore
+	Clicks      *service.ClickStream
+	Workspace   *service.WorkspaceAccess
+	AdminToken  string
+	Heartbeat   time.Duration
+	MaxDuration time.Duration
+
+	container atomic.Pointer[container.Container]
+}
+
+func NewStreamHandler(
+	urls *store.URLStore, stream *service.ClickStream, access *service.WorkspaceAccess, adminToken string,
+) *StreamHandler {
+	return &StreamHandler{
+		URLs:        urls,
+		Clicks:      stream,
+		Workspace:   access,
+		AdminToken:  adminToken,
+		Heartbeat:   15 * time.Second,
+		MaxDuration: time.Hour,
+	}
+}
+
+// Init keeps the app container for the stream middleware. Register it with app.OnStart.
+func (h *StreamHandler) Init(ctx *gofr.Context) error {
+	h.container.Store(ctx.Container)
+	return nil
+}
+
+// GET /urls/{short_code}/analytics/stream
+//
+// Only reached when the stream middleware is not installed.
+func (h *StreamHandler) Stream(*gofr.Context) (interface{}, error) {
+	return nil, &apierror.Error{Status: http.StatusNotImplemented, Message: "click streaming is not enabled"}
+}
+
+// Middleware answers stream requests and passes everything else on.
+func (h *StreamHandler) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			code, ok := streamShortCode(r)
+			c := h.container.Load()
+			if !ok || c == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			h.serve(w, r, &gofr.Context{Context: r.Context(), Container: c}, code)
+		})
+	}
+}
+
+// streamShortCode extracts the short code of GET /urls/{short_code}/analytics/stream.
+func streamShortCode(r *http.Request) (string, bool) {
+	if r.Method != http.MethodGet {
+		return "", false
+	}
+	rest, ok := strings.CutPrefix(r.URL.Path, "/urls/")
+	if !ok {
+		return "", false
+	}
+	code, ok := strings.CutSuffix(rest, "/analytics/stream")
+	if !ok || code == "" || strings.Contains(code, "/") {
+		return "", false
+	}
+	return code, true
+}
+
+func (h *StreamHandler) serve(w http.ResponseWriter, r *http.Request, ctx *gofr.Context, code string) {
+	link, err := h.URLs.FindByShortCode(ctx, code)
+	visible := false
+	if err == nil {
+		visible = middleware.IsAdmin(ctx, h.AdminToken)
+		if !visible {
+			visible, err = h.Workspace.CanView(ctx, link)
+		}
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) || err == nil && !visible {
+		// Links the caller may not view are reported as missing so their codes are not revealed.
+		writeStreamError(w, http.StatusNotFound, "link not found")
+		return
+	}
+	if err != nil {
+		ctx.Logger.Errorf("loading %s for streaming: %v", code, err)
+		writeStreamError(w, http.StatusInternalServerError, "could not open stream")
+		return
+	}
+
+	sub := h.Clicks.Subscribe(code, 64)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+
+	heartbeat := time.NewTicker(h.Heartbeat)
+	defer heartbeat.Stop()
+	deadline := time.NewTimer(h.MaxDuration)
+	defer deadline.Stop()
+	counter := model.ClickCounter{Minute: time.Now().UTC().Truncate(time.Minute)}
+	minute := time.NewTimer(time.Until(counter.Minute.Add(time.Minute)))
+	defer minute.Stop()
+
+	write := func(format string, args ...any) bool {
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			return false
+		}
+		// Writers that cannot flush still deliver events, just not immediately.
+		_ = rc.Flush()
+		return true
+	}
+
+	if !write(": streaming clicks of %s\n\n", code) {
+		return
+	}
+	for {
+		var ok bool
+		select {
+		case <-r.Context().Done():
+			return
+		case <-deadline.C:
+			return
+		case event, open := <-sub.C:
+			if !open {
+				return
+			}
+			if data, isClick := event.Data.(model.ClickData); isClick && data.Bot != "" {
+				counter.BotClicks++
+			} else {
+				counter.Clicks++
+			}
+			ok = writeEvent(write, "click", event.ID, event)
+		case <-heartbeat.C:
+			ok = write(": keep-alive\n\n")
+		case <-minute.C:
+			ok = writeEvent(write, "counter", "", counter)
+			counter = model.ClickCounter{Minute: counter.Minute.Add(time.Minute)}
+			minute.Reset(time.Until(counter.Minute.Add(time.Minute)))
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+func writeEvent(write func(string, ...any) bool, name, id string, payload any) bool {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return false
+	}
+	if id != "" && !write("id: %s\n", id) {
+		return false
+	}
+	return write("event: %s\ndata: %s\n\n", name, data)
+}
+
+func writeStreamError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]string{"message": message}})
+}
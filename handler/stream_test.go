@@ -0,0 +1,157 @@
+package handler_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/handler"
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+func newStreamServer(t *testing.T, link *model.URL) (*httptest.Server, *service.ClickStream) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	mocks.Mongo.EXPECT().FindOne(gomock.Any(), "urls", bson.M{"short_code": "abc123"}, gomock.Any()).AnyTimes().
+		DoAndReturn(func(_ context.Context, _ string, _ any, result any) error {
+			if link == nil {
+				return mongo.ErrNoDocuments
+			}
+			*result.(*model.URL) = *link
+			return nil
+		})
+
+	stream := service.NewClickStream()
+	streamHandler := handler.NewStreamHandler(store.NewURLStore(), stream, nil, "secret")
+	assert.NoError(t, streamHandler.Init(&gofr.Context{Context: context.Background(), Container: mockContainer}))
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	server := httptest.NewServer(middleware.CaptureRequest(nil)(streamHandler.Middleware()(next)))
+	t.Cleanup(server.Close)
+	return server, stream
+}
+
+func TestStreamHandlerAccess(t *testing.T) {
+	tests := []struct {
+		name           string
+		link           *model.URL
+		path           string
+		header         http.Header
+		expectedStatus int
+	}{
+		{
+			name:           "Success - Owner",
+			link:           &model.URL{ShortCode: "abc123", Owner: "alice"},
+			path:           "/urls/abc123/analytics/stream",
+			header:         http.Header{middleware.ActorHeader: {"alice"}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Success - Admin",
+			link:           &model.URL{ShortCode: "abc123", Owner: "alice"},
+			path:           "/urls/abc123/analytics/stream",
+			header:         http.Header{middleware.AdminHeader: {"secret"}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Failure - Not Owner",
+			link:           &model.URL{ShortCode: "abc123", Owner: "alice"},
+			path:           "/urls/abc123/analytics/stream",
+			header:         http.Header{middleware.ActorHeader: {"bob"}},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Failure - Unknown Link",
+			path:           "/urls/abc123/analytics/stream",
+			header:         http.Header{middleware.ActorHeader: {"alice"}},
+			expectedStatus: http.StatusNotFound,
+		},
+		{name: "Other Route", path: "/urls/abc123/analytics", expectedStatus: http.StatusTeapot},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newStreamServer(t, tt.link)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+tt.path, nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+			req.Header = tt.header.Clone()
+			if req.Header == nil {
+				req.Header = http.Header{}
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestStreamHandlerClicks(t *testing.T) {
+	server, stream := newStreamServer(t, &model.URL{ShortCode: "abc123", Owner: "alice"})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/urls/abc123/analytics/stream", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	req.Header.Set(middleware.ActorHeader, "alice")
+
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+
+	// The opening comment is written after subscribing, so the click below is not missed.
+	line, err := reader.ReadString('\n')
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, strings.HasPrefix(line, ": streaming clicks of abc123"))
+	_, _ = reader.ReadString('\n')
+
+	stream.Notify(nil, model.Event{
+		ID:   "e1",
+		Type: model.EventLinkClicked,
+		Data: model.ClickData{ShortCode: "abc123", Destination: "https://example.com"},
+	})
+
+	var lines []string
+	for len(lines) < 3 {
+		line, err := reader.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return
+		}
+		lines = append(lines, strings.TrimSuffix(line, "\n"))
+	}
+	assert.Equal(t, "id: e1", lines[0])
+	assert.Equal(t, "event: click", lines[1])
+	assert.Contains(t, lines[2], `"short_code":"abc123"`)
+	assert.Contains(t, lines[2], `"type":"link.clicked"`)
+}
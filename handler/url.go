@@ -1,9 +1,19 @@
 package handler
 
 import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+
 	"gofr.dev/pkg/gofr"
 	"gofr.dev/pkg/gofr/http/response"
 
+	gofrHttp "gofr.dev/pkg/gofr/http"
+
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
 	"github.com/sksmagr23/url-shortener-gofr/service"
 )
 
@@ -17,13 +27,11 @@ func NewURLHandler(service service.URLService) *URLHandler {
 
 // POST /api/urls
 func (h *URLHandler) Create(ctx *gofr.Context) (interface{}, error) {
-	var req struct {
-		OriginalURL string `json:"original_url"`
-	}
+	var req model.CreateURLRequest
 	if err := ctx.Bind(&req); err != nil {
 		return nil, err
 	}
-	url, err := h.Service.Create(ctx, req.OriginalURL)
+	url, err := h.Service.Create(ctx, &req)
 	if err != nil {
 		return nil, err
 	}
@@ -37,15 +45,216 @@ func (h *URLHandler) Get(ctx *gofr.Context) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	return url, nil
+	if service.NotModified(middleware.GetRequestMeta(ctx).Header, url) &&
+		middleware.NotModified(ctx, validators(url)) {
+		// The middleware turns whatever GoFr writes for the error into a bare
+		// 304 carrying the validators.
+		return nil, service.ErrNotModified
+	}
+	return versioned(url), nil
 }
 
-// GET /{short_code}
+// GET /{short_code} and GET /{short_code}/{path}
 func (h *URLHandler) Redirect(ctx *gofr.Context) (interface{}, error) {
 	code := ctx.PathParam("short_code")
-	url, err := h.Service.GetByShortCode(ctx, code)
+	suffix := ctx.PathParam("path")
+	query := middleware.GetRequestMeta(ctx).Query
+	destination, err := h.Service.Resolve(ctx, code, suffix, query)
+	var warning *service.DestinationWarning
+	if errors.As(err, &warning) {
+		return interstitial(warning)
+	}
+	if errors.Is(err, service.ErrLinkDisabled) {
+		// GoFr writes the page with the status of the error, 410 Gone.
+		return response.File{Content: takedownPage, ContentType: "text/html; charset=utf-8"}, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return response.Redirect{URL: destination}, nil
+}
+
+var interstitialPage = template.Must(template.New("interstitial").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<title>Warning: suspicious link</title>
+</head>
+<body>
+<h1>This link may not be safe</h1>
+<p>The page it leads to was flagged for:</p>
+<ul>{{range .Reasons}}<li>{{.}}</li>{{end}}</ul>
+<p>Only continue if you trust where it goes:</p>
+<p>{{.Destination}}</p>
+<p><a href="{{.Proceed}}" rel="noopener noreferrer nofollow">Continue to the page</a></p>
+</body>
+</html>
+`))
+
+// versioned returns url with the validators clients send back in
+// If-None-Match and If-Match.
+func versioned(url *model.URL) response.Response {
+	return response.Response{Data: url, Headers: validators(url)}
+}
+
+func validators(url *model.URL) map[string]string {
+	return map[string]string{
+		"ETag":          service.ETag(url),
+		"Last-Modified": service.LastModified(url).UTC().Format(http.TimeFormat),
+		"Cache-Control": "private, no-cache",
+	}
+}
+
+// requireIfMatch makes clients name the version of the link they changed, so
+// two editors cannot overwrite each other's changes.
+func requireIfMatch(ctx *gofr.Context) error {
+	if middleware.GetRequestMeta(ctx).Header.Get("If-Match") == "" {
+		return service.ErrPreconditionRequired
+	}
+	return nil
+}
+
+// interstitial renders the warning page shown instead of redirecting to a
+// suspicious destination.
+func interstitial(warning *service.DestinationWarning) (interface{}, error) {
+	var buf bytes.Buffer
+	if err := interstitialPage.Execute(&buf, warning); err != nil {
+		return nil, err
+	}
+	return response.File{Content: buf.Bytes(), ContentType: "text/html; charset=utf-8"}, nil
+}
+
+// takedownPage is shown for links an admin disabled. It deliberately says
+// nothing about the link, its destination or why it was taken down.
+var takedownPage = []byte(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<title>Link unavailable</title>
+</head>
+<body>
+<h1>This link is no longer available</h1>
+<p>It has been disabled by the operators of this service.</p>
+</body>
+</html>
+`)
+
+// GET /urls?tag=&folder=&recursive=&workspace=
+func (h *URLHandler) List(ctx *gofr.Context) (interface{}, error) {
+	filter := model.URLFilter{
+		Tags:      ctx.Params("tag"),
+		Folder:    ctx.Param("folder"),
+		Recursive: ctx.Param("recursive") == "true",
+		Workspace: ctx.Param("workspace"),
+	}
+	urls, err := h.Service.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
+
+// GET /tags
+func (h *URLHandler) ListTags(ctx *gofr.Context) (interface{}, error) {
+	tags, err := h.Service.ListTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// PUT /urls/{short_code}/tags
+func (h *URLHandler) SetTags(ctx *gofr.Context) (interface{}, error) {
+	var req model.SetTagsRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+	if err := requireIfMatch(ctx); err != nil {
+		return nil, err
+	}
+	url, err := h.Service.SetTags(ctx, ctx.PathParam("short_code"), &req)
+	if err != nil {
+		return nil, err
+	}
+	return versioned(url), nil
+}
+
+// PUT /urls/{short_code}/folder
+func (h *URLHandler) Move(ctx *gofr.Context) (interface{}, error) {
+	var req model.MoveRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+	if err := requireIfMatch(ctx); err != nil {
+		return nil, err
+	}
+	url, err := h.Service.Move(ctx, ctx.PathParam("short_code"), &req)
+	if err != nil {
+		return nil, err
+	}
+	return versioned(url), nil
+}
+
+// POST /urls/tags
+func (h *URLHandler) BulkTag(ctx *gofr.Context) (interface{}, error) {
+	var req model.BulkTagRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+	result, err := h.Service.BulkTag(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PATCH /urls/{short_code}
+func (h *URLHandler) Update(ctx *gofr.Context) (interface{}, error) {
+	var req model.UpdateURLRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+	if err := requireIfMatch(ctx); err != nil {
+		return nil, err
+	}
+	url, err := h.Service.Update(ctx, ctx.PathParam("short_code"), &req)
+	if err != nil {
+		return nil, err
+	}
+	return versioned(url), nil
+}
+
+// DELETE /urls/{short_code}
+func (h *URLHandler) Delete(ctx *gofr.Context) (interface{}, error) {
+	if err := h.Service.Delete(ctx, ctx.PathParam("short_code")); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// GET /urls/{short_code}/history
+func (h *URLHandler) History(ctx *gofr.Context) (interface{}, error) {
+	revisions, err := h.Service.History(ctx, ctx.PathParam("short_code"))
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// POST /urls/{short_code}/history/{revision}/revert
+func (h *URLHandler) Revert(ctx *gofr.Context) (interface{}, error) {
+	number, err := strconv.Atoi(ctx.PathParam("revision"))
+	if err != nil || number < 1 {
+		return nil, gofrHttp.ErrorInvalidParam{Params: []string{"revision"}}
+	}
+	if err := requireIfMatch(ctx); err != nil {
+		return nil, err
+	}
+	url, err := h.Service.Revert(ctx, ctx.PathParam("short_code"), number)
 	if err != nil {
 		return nil, err
 	}
-	return response.Redirect{URL: url.Original}, nil
+	return versioned(url), nil
 }
@@ -7,10 +7,12 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
+	gorillamux "github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson"
@@ -22,6 +24,7 @@ import (
 	gofrHttp "gofr.dev/pkg/gofr/http"
 
 	"github.com/sksmagr23/url-shortener-gofr/handler"
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
 	"github.com/sksmagr23/url-shortener-gofr/model"
 	"github.com/sksmagr23/url-shortener-gofr/service"
 	"github.com/sksmagr23/url-shortener-gofr/store"
@@ -31,8 +34,8 @@ type MockURLService struct {
 	mock.Mock
 }
 
-func (m *MockURLService) Create(ctx *gofr.Context, original string) (*model.URL, error) {
-	args := m.Called(ctx, original)
+func (m *MockURLService) Create(ctx *gofr.Context, req *model.CreateURLRequest) (*model.URL, error) {
+	args := m.Called(ctx, req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -47,6 +50,80 @@ func (m *MockURLService) GetByShortCode(ctx *gofr.Context, code string) (*model.
 	return args.Get(0).(*model.URL), args.Error(1)
 }
 
+func (m *MockURLService) Resolve(ctx *gofr.Context, code, suffix string, query url.Values) (string, error) {
+	args := m.Called(ctx, code, suffix, query)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockURLService) List(ctx *gofr.Context, filter model.URLFilter) ([]model.URL, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.URL), args.Error(1)
+}
+
+func (m *MockURLService) ListTags(ctx *gofr.Context) ([]model.TagCount, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.TagCount), args.Error(1)
+}
+
+func (m *MockURLService) SetTags(ctx *gofr.Context, code string, req *model.SetTagsRequest) (*model.URL, error) {
+	args := m.Called(ctx, code, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.URL), args.Error(1)
+}
+
+func (m *MockURLService) Move(ctx *gofr.Context, code string, req *model.MoveRequest) (*model.URL, error) {
+	args := m.Called(ctx, code, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.URL), args.Error(1)
+}
+
+func (m *MockURLService) BulkTag(ctx *gofr.Context, req *model.BulkTagRequest) (*model.BulkTagResult, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.BulkTagResult), args.Error(1)
+}
+
+func (m *MockURLService) Update(ctx *gofr.Context, code string, req *model.UpdateURLRequest) (*model.URL, error) {
+	args := m.Called(ctx, code, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.URL), args.Error(1)
+}
+
+func (m *MockURLService) History(ctx *gofr.Context, code string) ([]model.Revision, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Revision), args.Error(1)
+}
+
+func (m *MockURLService) Revert(ctx *gofr.Context, code string, number int) (*model.URL, error) {
+	args := m.Called(ctx, code, number)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.URL), args.Error(1)
+}
+
+func (m *MockURLService) Delete(ctx *gofr.Context, code string) error {
+	args := m.Called(ctx, code)
+	return args.Error(0)
+}
+
 func TestURLCreateHandler(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -203,8 +280,11 @@ func TestURLGetHandler(t *testing.T) {
 			assert.NoError(t, err)
 			assert.NotNil(t, result)
 
-			url, ok := result.(*model.URL)
-			assert.True(t, ok, "Expected result to be *model.URL")
+			resp, ok := result.(response.Response)
+			assert.True(t, ok, "Expected result to be response.Response")
+			assert.Equal(t, service.ETag(tt.mockURL), resp.Headers["ETag"])
+			url, ok := resp.Data.(*model.URL)
+			assert.True(t, ok, "Expected data to be *model.URL")
 			assert.Equal(t, tt.mockURL.Original, url.Original)
 			assert.Equal(t, tt.mockURL.ShortCode, url.ShortCode)
 			assert.NotEmpty(t, url.ShortURL)
@@ -213,25 +293,64 @@ func TestURLGetHandler(t *testing.T) {
 	}
 }
 
+func TestURLGetHandlerNotModified(t *testing.T) {
+	mockContainer, _ := container.NewMockContainer(t)
+	link := &model.URL{ShortCode: "abc123", Original: "https://example.com", Revision: 2, UpdatedAt: time.Now().UTC()}
+	mockService := &MockURLService{}
+	mockService.On("GetByShortCode", mock.Anything, "abc123").Return(link, nil)
+	urlHandler := &handler.URLHandler{Service: mockService}
+
+	// next writes the error the way GoFr does, as a JSON body with its status.
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = gorillamux.SetURLVars(r, map[string]string{"short_code": "abc123"})
+		_, err := urlHandler.Get(&gofr.Context{Context: r.Context(), Request: gofrHttp.NewRequest(r), Container: mockContainer})
+		var status interface{ StatusCode() int }
+		if !errors.As(err, &status) {
+			t.Fatalf("expected an error with a status, got %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status.StatusCode())
+		_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]string{"message": err.Error()}})
+	})
+	r := httptest.NewRequest(http.MethodGet, "/urls/abc123", nil)
+	r.Header.Set("If-None-Match", service.ETag(link))
+	r = r.WithContext(middleware.WithRequestMeta(r.Context(), middleware.RequestMeta{Method: r.Method, Header: r.Header}))
+	w := httptest.NewRecorder()
+
+	middleware.NotModifiedResponses(next).ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, service.ETag(link), w.Header().Get("ETag"))
+	assert.NotEmpty(t, w.Header().Get("Last-Modified"))
+	assert.Empty(t, w.Header().Get("Content-Type"))
+	assert.Empty(t, w.Body.String())
+}
+
 func TestURLRedirectHandler(t *testing.T) {
 	tests := []struct {
 		name           string
 		shortCode      string
-		mockURL        *model.URL
+		suffix         string
+		query          url.Values
+		mockDest       string
 		mockError      error
 		expectedStatus int
 		expectError    bool
 	}{
 		{
-			name:      "Success - Valid Redirect",
-			shortCode: "abc123",
-			mockURL: &model.URL{
-				ID:        "test-id",
-				Original:  "https://example.com/test",
-				ShortCode: "abc123",
-				ShortURL:  "http://localhost:8000/abc123",
-				CreatedAt: time.Now().UTC(),
-			},
+			name:           "Success - Valid Redirect",
+			shortCode:      "abc123",
+			mockDest:       "https://example.com/test",
+			mockError:      nil,
+			expectedStatus: http.StatusFound,
+			expectError:    false,
+		},
+		{
+			name:           "Success - Path And Query Passthrough",
+			shortCode:      "docs",
+			suffix:         "guide/intro",
+			query:          url.Values{"lang": {"en"}},
+			mockDest:       "https://docs.example.com/guide/intro?lang=en",
 			mockError:      nil,
 			expectedStatus: http.StatusFound,
 			expectError:    false,
@@ -239,7 +358,7 @@ func TestURLRedirectHandler(t *testing.T) {
 		{
 			name:           "Failure - URL Not Found",
 			shortCode:      "nonexistent",
-			mockURL:        nil,
+			mockDest:       "",
 			mockError:      mongo.ErrNoDocuments,
 			expectedStatus: http.StatusNotFound,
 			expectError:    true,
@@ -252,18 +371,33 @@ func TestURLRedirectHandler(t *testing.T) {
 
 			mockService := &MockURLService{}
 
-			mockService.On("GetByShortCode", mock.Anything, mock.Anything).
-				Return(tt.mockURL, tt.mockError)
+			query := tt.query
+			if query == nil {
+				query = url.Values{}
+			}
+			mockService.On("Resolve", mock.Anything, tt.shortCode, tt.suffix, query).
+				Return(tt.mockDest, tt.mockError)
 
 			urlHandler := &handler.URLHandler{
 				Service: mockService,
 			}
 
-			req := httptest.NewRequest(http.MethodGet, "/"+tt.shortCode, nil)
+			target := "/" + tt.shortCode
+			if tt.suffix != "" {
+				target += "/" + tt.suffix
+			}
+			req := httptest.NewRequest(http.MethodGet, target+"?"+query.Encode(), nil)
+			req = gorillamux.SetURLVars(req, map[string]string{
+				"short_code": tt.shortCode,
+				"path":       tt.suffix,
+			})
 			request := gofrHttp.NewRequest(req)
 
 			ctx := &gofr.Context{
-				Context:   context.Background(),
+				Context: middleware.WithRequestMeta(context.Background(), middleware.RequestMeta{
+					Method: http.MethodGet,
+					Query:  query,
+				}),
 				Request:   request,
 				Container: mockContainer,
 			}
@@ -279,7 +413,145 @@ func TestURLRedirectHandler(t *testing.T) {
 			assert.NotNil(t, result)
 			redirect, ok := result.(response.Redirect)
 			assert.True(t, ok, "Expected result to be response.Redirect")
-			assert.Equal(t, tt.mockURL.Original, redirect.URL)
+			assert.Equal(t, tt.mockDest, redirect.URL)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestURLRedirectHandlerInterstitial(t *testing.T) {
+	mockContainer, _ := container.NewMockContainer(t)
+	mockService := &MockURLService{}
+	destination := `https://203.0.113.7/login?next="><script>`
+	mockService.On("Resolve", mock.Anything, "abc123", "", url.Values{}).
+		Return(destination, &service.DestinationWarning{Destination: destination, Reasons: []string{service.ReasonIPHost},
+			Proceed: "/abc123?_proceed=1"})
+
+	req := gorillamux.SetURLVars(httptest.NewRequest(http.MethodGet, "/abc123", nil), map[string]string{"short_code": "abc123"})
+	ctx := &gofr.Context{
+		Context:   middleware.WithRequestMeta(context.Background(), middleware.RequestMeta{Method: http.MethodGet, Query: url.Values{}}),
+		Request:   gofrHttp.NewRequest(req),
+		Container: mockContainer,
+	}
+
+	result, err := (&handler.URLHandler{Service: mockService}).Redirect(ctx)
+
+	assert.NoError(t, err)
+	page, ok := result.(response.File)
+	if !assert.True(t, ok, "Expected result to be response.File") {
+		return
+	}
+	assert.Equal(t, "text/html; charset=utf-8", page.ContentType)
+	assert.Contains(t, string(page.Content), service.ReasonIPHost)
+	assert.Contains(t, string(page.Content), `href="/abc123?_proceed=1"`)
+	assert.Contains(t, string(page.Content), "https://203.0.113.7/login?next=&#34;&gt;&lt;script&gt;")
+	assert.NotContains(t, string(page.Content), "<script>")
+}
+
+func TestURLRedirectHandlerTakedown(t *testing.T) {
+	mockContainer, _ := container.NewMockContainer(t)
+	mockService := &MockURLService{}
+	mockService.On("Resolve", mock.Anything, "abc123", "", url.Values{}).Return("", service.ErrLinkDisabled)
+
+	req := gorillamux.SetURLVars(httptest.NewRequest(http.MethodGet, "/abc123", nil), map[string]string{"short_code": "abc123"})
+	ctx := &gofr.Context{
+		Context:   middleware.WithRequestMeta(context.Background(), middleware.RequestMeta{Method: http.MethodGet, Query: url.Values{}}),
+		Request:   gofrHttp.NewRequest(req),
+		Container: mockContainer,
+	}
+
+	result, err := (&handler.URLHandler{Service: mockService}).Redirect(ctx)
+
+	assert.Equal(t, service.ErrLinkDisabled, err)
+	page, ok := result.(response.File)
+	if !assert.True(t, ok, "Expected result to be response.File") {
+		return
+	}
+	assert.Equal(t, "text/html; charset=utf-8", page.ContentType)
+	assert.Contains(t, string(page.Content), "no longer available")
+}
+
+func TestURLListHandler(t *testing.T) {
+	mockContainer, _ := container.NewMockContainer(t)
+
+	mockService := &MockURLService{}
+	expectedFilter := model.URLFilter{
+		Tags:      []string{"docs", "beta"},
+		Folder:    "marketing",
+		Recursive: true,
+	}
+	mockService.On("List", mock.Anything, expectedFilter).
+		Return([]model.URL{{ShortCode: "abc123", Tags: []string{"docs", "beta"}}}, nil)
+
+	urlHandler := &handler.URLHandler{Service: mockService}
+
+	req := httptest.NewRequest(http.MethodGet, "/urls?tag=docs&tag=beta&folder=marketing&recursive=true", nil)
+	ctx := &gofr.Context{
+		Context:   context.Background(),
+		Request:   gofrHttp.NewRequest(req),
+		Container: mockContainer,
+	}
+
+	result, err := urlHandler.List(ctx)
+	assert.NoError(t, err)
+	urls, ok := result.([]model.URL)
+	assert.True(t, ok, "Expected result to be []model.URL")
+	assert.Len(t, urls, 1)
+	mockService.AssertExpectations(t)
+}
+
+func TestURLRevertHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		revision    string
+		expectCall  bool
+		noIfMatch   bool
+		expectError bool
+	}{
+		{name: "Success - Valid Revision", revision: "2", expectCall: true},
+		{name: "Failure - Non Numeric Revision", revision: "latest", expectError: true},
+		{name: "Failure - Zero Revision", revision: "0", expectError: true},
+		{name: "Failure - Missing If-Match", revision: "2", noIfMatch: true, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContainer, _ := container.NewMockContainer(t)
+
+			mockService := &MockURLService{}
+			if tt.expectCall {
+				mockService.On("Revert", mock.Anything, "abc123", 2).
+					Return(&model.URL{ShortCode: "abc123", Revision: 4}, nil)
+			}
+
+			urlHandler := &handler.URLHandler{Service: mockService}
+
+			req := httptest.NewRequest(http.MethodPost, "/urls/abc123/history/"+tt.revision+"/revert", nil)
+			req = gorillamux.SetURLVars(req, map[string]string{
+				"short_code": "abc123",
+				"revision":   tt.revision,
+			})
+			meta := middleware.GetRequestMeta(context.Background())
+			if !tt.noIfMatch {
+				meta.Header.Set("If-Match", `"3-abc"`)
+			}
+			ctx := &gofr.Context{
+				Context:   middleware.WithRequestMeta(context.Background(), meta),
+				Request:   gofrHttp.NewRequest(req),
+				Container: mockContainer,
+			}
+
+			result, err := urlHandler.Revert(ctx)
+			if tt.expectError {
+				assert.Error(t, err)
+				mockService.AssertNotCalled(t, "Revert", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			assert.NoError(t, err)
+			url, ok := result.(response.Response).Data.(*model.URL)
+			assert.True(t, ok, "Expected data to be *model.URL")
+			assert.Equal(t, 4, url.Revision)
 			mockService.AssertExpectations(t)
 		})
 	}
@@ -309,14 +581,20 @@ func TestURLServiceIntegration(t *testing.T) {
 		"urls",
 		bson.M{"short_code": "test123"},
 		gomock.Any(),
-	).Return(nil)
-
+	).DoAndReturn(func(_ context.Context, _ string, _ any, result any) error {
+		// Only the owner of a link may read it.
+		result.(*model.URL).Owner = "alice"
+		return nil
+	})
+
+	header := http.Header{}
+	header.Set(middleware.ActorHeader, "alice")
 	ctx := &gofr.Context{
-		Context:   context.Background(),
+		Context:   middleware.WithRequestMeta(context.Background(), middleware.RequestMeta{Header: header}),
 		Container: mockContainer,
 	}
 
-	createdURL, err := urlService.Create(ctx, "https://example.com/test")
+	createdURL, err := urlService.Create(ctx, &model.CreateURLRequest{OriginalURL: "https://example.com/test"})
 	assert.NoError(t, err)
 	assert.NotNil(t, createdURL)
 	assert.Equal(t, "https://example.com/test", createdURL.Original)
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+)
+
+type WebhookHandler struct {
+	Service service.WebhookService
+}
+
+func NewWebhookHandler(service service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{Service: service}
+}
+
+// POST /webhooks
+func (h *WebhookHandler) Create(ctx *gofr.Context) (interface{}, error) {
+	var req model.CreateWebhookRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+	webhook, err := h.Service.Create(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// GET /webhooks
+func (h *WebhookHandler) List(ctx *gofr.Context) (interface{}, error) {
+	webhooks, err := h.Service.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// DELETE /webhooks/{id}
+func (h *WebhookHandler) Delete(ctx *gofr.Context) (interface{}, error) {
+	if err := h.Service.Delete(ctx, ctx.PathParam("id")); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// GET /webhooks/{id}/deliveries
+func (h *WebhookHandler) Deliveries(ctx *gofr.Context) (interface{}, error) {
+	deliveries, err := h.Service.Deliveries(ctx, ctx.PathParam("id"))
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// POST /webhooks/{id}/deliveries/{delivery_id}/redeliver
+func (h *WebhookHandler) Redeliver(ctx *gofr.Context) (interface{}, error) {
+	delivery, err := h.Service.Redeliver(ctx, ctx.PathParam("id"), ctx.PathParam("delivery_id"))
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
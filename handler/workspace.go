@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+)
+
+type WorkspaceHandler struct {
+	Service service.WorkspaceService
+}
+
+func NewWorkspaceHandler(service service.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{Service: service}
+}
+
+// POST /workspaces
+func (h *WorkspaceHandler) Create(ctx *gofr.Context) (interface{}, error) {
+	var req model.CreateWorkspaceRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+	workspace, err := h.Service.Create(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// GET /workspaces
+func (h *WorkspaceHandler) List(ctx *gofr.Context) (interface{}, error) {
+	workspaces, err := h.Service.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+// GET /workspaces/{id}
+func (h *WorkspaceHandler) Get(ctx *gofr.Context) (interface{}, error) {
+	workspace, err := h.Service.Get(ctx, ctx.PathParam("id"))
+	if err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// GET /workspaces/{id}/members
+func (h *WorkspaceHandler) Members(ctx *gofr.Context) (interface{}, error) {
+	members, err := h.Service.Members(ctx, ctx.PathParam("id"))
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// PUT /workspaces/{id}/members/{user_id}
+func (h *WorkspaceHandler) SetRole(ctx *gofr.Context) (interface{}, error) {
+	var req model.SetRoleRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+	member, err := h.Service.SetRole(ctx, ctx.PathParam("id"), ctx.PathParam("user_id"), &req)
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// DELETE /workspaces/{id}/members/{user_id}
+func (h *WorkspaceHandler) RemoveMember(ctx *gofr.Context) (interface{}, error) {
+	if err := h.Service.RemoveMember(ctx, ctx.PathParam("id"), ctx.PathParam("user_id")); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// POST /workspaces/{id}/invitations
+func (h *WorkspaceHandler) Invite(ctx *gofr.Context) (interface{}, error) {
+	var req model.InviteRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+	invitation, err := h.Service.Invite(ctx, ctx.PathParam("id"), &req)
+	if err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// GET /workspaces/{id}/invitations
+func (h *WorkspaceHandler) Invitations(ctx *gofr.Context) (interface{}, error) {
+	invitations, err := h.Service.Invitations(ctx, ctx.PathParam("id"))
+	if err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// POST /invitations/accept
+func (h *WorkspaceHandler) Accept(ctx *gofr.Context) (interface{}, error) {
+	var req model.AcceptInvitationRequest
+	if err := ctx.Bind(&req); err != nil {
+		return nil, err
+	}
+	member, err := h.Service.Accept(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
@@ -0,0 +1,82 @@
+// Package hll implements HyperLogLog sketches for estimating the number of
+// distinct items without storing the items themselves.
+package hll
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+// Precision is the number of hash bits used to pick a register. 2^12 registers
+// give a standard error of about 1.6%.
+const (
+	Precision = 12
+	Registers = 1 << Precision
+)
+
+// Sketch is a sparse HyperLogLog: it maps register indexes to their rank and
+// omits empty registers, so sketches of small buckets stay small.
+type Sketch map[int]uint8
+
+func New() Sketch {
+	return make(Sketch)
+}
+
+// Add counts item in the sketch.
+func (s Sketch) Add(item string) {
+	index, rank := position(hash(item))
+	if rank > s[index] {
+		s[index] = rank
+	}
+}
+
+// Merge folds other into s, so that s estimates the union of both.
+func (s Sketch) Merge(other Sketch) {
+	for index, rank := range other {
+		if rank > s[index] {
+			s[index] = rank
+		}
+	}
+}
+
+// Estimate returns the estimated number of distinct items added.
+func (s Sketch) Estimate() uint64 {
+	if len(s) == 0 {
+		return 0
+	}
+	const m = float64(Registers)
+	sum := float64(Registers - len(s)) // empty registers contribute 2^0
+	for _, rank := range s {
+		sum += math.Ldexp(1, -int(rank))
+	}
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+
+	// Small cardinalities are estimated better by linear counting.
+	if empty := Registers - len(s); estimate <= 2.5*m && empty > 0 {
+		estimate = m * math.Log(m/float64(empty))
+	}
+	return uint64(math.Round(estimate))
+}
+
+// position splits a hash into a register index and the rank of the
+// remaining bits (position of the leftmost 1).
+func position(h uint64) (int, uint8) {
+	index := int(h >> (64 - Precision))
+	rest := h<<Precision | 1<<(Precision-1)
+	return index, uint8(bits.LeadingZeros64(rest) + 1)
+}
+
+// hash is FNV-1a followed by a 64-bit finalizer, which spreads the small
+// differences between similar items over all bits.
+func hash(item string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(item))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
@@ -0,0 +1,54 @@
+package hll_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sksmagr23/url-shortener-gofr/hll"
+)
+
+func TestSketchEstimate(t *testing.T) {
+	tests := []struct {
+		name     string
+		distinct int
+	}{
+		{name: "Empty", distinct: 0},
+		{name: "Small", distinct: 100},
+		{name: "Medium", distinct: 10000},
+		{name: "Large", distinct: 500000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sketch := hll.New()
+			for i := range tt.distinct {
+				// Every item is added twice; duplicates must not count.
+				sketch.Add("visitor-" + strconv.Itoa(i))
+				sketch.Add("visitor-" + strconv.Itoa(i))
+			}
+			// Allow three standard errors.
+			assert.InEpsilon(t, float64(tt.distinct)+1, float64(sketch.Estimate())+1, 0.05)
+		})
+	}
+}
+
+func TestSketchMerge(t *testing.T) {
+	first, second, union := hll.New(), hll.New(), hll.New()
+	for i := range 6000 {
+		item := strconv.Itoa(i)
+		if i < 4000 {
+			first.Add(item)
+		}
+		if i >= 2000 {
+			second.Add(item)
+		}
+		union.Add(item)
+	}
+
+	first.Merge(second)
+
+	assert.Equal(t, union, first)
+	assert.InEpsilon(t, 6000, float64(first.Estimate()), 0.05)
+}
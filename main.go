@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -10,6 +13,10 @@ import (
 	"gofr.dev/pkg/gofr/datasource/mongo"
 
 	"github.com/sksmagr23/url-shortener-gofr/handler"
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/oidc"
+	"github.com/sksmagr23/url-shortener-gofr/referrer"
 	"github.com/sksmagr23/url-shortener-gofr/service"
 	"github.com/sksmagr23/url-shortener-gofr/store"
 )
@@ -34,14 +41,276 @@ func main() {
 	app.GET("/health", handler.HealthHandler())
 
 	urlStore := store.NewURLStore()
+	campaignStore := store.NewCampaignStore()
+	folderStore := store.NewFolderStore()
+	revisionStore := store.NewRevisionStore()
+	auditStore := store.NewAuditStore()
+	auditor := service.NewAuditor(auditStore)
+	webhookStore := store.NewWebhookStore()
+	dispatcher := service.NewWebhookDispatcher(webhookStore, 1000)
+	dispatcher.Start(4)
+	clickStore := store.NewClickStore()
+	rollupStore := store.NewRollupStore()
+	clickIngester := service.NewClickIngester(clickStore, urlStore, rollupStore, 10000)
+	clickIngester.RegisterMetrics(app.Metrics())
+	clickIngester.Start(2)
+	clickStream := service.NewClickStream()
+	eventPublisher := service.NewEventPublisher(nil, envOrDefault("LINK_EVENTS_TOPIC", "link-events"), 10000)
+	if os.Getenv("PUBSUB_BACKEND") == "" {
+		// Without a broker, keep events in process so the service still runs offline.
+		eventPublisher.Publisher = service.NewMemoryPublisher(1000)
+	}
+	eventPublisher.Start(2)
+	ipAnonymizer, err := service.NewIPAnonymizer(os.Getenv("IP_ANONYMIZATION"), 24*time.Hour)
+	if err != nil {
+		fmt.Println("Error configuring IP anonymization:", err)
+		os.Exit(1)
+	}
+	screener, err := service.NewScreener(strings.Split(os.Getenv("BLOCKLIST_FILES"), ",")...)
+	if err != nil {
+		fmt.Println("Error loading blocklists:", err)
+		os.Exit(1)
+	}
 	shortURLHost := os.Getenv("SHORT_URL_HOST")
-	urlService := service.NewURLService(urlStore, shortURLHost)
+	plans, err := service.ParsePlans(os.Getenv("PLANS"))
+	if err != nil {
+		fmt.Println("Error loading plans:", err)
+		os.Exit(1)
+	}
+	meter, err := service.NewMeter(store.NewQuotaStore(), urlStore, plans, os.Getenv("DEFAULT_PLAN"))
+	if err != nil {
+		fmt.Println("Error loading plans:", err)
+		os.Exit(1)
+	}
+	meter.Start()
+	policyStore := store.NewPolicyStore()
+	workspaceStore := store.NewWorkspaceStore()
+	workspaceAccess := service.NewWorkspaceAccess(workspaceStore)
+	ssoService, err := newSSOService(workspaceStore, auditor)
+	if err != nil {
+		fmt.Println("Error configuring single sign-on:", err)
+		os.Exit(1)
+	}
+	if ssoService != nil {
+		// Bearer tokens replace X-User-ID, so this runs before CaptureRequest.
+		app.UseMiddleware(middleware.Authenticate(ssoService))
+	}
+	trustedProxies, err := middleware.ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		fmt.Println("Error configuring trusted proxies:", err)
+		os.Exit(1)
+	}
+	app.UseMiddleware(middleware.CaptureRequest(trustedProxies))
+	idempotency := service.NewIdempotency(store.NewIdempotencyStore(), envHours("IDEMPOTENCY_TTL_HOURS", 24))
+	urlService := service.NewURLService(urlStore, shortURLHost,
+		service.WithCampaigns(campaignStore),
+		service.WithFolders(folderStore),
+		service.WithRevisions(revisionStore),
+		service.WithAudit(auditor),
+		service.WithClicks(clickIngester),
+		service.WithPrivacy(ipAnonymizer),
+		service.WithScreening(screener),
+		service.WithChainGuard(service.NewChainGuard(urlStore,
+			append(strings.Split(os.Getenv("OWN_DOMAINS"), ","), shortURLHost),
+			strings.Split(os.Getenv("SHORTENER_DOMAINS"), ","),
+			envInt("MAX_CHAIN_DEPTH", 1),
+			os.Getenv("RESOLVE_CHAINS") == "true",
+		)),
+		service.WithPolicies(service.NewPolicyChecker(policyStore)),
+		service.WithWorkspaces(workspaceAccess),
+		service.WithQuotas(meter),
+		service.WithIdempotency(idempotency),
+		service.WithBotFilter(service.NewBotClassifier(strings.Split(os.Getenv("BOT_UA_PATTERNS"), ",")...)),
+		service.WithSources(referrer.NewClassifier([]string{shortURLHost}, referrer.ParseRules(os.Getenv("REFERRER_CHANNELS"))...)),
+		service.WithListener(dispatcher),
+		service.WithListener(eventPublisher),
+		service.WithListener(clickStream),
+	)
 	urlHandler := handler.NewURLHandler(urlService)
+	analyticsHandler := handler.NewAnalyticsHandler(service.NewAnalyticsService(urlStore, rollupStore, workspaceAccess))
+	streamHandler := handler.NewStreamHandler(urlStore, clickStream, workspaceAccess, os.Getenv("ADMIN_TOKEN"))
+	app.OnStart(streamHandler.Init)
+	quotaHandler := handler.NewQuotaHandler(service.NewQuotaService(meter, workspaceAccess, auditor), meter,
+		os.Getenv("ADMIN_TOKEN"))
+	app.OnStart(quotaHandler.Init)
+	// API calls are metered before streams are served, so streams count too.
+	app.UseMiddleware(quotaHandler.Middleware())
+	app.UseMiddleware(streamHandler.Middleware())
+	app.UseMiddleware(middleware.NotModifiedResponses)
+	campaignHandler := handler.NewCampaignHandler(service.NewCampaignService(campaignStore, urlStore, workspaceAccess, auditor))
+	workspaceHandler := handler.NewWorkspaceHandler(service.NewWorkspaceService(workspaceStore, auditor))
+	folderHandler := handler.NewFolderHandler(service.NewFolderService(folderStore, urlStore, auditor))
+	auditHandler := handler.NewAuditHandler(auditor, os.Getenv("ADMIN_TOKEN"))
+	webhookHandler := handler.NewWebhookHandler(service.NewWebhookService(webhookStore, dispatcher, auditor))
+	reportStore := store.NewReportStore()
+	reportService := service.NewReportService(reportStore, urlStore, rollupStore, workspaceAccess, os.Getenv("REPORT_DIR"), auditor)
+	reportHandler := handler.NewReportHandler(reportService)
+	moderationHandler := handler.NewModerationHandler(service.NewModerationService(urlStore, store.NewAbuseReportStore(),
+		shortURLHost, auditor, dispatcher, eventPublisher, clickStream), os.Getenv("ADMIN_TOKEN"))
+	policyHandler := handler.NewPolicyHandler(service.NewPolicyService(policyStore, auditor), os.Getenv("ADMIN_TOKEN"))
+	privacyHandler := handler.NewPrivacyHandler(service.NewErasureService(urlStore, clickStore, rollupStore, revisionStore,
+		folderStore, webhookStore, reportStore, workspaceStore, store.NewAbuseReportStore(), store.NewQuotaStore(),
+		store.NewIdempotencyStore(), auditStore, auditor), os.Getenv("ADMIN_TOKEN"))
+
+	// Single sign-on endpoints
+	if ssoService != nil {
+		authHandler := handler.NewAuthHandler(ssoService)
+		app.GET("/auth/login", authHandler.Login)
+		app.GET("/auth/callback", authHandler.Callback)
+	}
+
+	// Admin endpoints
+	app.GET("/admin/audit", auditHandler.Query)
+	app.POST("/admin/erasures", privacyHandler.Erase)
+	app.GET("/admin/moderation", moderationHandler.Queue)
+	app.POST("/admin/moderation/{short_code}", moderationHandler.Act)
+	app.POST("/admin/policies", policyHandler.Create)
+	app.GET("/admin/policies", policyHandler.List)
+	app.DELETE("/admin/policies/{id}", policyHandler.Delete)
+	app.PUT("/admin/subscriptions/{account}", quotaHandler.SetPlan)
+
+	// Plans and usage
+	app.GET("/plans", quotaHandler.Plans)
+	app.GET("/usage", quotaHandler.Usage)
+
+	// Campaign endpoints
+	app.POST("/campaigns", campaignHandler.Create)
+	app.GET("/campaigns", campaignHandler.List)
+	app.GET("/campaigns/{id}", campaignHandler.Get)
+	app.GET("/campaigns/{id}/analytics", campaignHandler.Analytics)
+
+	// Webhook endpoints
+	app.POST("/webhooks", webhookHandler.Create)
+	app.GET("/webhooks", webhookHandler.List)
+	app.DELETE("/webhooks/{id}", webhookHandler.Delete)
+	app.GET("/webhooks/{id}/deliveries", webhookHandler.Deliveries)
+	app.POST("/webhooks/{id}/deliveries/{delivery_id}/redeliver", webhookHandler.Redeliver)
+
+	// Analytics export and scheduled reports
+	app.GET("/analytics/export", analyticsHandler.Export)
+	app.POST("/reports", reportHandler.Create)
+	app.GET("/reports", reportHandler.List)
+	app.DELETE("/reports/{id}", reportHandler.Delete)
+
+	// Workspace endpoints
+	app.POST("/workspaces", workspaceHandler.Create)
+	app.GET("/workspaces", workspaceHandler.List)
+	app.GET("/workspaces/{id}", workspaceHandler.Get)
+	app.GET("/workspaces/{id}/members", workspaceHandler.Members)
+	app.PUT("/workspaces/{id}/members/{user_id}", workspaceHandler.SetRole)
+	app.DELETE("/workspaces/{id}/members/{user_id}", workspaceHandler.RemoveMember)
+	app.POST("/workspaces/{id}/invitations", workspaceHandler.Invite)
+	app.GET("/workspaces/{id}/invitations", workspaceHandler.Invitations)
+	app.POST("/invitations/accept", workspaceHandler.Accept)
+
+	// Organization endpoints
+	app.POST("/folders", folderHandler.Create)
+	app.GET("/folders", folderHandler.List)
+	app.DELETE("/folders/{id}", folderHandler.Delete)
+	app.GET("/tags", urlHandler.ListTags)
 
 	// URL endpoints
 	app.POST("/urls", urlHandler.Create)
+	app.GET("/urls", urlHandler.List)
+	app.POST("/urls/tags", urlHandler.BulkTag)
 	app.GET("/urls/{short_code}", urlHandler.Get)
-	app.GET("/{short_code}", urlHandler.Redirect)
+	app.PATCH("/urls/{short_code}", urlHandler.Update)
+	app.DELETE("/urls/{short_code}", urlHandler.Delete)
+	app.GET("/urls/{short_code}/analytics", analyticsHandler.Link)
+	app.GET("/urls/{short_code}/analytics/stream", streamHandler.Stream)
+	app.GET("/urls/{short_code}/history", urlHandler.History)
+	app.POST("/urls/{short_code}/history/{revision}/revert", urlHandler.Revert)
+	app.PUT("/urls/{short_code}/tags", urlHandler.SetTags)
+	app.PUT("/urls/{short_code}/folder", urlHandler.Move)
+	// Short codes are restricted to URL-safe characters so the wildcard route
+	// does not swallow GoFr's /.well-known endpoints.
+	app.POST("/{short_code:[A-Za-z0-9_-]+}/report", moderationHandler.Report)
+	app.GET("/{short_code:[A-Za-z0-9_-]+}", urlHandler.Redirect)
+	app.GET("/{short_code:[A-Za-z0-9_-]+}/{path:.+}", urlHandler.Redirect)
+
+	retention := &service.ClickRetention{
+		Clicks:    clickStore,
+		Rollups:   rollupStore,
+		IPs:       ipAnonymizer,
+		RawClicks: envDays("RAW_CLICK_RETENTION_DAYS", 90),
+		Rollup: map[string]time.Duration{
+			model.GranularityMinute: envDays("MINUTE_ROLLUP_RETENTION_DAYS", 2),
+			model.GranularityHour:   envDays("HOUR_ROLLUP_RETENTION_DAYS", 90),
+		},
+	}
+	app.AddCronJob("* * * * *", "webhook-retries", dispatcher.RetryDue)
+	app.AddCronJob("30 3 * * *", "click-retention", retention.Expire)
+	app.AddCronJob("15 * * * *", "idempotency-expiry", idempotency.Expire)
+	app.AddCronJob("0 4 * * *", "link-screening", service.NewLinkScanner(urlStore, screener, auditor).Rescan)
+	app.AddCronJob("0 6 * * *", "daily-reports", func(ctx *gofr.Context) {
+		reportService.Run(ctx, model.ReportDaily)
+	})
+	app.AddCronJob("0 6 * * 1", "weekly-reports", func(ctx *gofr.Context) {
+		reportService.Run(ctx, model.ReportWeekly)
+	})
 
 	app.Run()
+
+	// Run returns once the server has shut down; write out the buffered
+	// clicks, usage and events and finish the webhook deliveries in progress.
+	clickIngester.Stop()
+	meter.Stop()
+	dispatcher.Stop()
+	eventPublisher.Stop()
+}
+
+// newSSOService signs users in with the OpenID Connect provider at
+// OIDC_ISSUER. It returns nil when no provider is configured.
+func newSSOService(workspaces *store.WorkspaceStore, auditor *service.Auditor) (service.SSOService, error) {
+	issuer := os.Getenv("OIDC_ISSUER")
+	if issuer == "" {
+		return nil, nil
+	}
+	grants, err := service.ParseRoleGrants(os.Getenv("OIDC_ROLE_MAP"))
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	provider, err := oidc.Discover(ctx, oidc.Config{
+		Issuer:       issuer,
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:       strings.Split(envOrDefault("OIDC_SCOPES", "openid,profile,email"), ","),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return service.NewSSOService(provider, store.NewSSOLoginStore(), workspaces, service.SSOConfig{
+		UserClaim:  os.Getenv("OIDC_USER_CLAIM"),
+		GroupClaim: os.Getenv("OIDC_GROUP_CLAIM"),
+		Grants:     grants,
+	}, auditor), nil
+}
+
+func envOrDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// envInt reads a number from the environment.
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// envHours reads a number of hours from the environment.
+func envHours(key string, fallback int) time.Duration {
+	return time.Duration(envInt(key, fallback)) * time.Hour
+}
+
+// envDays reads a number of days from the environment; 0 disables expiry.
+func envDays(key string, fallback int) time.Duration {
+	return time.Duration(envInt(key, fallback)) * 24 * time.Hour
 }
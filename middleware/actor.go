@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ActorHeader names the caller of a request. Without single sign-on the
+// gateway in front of the service is expected to set it; with it,
+// Authenticate sets it from the request's bearer token.
+const ActorHeader = "X-User-ID"
+
+// Actor returns the caller of the request, or "" for anonymous requests.
+func Actor(ctx context.Context) string {
+	return GetRequestMeta(ctx).Header.Get(ActorHeader)
+}
+
+// ClientIP returns the address of the client as resolved by CaptureRequest,
+// falling back to the peer address of the connection.
+func ClientIP(ctx context.Context) string {
+	meta := GetRequestMeta(ctx)
+	if meta.ClientIP != "" {
+		return meta.ClientIP
+	}
+	return hostOf(meta.RemoteAddr)
+}
+
+// TrustedProxies are the proxies in front of the service. Only their
+// X-Forwarded-For and X-Real-IP headers are believed; anyone else could
+// send those headers to pose as another client.
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies parses a comma separated list of addresses and CIDR ranges.
+func ParseTrustedProxies(raw string) (TrustedProxies, error) {
+	var proxies TrustedProxies
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid proxy address %q", entry)
+			}
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy range %q", entry)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
+// ClientIP returns the address of the client of a request from the peer
+// remoteAddr. Forwarded headers are followed from the nearest hop back for
+// as long as the hops are trusted proxies.
+func (p TrustedProxies) ClientIP(header http.Header, remoteAddr string) string {
+	client := hostOf(remoteAddr)
+	if !p.trusts(client) {
+		return client
+	}
+	if forwarded := header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if hop == "" {
+				continue
+			}
+			client = hop
+			if !p.trusts(hop) {
+				break
+			}
+		}
+		return client
+	}
+	if realIP := strings.TrimSpace(header.Get("X-Real-IP")); realIP != "" {
+		return realIP
+	}
+	return client
+}
+
+func (p TrustedProxies) trusts(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range p {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func hostOf(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// AdminHeader carries the admin token that unlocks the /admin endpoints.
+const AdminHeader = "X-Admin-Token"
+
+// IsAdmin reports whether the request presented the configured admin token.
+// An empty token disables admin access entirely.
+func IsAdmin(ctx context.Context, token string) bool {
+	presented := GetRequestMeta(ctx).Header.Get(AdminHeader)
+	return token != "" && subtle.ConstantTimeCompare([]byte(presented), []byte(token)) == 1
+}
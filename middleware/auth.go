@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Authenticator resolves a bearer token to the user it was issued to.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (string, error)
+}
+
+// Authenticate makes bearer tokens the only way to name the caller: it sets
+// ActorHeader to the user of a valid "Authorization: Bearer" token and drops
+// any value the client sent itself. Requests without a token are anonymous;
+// requests with an invalid one are rejected with 401. It must run before
+// CaptureRequest.
+func Authenticate(auth Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Header.Del(ActorHeader)
+			scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+			if found && strings.EqualFold(scheme, "Bearer") {
+				user, err := auth.Authenticate(r.Context(), strings.TrimSpace(token))
+				if err != nil {
+					unauthorized(w, err)
+					return
+				}
+				r.Header.Set(ActorHeader, user)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func unauthorized(w http.ResponseWriter, err error) {
+	w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	WriteError(w, http.StatusUnauthorized, err)
+}
+
+// WriteError answers with status and err in the envelope GoFr uses for
+// handler errors, for middlewares that reject a request.
+func WriteError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]string{"message": err.Error()}})
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+)
+
+type notModifiedKey struct{}
+
+// notModified holds the validators of a request a handler answered with
+// 304 Not Modified.
+type notModified struct {
+	header map[string]string
+}
+
+// NotModifiedResponses lets handlers answer a conditional GET with a bare
+// 304. GoFr writes an error response as JSON and a response carrying data
+// alongside an error as 206 Partial Content, so neither can express a 304
+// that has validator headers and no body.
+func NotModifiedResponses(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := &notModified{}
+		ctx := context.WithValue(r.Context(), notModifiedKey{}, state)
+		next.ServeHTTP(&notModifiedWriter{ResponseWriter: w, state: state}, r.WithContext(ctx))
+	})
+}
+
+// NotModified makes the response to the request of ctx a 304 carrying
+// header and no body. It reports false when the request did not pass
+// through NotModifiedResponses, so the handler should answer normally.
+func NotModified(ctx context.Context, header map[string]string) bool {
+	state, ok := ctx.Value(notModifiedKey{}).(*notModified)
+	if !ok {
+		return false
+	}
+	state.header = header
+	return true
+}
+
+// notModifiedWriter replaces whatever GoFr writes for a request marked by
+// NotModified with the 304.
+type notModifiedWriter struct {
+	http.ResponseWriter
+	state *notModified
+}
+
+func (w *notModifiedWriter) WriteHeader(status int) {
+	if w.state.header == nil {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	header := w.ResponseWriter.Header()
+	header.Del("Content-Type")
+	header.Del("Content-Length")
+	for key, value := range w.state.header {
+		header.Set(key, value)
+	}
+	w.ResponseWriter.WriteHeader(http.StatusNotModified)
+}
+
+func (w *notModifiedWriter) Write(body []byte) (int, error) {
+	if w.state.header == nil {
+		return w.ResponseWriter.Write(body)
+	}
+	// A 304 has no body; pretend it was written so GoFr does not log an error.
+	return len(body), nil
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *notModifiedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
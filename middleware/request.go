@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+type requestMetaKey struct{}
+
+// RequestMeta carries the parts of the incoming HTTP request that GoFr's
+// Request interface does not expose to handlers.
+type RequestMeta struct {
+	Method     string
+	Query      url.Values
+	Header     http.Header
+	RemoteAddr string
+	ClientIP   string
+}
+
+// CaptureRequest stores the RequestMeta of every request in its context,
+// resolving the client address through proxies.
+func CaptureRequest(proxies TrustedProxies) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			meta := RequestMeta{
+				Method:     r.Method,
+				Query:      r.URL.Query(),
+				Header:     r.Header.Clone(),
+				RemoteAddr: r.RemoteAddr,
+				ClientIP:   proxies.ClientIP(r.Header, r.RemoteAddr),
+			}
+			next.ServeHTTP(w, r.WithContext(WithRequestMeta(r.Context(), meta)))
+		})
+	}
+}
+
+func WithRequestMeta(ctx context.Context, meta RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+// GetRequestMeta returns the RequestMeta stored in ctx, or an empty one when
+// the request did not pass through CaptureRequest.
+func GetRequestMeta(ctx context.Context) RequestMeta {
+	meta, ok := ctx.Value(requestMetaKey{}).(RequestMeta)
+	if !ok {
+		return RequestMeta{Query: url.Values{}, Header: http.Header{}}
+	}
+	return meta
+}
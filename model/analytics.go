@@ -0,0 +1,93 @@
+package model
+
+import "time"
+
+// Rollup granularities. Every click is counted in one bucket of each.
+const (
+	GranularityMinute = "minute"
+	GranularityHour   = "hour"
+	GranularityDay    = "day"
+)
+
+// Click dimensions kept in rollups.
+const (
+	DimensionReferrer       = "referrer" // normalized referrer domain
+	DimensionSource         = "source"   // utm_source, or the site the referrer belongs to
+	DimensionChannel        = "channel"  // search, social, email, referral, internal or direct
+	DimensionBrowser        = "browser"
+	DimensionBrowserVersion = "browser_version" // family and major version, e.g. "Chrome 124"
+	DimensionOS             = "os"
+	DimensionDevice         = "device" // also counts bot clicks, as "bot"
+	DimensionBot            = "bot"    // bot name, counted for bot clicks only
+)
+
+// Dimensions lists every dimension analytics can be grouped by.
+var Dimensions = []string{
+	DimensionReferrer,
+	DimensionSource,
+	DimensionChannel,
+	DimensionBrowser,
+	DimensionBrowserVersion,
+	DimensionOS,
+	DimensionDevice,
+	DimensionBot,
+}
+
+// ClickRollup aggregates the clicks of one link in one time bucket.
+type ClickRollup struct {
+	ID          string                      `bson:"_id"                   json:"-"`
+	ShortCode   string                      `bson:"short_code"            json:"short_code"`
+	Owner       string                      `bson:"owner,omitempty"       json:"-"`
+	CampaignID  string                      `bson:"campaign_id,omitempty" json:"campaign_id,omitempty"`
+	Granularity string                      `bson:"granularity"           json:"granularity"`
+	Bucket      time.Time                   `bson:"bucket"                json:"bucket"`
+	Clicks      int64                       `bson:"clicks"                json:"clicks"`
+	BotClicks   int64                       `bson:"bot_clicks"            json:"bot_clicks"`
+	Visitors    map[string]int              `bson:"hll"                   json:"-"` // sparse HyperLogLog registers
+	Dimensions  map[string]map[string]int64 `bson:"dimensions"            json:"dimensions"`
+}
+
+// AnalyticsQuery selects the rollups behind GET /urls/{short_code}/analytics.
+type AnalyticsQuery struct {
+	From        time.Time
+	To          time.Time
+	Granularity string
+	GroupBy     string
+}
+
+type AnalyticsPoint struct {
+	Bucket    time.Time `json:"bucket"`
+	Clicks    int64     `json:"clicks"`
+	BotClicks int64     `json:"bot_clicks"`
+	Uniques   int64     `json:"uniques"`
+}
+
+type DimensionCount struct {
+	Value      string  `json:"value"`
+	Clicks     int64   `json:"clicks"`
+	Percentage float64 `json:"percentage"` // share of the dimension's clicks
+}
+
+// ClickCounter is pushed to live viewers at the end of every minute.
+type ClickCounter struct {
+	Minute    time.Time `json:"minute"`
+	Clicks    int64     `json:"clicks"`
+	BotClicks int64     `json:"bot_clicks"`
+}
+
+// LinkAnalytics is the response of GET /urls/{short_code}/analytics. Clicks,
+// uniques and dimensions other than "bot" only count people.
+type LinkAnalytics struct {
+	ShortCode   string                      `json:"short_code"`
+	Granularity string                      `json:"granularity"`
+	From        time.Time                   `json:"from"`
+	To          time.Time                   `json:"to"`
+	Clicks      int64                       `json:"clicks"`
+	BotClicks   int64                       `json:"bot_clicks"`
+	Uniques     int64                       `json:"uniques"`
+	Series      []AnalyticsPoint            `json:"series"`
+	Top         map[string][]DimensionCount `json:"top"`
+	Channels    []DimensionCount            `json:"channels"` // every channel
+	GroupBy     string                      `json:"group_by,omitempty"`
+	Groups      []DimensionCount            `json:"groups,omitempty"` // every value of GroupBy
+}
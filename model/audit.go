@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+// Audit actions name the mutating operations recorded in the audit log.
+const (
+	AuditLinkCreate      = "link.create"
+	AuditLinkUpdate      = "link.update"
+	AuditLinkDelete      = "link.delete"
+	AuditLinkRevert      = "link.revert"
+	AuditLinkTags        = "link.tags"
+	AuditLinkMove        = "link.move"
+	AuditLinkBulkTag     = "link.bulk_tag"
+	AuditLinkScreen      = "link.screen"
+	AuditLinkModerate    = "link.moderate"
+	AuditCampaignCreate  = "campaign.create"
+	AuditFolderCreate    = "folder.create"
+	AuditFolderDelete    = "folder.delete"
+	AuditWebhookCreate   = "webhook.create"
+	AuditWebhookDelete   = "webhook.delete"
+	AuditReportCreate    = "report.create"
+	AuditReportDelete    = "report.delete"
+	AuditPolicyCreate    = "policy.create"
+	AuditPolicyDelete    = "policy.delete"
+	AuditPlanAssign      = "plan.assign"
+	AuditUserErase       = "user.erase"
+	AuditUserLogin       = "user.login"
+	AuditWorkspaceCreate = "workspace.create"
+	AuditWorkspaceInvite = "workspace.invite"
+	AuditWorkspaceJoin   = "workspace.join"
+	AuditWorkspaceRole   = "workspace.role"
+	AuditWorkspaceRemove = "workspace.remove"
+)
+
+// AuditChange is one field that differs between the before and after state,
+// with nested fields flattened to dotted paths.
+type AuditChange struct {
+	Field  string `bson:"field"            json:"field"`
+	Before any    `bson:"before,omitempty" json:"before,omitempty"`
+	After  any    `bson:"after,omitempty"  json:"after,omitempty"`
+}
+
+type AuditEntry struct {
+	ID        string        `bson:"_id"                  json:"id"`
+	Action    string        `bson:"action"               json:"action"`
+	Resource  string        `bson:"resource"             json:"resource"`
+	Actor     string        `bson:"actor"                json:"actor"`
+	IP        string        `bson:"ip"                   json:"ip"`
+	RequestID string        `bson:"request_id,omitempty" json:"request_id,omitempty"`
+	Changes   []AuditChange `bson:"changes"              json:"changes"`
+	Timestamp time.Time     `bson:"timestamp"            json:"timestamp"`
+}
+
+// AuditFilter narrows GET /admin/audit. Zero values match everything.
+type AuditFilter struct {
+	Actor    string
+	Action   string
+	Resource string
+	From     time.Time
+	To       time.Time
+}
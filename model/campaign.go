@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// Campaign apply modes decide when a campaign's UTM parameters are merged
+// into a link's destination.
+const (
+	CampaignApplyAtCreate   = "create"
+	CampaignApplyAtRedirect = "redirect"
+)
+
+type UTMParams struct {
+	Source   string `bson:"utm_source,omitempty"   json:"utm_source,omitempty"`
+	Medium   string `bson:"utm_medium,omitempty"   json:"utm_medium,omitempty"`
+	Campaign string `bson:"utm_campaign,omitempty" json:"utm_campaign,omitempty"`
+	Term     string `bson:"utm_term,omitempty"     json:"utm_term,omitempty"`
+	Content  string `bson:"utm_content,omitempty"  json:"utm_content,omitempty"`
+}
+
+type Campaign struct {
+	ID        string    `bson:"_id"                 json:"id"`
+	Name      string    `bson:"name"                json:"name"`
+	UTM       UTMParams `bson:"utm"                 json:"utm"`
+	ApplyAt   string    `bson:"apply_at"            json:"apply_at"`
+	Owner     string    `bson:"owner,omitempty"     json:"owner,omitempty"`
+	Workspace string    `bson:"workspace,omitempty" json:"workspace,omitempty"`
+	CreatedAt time.Time `bson:"created_at"          json:"created_at"`
+}
+
+// CreateCampaignRequest is the body accepted by POST /campaigns. Campaigns
+// made in a workspace are shared with its members.
+type CreateCampaignRequest struct {
+	Name      string    `json:"name"`
+	UTM       UTMParams `json:"utm"`
+	ApplyAt   string    `json:"apply_at"`
+	Workspace string    `json:"workspace"`
+}
+
+type CampaignLinkStats struct {
+	ShortCode string `json:"short_code"`
+	Clicks    int64  `json:"clicks"`
+}
+
+type CampaignAnalytics struct {
+	CampaignID  string              `json:"campaign_id"`
+	TotalLinks  int                 `json:"total_links"`
+	TotalClicks int64               `json:"total_clicks"`
+	Links       []CampaignLinkStats `json:"links"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Click is one redirect through a short link.
+type Click struct {
+	ID             string    `bson:"_id,omitempty"             json:"id"`
+	ShortCode      string    `bson:"short_code"                json:"short_code"`
+	Owner          string    `bson:"owner,omitempty"           json:"-"`
+	CampaignID     string    `bson:"campaign_id,omitempty"     json:"campaign_id,omitempty"`
+	Destination    string    `bson:"destination"               json:"destination"`
+	Referrer       string    `bson:"referrer,omitempty"        json:"referrer,omitempty"`
+	Source         string    `bson:"source,omitempty"          json:"source,omitempty"`
+	Channel        string    `bson:"channel,omitempty"         json:"channel,omitempty"`
+	UserAgent      string    `bson:"user_agent,omitempty"      json:"user_agent,omitempty"`
+	Browser        string    `bson:"browser,omitempty"         json:"browser,omitempty"`
+	BrowserVersion string    `bson:"browser_version,omitempty" json:"browser_version,omitempty"`
+	OS             string    `bson:"os,omitempty"              json:"os,omitempty"`
+	Device         string    `bson:"device,omitempty"          json:"device,omitempty"`
+	IP             string    `bson:"ip,omitempty"              json:"-"`                   // anonymized
+	IPMode         string    `bson:"ip_mode,omitempty"         json:"-"`                   // how IP was anonymized
+	Visitor        string    `bson:"-"                         json:"-"`                   // raw visitor identity for unique counts, never stored
+	Anonymous      bool      `bson:"anonymous,omitempty"       json:"anonymous,omitempty"` // counted without visitor data
+	Bot            string    `bson:"bot,omitempty"             json:"bot,omitempty"`       // bot name; empty for people
+	Timestamp      time.Time `bson:"timestamp"                 json:"timestamp"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// Link event types delivered to webhooks and other listeners.
+const (
+	EventLinkCreated = "link.created"
+	EventLinkUpdated = "link.updated"
+	EventLinkDeleted = "link.deleted"
+	EventLinkClicked = "link.clicked"
+)
+
+// EventSchemaVersion is bumped whenever the published event payload changes
+// in a way consumers must know about.
+const EventSchemaVersion = 1
+
+// Event describes something that happened to a link. Owner routes the event
+// to the owner's subscriptions and is not part of the payload.
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	Owner      string    `json:"-"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// EventEnvelope is the message published to the pub/sub topic.
+type EventEnvelope struct {
+	SchemaVersion int `json:"schema_version"`
+	Event
+}
+
+// ClickData is the payload of link.clicked events.
+type ClickData struct {
+	ShortCode   string `json:"short_code"`
+	Destination string `json:"destination"`
+	Bot         string `json:"bot,omitempty"`
+}
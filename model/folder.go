@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// Folder is a node in an owner's link hierarchy. Path holds the full
+// slash-separated location, e.g. "marketing/2024/spring".
+type Folder struct {
+	ID        string    `bson:"_id"        json:"id"`
+	Owner     string    `bson:"owner"      json:"owner"`
+	Path      string    `bson:"path"       json:"path"`
+	Parent    string    `bson:"parent"     json:"parent"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// CreateFolderRequest is the body accepted by POST /folders.
+type CreateFolderRequest struct {
+	Path string `json:"path"`
+}
+
+// URLFilter narrows the link listing of an owner, or of a workspace when
+// Workspace is set.
+type URLFilter struct {
+	Owner     string
+	Workspace string
+	Tags      []string
+	Folder    string
+	Recursive bool
+}
+
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// SetTagsRequest is the body accepted by PUT /urls/{short_code}/tags.
+type SetTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// MoveRequest is the body accepted by PUT /urls/{short_code}/folder.
+type MoveRequest struct {
+	Folder string `json:"folder"`
+}
+
+// BulkTagRequest is the body accepted by POST /urls/tags.
+type BulkTagRequest struct {
+	ShortCodes []string `json:"short_codes"`
+	Add        []string `json:"add"`
+	Remove     []string `json:"remove"`
+}
+
+type BulkTagResult struct {
+	Updated int64 `json:"updated"`
+}
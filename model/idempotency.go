@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// IdempotencyHeader carries the key a client sends to make retries of
+// POST /urls safe.
+const IdempotencyHeader = "Idempotency-Key"
+
+// IdempotencyRecord remembers the first request made with an idempotency
+// key. It is stored under a hash of the client and key; Link is empty while
+// the first request is still being served, which it may be until LeaseUntil.
+type IdempotencyRecord struct {
+	ID          string    `bson:"_id"`
+	Fingerprint string    `bson:"fingerprint"` // hash of the request body
+	Link        *URL      `bson:"link,omitempty"`
+	CreatedAt   time.Time `bson:"created_at"`
+	LeaseUntil  time.Time `bson:"lease_until"`
+	ExpiresAt   time.Time `bson:"expires_at"`
+}
@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// Abuse report categories accepted by POST /{short_code}/report.
+const (
+	AbusePhishing = "phishing"
+	AbuseMalware  = "malware"
+	AbuseSpam     = "spam"
+	AbuseFraud    = "fraud"
+	AbuseIllegal  = "illegal"
+	AbuseOther    = "other"
+)
+
+// Moderation statuses of a link.
+const (
+	ModerationDisabled = "disabled" // redirects show a takedown page
+	ModerationCleared  = "cleared"  // reviewed and left up despite reports or flags
+)
+
+// Moderation actions taken from the moderation queue.
+const (
+	ModerationActionDisable = "disable"
+	ModerationActionDelete  = "delete"
+	ModerationActionClear   = "clear"
+)
+
+// Moderation is the outcome of the latest review of a link by an admin.
+type Moderation struct {
+	Status    string    `bson:"status"         json:"status"`
+	Note      string    `bson:"note,omitempty" json:"note,omitempty"`
+	UpdatedAt time.Time `bson:"updated_at"     json:"updated_at"`
+}
+
+// AbuseReport is one report of an abusive link. Open reports keep the link in
+// the moderation queue until an admin acts on it.
+type AbuseReport struct {
+	ID         string    `bson:"_id"                   json:"id"`
+	ShortCode  string    `bson:"short_code"            json:"short_code"`
+	Reason     string    `bson:"reason"                json:"reason"`
+	Details    string    `bson:"details,omitempty"     json:"details,omitempty"`
+	Reporter   string    `bson:"reporter,omitempty"    json:"reporter,omitempty"` // network of the client, /24 or /48
+	Resolution string    `bson:"resolution,omitempty"  json:"resolution,omitempty"`
+	CreatedAt  time.Time `bson:"created_at"            json:"created_at"`
+	ResolvedAt time.Time `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
+}
+
+// ReportAbuseRequest is the body accepted by POST /{short_code}/report.
+type ReportAbuseRequest struct {
+	Reason  string `json:"reason"`
+	Details string `json:"details"`
+}
+
+// ModerationItem is a link waiting for review with the evidence against it:
+// its open reports and its screening verdict.
+type ModerationItem struct {
+	Link    URL           `json:"link"`
+	Reports []AbuseReport `json:"reports"`
+}
+
+// ModerationRequest is the body accepted by POST /admin/moderation/{short_code}.
+type ModerationRequest struct {
+	Action string `json:"action"`
+	Note   string `json:"note"`
+}
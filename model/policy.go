@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// Policy rule effects.
+const (
+	PolicyAllow = "allow" // once a workspace has allow rules, destinations must match one
+	PolicyDeny  = "deny"  // destinations matching the rule are rejected
+)
+
+// How a policy rule's pattern is matched against a destination.
+const (
+	PolicyMatchDomain   = "domain"   // the host or one of its parent domains
+	PolicyMatchWildcard = "wildcard" // the host, with * matching any characters
+	PolicyMatchRegex    = "regex"    // the full destination URL
+)
+
+// PolicyRule restricts the destinations links of a workspace may point to.
+// Rules with an empty Workspace apply to every link.
+type PolicyRule struct {
+	ID          string    `bson:"_id"                   json:"id"`
+	Workspace   string    `bson:"workspace"             json:"workspace"`
+	Effect      string    `bson:"effect"                json:"effect"`
+	Match       string    `bson:"match"                 json:"match"`
+	Pattern     string    `bson:"pattern"               json:"pattern"`
+	Description string    `bson:"description,omitempty" json:"description,omitempty"`
+	CreatedAt   time.Time `bson:"created_at"            json:"created_at"`
+}
+
+// CreatePolicyRuleRequest is the body accepted by POST /admin/policies.
+type CreatePolicyRuleRequest struct {
+	Workspace   string `json:"workspace"`
+	Effect      string `json:"effect"`
+	Match       string `json:"match"`
+	Pattern     string `json:"pattern"`
+	Description string `json:"description"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// ErasedUser replaces an erased user in the records kept for others, such as
+// the revisions they made to workspace links.
+const ErasedUser = "erased-user"
+
+// ErasureRequest is the body accepted by POST /admin/erasures.
+type ErasureRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// ErasureReport counts what an erasure removed.
+type ErasureReport struct {
+	UserID            string    `json:"user_id"`
+	Links             int64     `json:"links"`
+	LinksHandedOver   int64     `json:"links_handed_over"` // workspace links given to another owner
+	Clicks            int64     `json:"clicks"`
+	Rollups           int64     `json:"rollups"`
+	Revisions         int64     `json:"revisions"`
+	Folders           int64     `json:"folders"`
+	Webhooks          int64     `json:"webhooks"`
+	WebhookDeliveries int64     `json:"webhook_deliveries"`
+	Reports           int64     `json:"reports"`
+	Memberships       int64     `json:"memberships"`
+	Invitations       int64     `json:"invitations"`
+	Pseudonymized     int64     `json:"pseudonymized"` // revisions, workspaces and invitations credited to ErasedUser
+	AbuseReports      int64     `json:"abuse_reports"`
+	Subscriptions     int64     `json:"subscriptions"`
+	Usage             int64     `json:"usage"`
+	IdempotencyKeys   int64     `json:"idempotency_keys"`
+	AuditEntries      int64     `json:"audit_entries"`
+	ErasedAt          time.Time `json:"erased_at"`
+}
@@ -0,0 +1,70 @@
+package model
+
+import "time"
+
+// Quota metrics. Links are counted as they exist; the others are metered
+// per calendar month (UTC).
+const (
+	MetricLinks         = "links"
+	MetricTrackedClicks = "tracked_clicks"
+	MetricAPICalls      = "api_calls"
+)
+
+// Plan sets the limits of an account, which is a workspace or, for links
+// outside any workspace, a user. A limit of 0 means unlimited.
+type Plan struct {
+	Name          string `json:"name"`
+	Links         int64  `json:"links"`
+	TrackedClicks int64  `json:"tracked_clicks"` // per month
+	APICalls      int64  `json:"api_calls"`      // per month, per user
+}
+
+// Limit returns the plan's limit for metric, 0 for unlimited.
+func (p Plan) Limit(metric string) int64 {
+	switch metric {
+	case MetricLinks:
+		return p.Links
+	case MetricTrackedClicks:
+		return p.TrackedClicks
+	case MetricAPICalls:
+		return p.APICalls
+	}
+	return 0
+}
+
+// Subscription puts an account on a plan other than the default one.
+type Subscription struct {
+	Account   string    `bson:"_id"        json:"account"`
+	Plan      string    `bson:"plan"       json:"plan"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// UsageCounter is the stored usage of one metered metric in one month.
+type UsageCounter struct {
+	ID      string `bson:"_id"`
+	Account string `bson:"account"`
+	Metric  string `bson:"metric"`
+	Period  string `bson:"period"` // "2006-01"
+	Count   int64  `bson:"count"`
+}
+
+// Usage is returned by GET /usage.
+type Usage struct {
+	Account  string        `json:"account"`
+	Plan     string        `json:"plan"`
+	Period   string        `json:"period"`
+	ResetsAt time.Time     `json:"resets_at"`
+	Metrics  []UsageMetric `json:"metrics"`
+}
+
+type UsageMetric struct {
+	Metric  string `json:"metric"`
+	Used    int64  `json:"used"`
+	Limit   int64  `json:"limit"` // 0 means unlimited
+	Monthly bool   `json:"monthly"`
+}
+
+// SetPlanRequest is the body accepted by PUT /admin/subscriptions/{account}.
+type SetPlanRequest struct {
+	Plan string `json:"plan"` // empty returns the account to the default plan
+}
@@ -0,0 +1,83 @@
+package model
+
+import "time"
+
+// Report frequencies.
+const (
+	ReportDaily  = "daily"
+	ReportWeekly = "weekly"
+)
+
+// Report deliveries.
+const (
+	ReportDeliveryWebhook = "webhook" // POST to WebhookURL
+	ReportDeliveryFile    = "file"    // write to the configured report directory
+)
+
+// Export and report formats.
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// ReportScope selects the links of an export or report. Exactly one field is set.
+type ReportScope struct {
+	ShortCode  string `bson:"short_code,omitempty"  json:"short_code,omitempty"`
+	Tag        string `bson:"tag,omitempty"         json:"tag,omitempty"`
+	CampaignID string `bson:"campaign_id,omitempty" json:"campaign_id,omitempty"`
+}
+
+// ExportRow is one bucket of one link in an analytics export.
+type ExportRow struct {
+	ShortCode string    `json:"short_code"`
+	Bucket    time.Time `json:"bucket"`
+	Clicks    int64     `json:"clicks"`
+	BotClicks int64     `json:"bot_clicks"`
+	Uniques   int64     `json:"uniques"`
+}
+
+type Report struct {
+	ID         string      `bson:"_id,omitempty"         json:"id"`
+	Owner      string      `bson:"owner"                 json:"owner"`
+	Name       string      `bson:"name"                  json:"name"`
+	Scope      ReportScope `bson:"scope"                 json:"scope"`
+	Frequency  string      `bson:"frequency"             json:"frequency"`
+	Format     string      `bson:"format"                json:"format"`
+	Delivery   string      `bson:"delivery"              json:"delivery"`
+	WebhookURL string      `bson:"webhook_url,omitempty" json:"webhook_url,omitempty"`
+	LastPeriod time.Time   `bson:"last_period,omitempty" json:"last_period,omitempty"`
+	LastRunAt  time.Time   `bson:"last_run_at,omitempty" json:"last_run_at,omitempty"`
+	LastError  string      `bson:"last_error,omitempty"  json:"last_error,omitempty"`
+	CreatedAt  time.Time   `bson:"created_at"            json:"created_at"`
+}
+
+// CreateReportRequest is the body accepted by POST /reports.
+type CreateReportRequest struct {
+	Name       string      `json:"name"`
+	Scope      ReportScope `json:"scope"`
+	Frequency  string      `json:"frequency"`
+	Format     string      `json:"format"`
+	Delivery   string      `json:"delivery"`
+	WebhookURL string      `json:"webhook_url"`
+}
+
+type LinkSummary struct {
+	ShortCode string `json:"short_code"`
+	Clicks    int64  `json:"clicks"`
+	BotClicks int64  `json:"bot_clicks"`
+	Uniques   int64  `json:"uniques"`
+}
+
+// ReportSummary is what a scheduled report delivers.
+type ReportSummary struct {
+	ReportID     string           `json:"report_id"`
+	Name         string           `json:"name"`
+	Scope        ReportScope      `json:"scope"`
+	From         time.Time        `json:"from"`
+	To           time.Time        `json:"to"`
+	Clicks       int64            `json:"clicks"`
+	BotClicks    int64            `json:"bot_clicks"`
+	Uniques      int64            `json:"uniques"`
+	Links        []LinkSummary    `json:"links"`
+	TopReferrers []DimensionCount `json:"top_referrers"`
+}
@@ -0,0 +1,63 @@
+package model
+
+import "time"
+
+// Revision actions record why a link changed.
+const (
+	RevisionActionCreate = "create"
+	RevisionActionUpdate = "update"
+	RevisionActionRevert = "revert"
+)
+
+// LinkSettings is the editable part of a link that revisions capture.
+type LinkSettings struct {
+	Original      string `bson:"original_url"             json:"original_url"`
+	ForwardQuery  bool   `bson:"forward_query"            json:"forward_query"`
+	QueryConflict string `bson:"query_conflict,omitempty" json:"query_conflict,omitempty"`
+	Wildcard      bool   `bson:"wildcard"                 json:"wildcard"`
+	CampaignID    string `bson:"campaign_id,omitempty"    json:"campaign_id,omitempty"`
+	NoTracking    bool   `bson:"no_tracking,omitempty"    json:"no_tracking,omitempty"`
+}
+
+// Revision is an immutable record of a link's settings after a change.
+type Revision struct {
+	ID           string       `bson:"_id"                     json:"id"`
+	ShortCode    string       `bson:"short_code"              json:"short_code"`
+	Number       int          `bson:"number"                  json:"number"`
+	Action       string       `bson:"action"                  json:"action"`
+	RevertedFrom int          `bson:"reverted_from,omitempty" json:"reverted_from,omitempty"`
+	Settings     LinkSettings `bson:"settings"                json:"settings"`
+	ChangedBy    string       `bson:"changed_by"              json:"changed_by"`
+	ChangedAt    time.Time    `bson:"changed_at"              json:"changed_at"`
+}
+
+// UpdateURLRequest is the body accepted by PATCH /urls/{short_code}.
+// Omitted fields keep their current value.
+type UpdateURLRequest struct {
+	OriginalURL   *string `json:"original_url"`
+	ForwardQuery  *bool   `json:"forward_query"`
+	QueryConflict *string `json:"query_conflict"`
+	Wildcard      *bool   `json:"wildcard"`
+	CampaignID    *string `json:"campaign_id"`
+	NoTracking    *bool   `json:"no_tracking"`
+}
+
+func SettingsOf(u *URL) LinkSettings {
+	return LinkSettings{
+		Original:      u.Original,
+		ForwardQuery:  u.ForwardQuery,
+		QueryConflict: u.QueryConflict,
+		Wildcard:      u.Wildcard,
+		CampaignID:    u.CampaignID,
+		NoTracking:    u.NoTracking,
+	}
+}
+
+func (s LinkSettings) ApplyTo(u *URL) {
+	u.Original = s.Original
+	u.ForwardQuery = s.ForwardQuery
+	u.QueryConflict = s.QueryConflict
+	u.Wildcard = s.Wildcard
+	u.CampaignID = s.CampaignID
+	u.NoTracking = s.NoTracking
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Screening verdicts on a link's destination.
+const (
+	VerdictClean      = "clean"
+	VerdictSuspicious = "suspicious" // redirects through a warning page
+	VerdictMalicious  = "malicious"  // redirects are blocked
+)
+
+// Screening is the outcome of the latest check of a link's destination
+// against the blocklists and heuristics.
+type Screening struct {
+	Verdict   string    `bson:"verdict"           json:"verdict"`
+	Reasons   []string  `bson:"reasons,omitempty" json:"reasons,omitempty"`
+	CheckedAt time.Time `bson:"checked_at"        json:"checked_at"`
+}
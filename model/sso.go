@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// MembershipSSO marks memberships granted from identity provider groups.
+// They are kept in step with the groups on every sign-in; memberships from
+// invitations are never touched by sign-in.
+const MembershipSSO = "sso"
+
+// SSOLogin is a sign-in waiting for the identity provider to send the user
+// back. It is stored under a hash of its state and used at most once.
+type SSOLogin struct {
+	ID        string    `bson:"_id"`
+	Nonce     string    `bson:"nonce"`
+	Verifier  string    `bson:"verifier"` // PKCE code verifier
+	CreatedAt time.Time `bson:"created_at"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// Session is returned by a completed sign-in. Token is the provider's ID
+// token, sent back as "Authorization: Bearer <token>" until it expires.
+type Session struct {
+	UserID      string       `json:"user_id"`
+	Token       string       `json:"token"`
+	TokenType   string       `json:"token_type"`
+	ExpiresAt   time.Time    `json:"expires_at"`
+	Memberships []Membership `json:"memberships"`
+}
@@ -2,10 +2,46 @@ package model
 
 import "time"
 
+// Query conflict modes decide which value wins when an incoming query
+// parameter is already present on the destination URL.
+const (
+	QueryConflictPreserve = "preserve" // destination value is kept
+	QueryConflictOverride = "override" // incoming value replaces it
+	QueryConflictAppend   = "append"   // both values are kept
+)
+
 type URL struct {
-	ID        string    `bson:"_id,omitempty" json:"id"`
-	Original  string    `bson:"original_url"  json:"original_url"`
-	ShortCode string    `bson:"short_code"    json:"short_code"`
-	CreatedAt time.Time `bson:"created_at"    json:"created_at"`
-	ShortURL  string    `bson:"-"             json:"short_url"`
+	ID            string      `bson:"_id,omitempty"            json:"id"`
+	Original      string      `bson:"original_url"             json:"original_url"`
+	ShortCode     string      `bson:"short_code"               json:"short_code"`
+	ForwardQuery  bool        `bson:"forward_query"            json:"forward_query"`
+	QueryConflict string      `bson:"query_conflict,omitempty" json:"query_conflict,omitempty"`
+	Wildcard      bool        `bson:"wildcard"                 json:"wildcard"`
+	CampaignID    string      `bson:"campaign_id,omitempty"    json:"campaign_id,omitempty"`
+	NoTracking    bool        `bson:"no_tracking,omitempty"    json:"no_tracking,omitempty"` // count clicks without visitor data
+	ClickCount    int64       `bson:"click_count"              json:"click_count"`
+	BotClickCount int64       `bson:"bot_click_count"          json:"bot_click_count"`
+	Owner         string      `bson:"owner,omitempty"          json:"owner,omitempty"`
+	Workspace     string      `bson:"workspace,omitempty"      json:"workspace,omitempty"` // shared with its members when set
+	Tags          []string    `bson:"tags,omitempty"           json:"tags,omitempty"`
+	Folder        string      `bson:"folder,omitempty"         json:"folder,omitempty"`
+	Revision      int         `bson:"revision"                 json:"revision"`
+	Screening     *Screening  `bson:"screening,omitempty"      json:"screening,omitempty"`
+	Moderation    *Moderation `bson:"moderation,omitempty"     json:"moderation,omitempty"`
+	CreatedAt     time.Time   `bson:"created_at"               json:"created_at"`
+	UpdatedAt     time.Time   `bson:"updated_at,omitempty"     json:"updated_at,omitempty"` // last edit; click counts and screening do not change it
+	ShortURL      string      `bson:"-"                        json:"short_url"`
+}
+
+// CreateURLRequest is the body accepted by POST /urls.
+type CreateURLRequest struct {
+	OriginalURL   string   `json:"original_url"`
+	ForwardQuery  bool     `json:"forward_query"`
+	QueryConflict string   `json:"query_conflict"`
+	Wildcard      bool     `json:"wildcard"`
+	CampaignID    string   `json:"campaign_id"`
+	NoTracking    bool     `json:"no_tracking"`
+	Tags          []string `json:"tags"`
+	Folder        string   `json:"folder"`
+	Workspace     string   `json:"workspace"`
 }
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// Webhook delivery states.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook subscribes an HTTP endpoint to link events of its owner. Secret
+// signs every payload and is only returned when the webhook is created.
+type Webhook struct {
+	ID        string    `bson:"_id"        json:"id"`
+	Owner     string    `bson:"owner"      json:"owner"`
+	URL       string    `bson:"url"        json:"url"`
+	Events    []string  `bson:"events"     json:"events"`
+	Secret    string    `bson:"secret"     json:"secret,omitempty"`
+	Active    bool      `bson:"active"     json:"active"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// CreateWebhookRequest is the body accepted by POST /webhooks. A secret is
+// generated when none is given.
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+}
+
+// WebhookDelivery logs the attempts to deliver one event to one webhook.
+type WebhookDelivery struct {
+	ID             string    `bson:"_id"                        json:"id"`
+	WebhookID      string    `bson:"webhook_id"                 json:"webhook_id"`
+	EventID        string    `bson:"event_id"                   json:"event_id"`
+	EventType      string    `bson:"event_type"                 json:"event_type"`
+	Payload        string    `bson:"payload"                    json:"payload"`
+	Status         string    `bson:"status"                     json:"status"`
+	Attempts       int       `bson:"attempts"                   json:"attempts"`
+	LastStatusCode int       `bson:"last_status_code,omitempty" json:"last_status_code,omitempty"`
+	LastError      string    `bson:"last_error,omitempty"       json:"last_error,omitempty"`
+	NextAttemptAt  time.Time `bson:"next_attempt_at,omitempty"  json:"next_attempt_at,omitempty"`
+	CreatedAt      time.Time `bson:"created_at"                 json:"created_at"`
+	UpdatedAt      time.Time `bson:"updated_at"                 json:"updated_at"`
+}
@@ -0,0 +1,68 @@
+package model
+
+import "time"
+
+// Workspace roles, from most to least privileged. Each role can do
+// everything the roles below it can.
+const (
+	RoleOwner  = "owner"  // manages owners and everything below
+	RoleAdmin  = "admin"  // invites and manages members up to admin
+	RoleEditor = "editor" // creates, edits and deletes links
+	RoleViewer = "viewer" // lists links and reads their analytics
+)
+
+// Workspace groups the links of a team.
+type Workspace struct {
+	ID        string    `bson:"_id"        json:"id"`
+	Name      string    `bson:"name"       json:"name"`
+	CreatedBy string    `bson:"created_by" json:"created_by"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	Owners    int64     `bson:"owners"     json:"-"`              // members with the owner role
+	Role      string    `bson:"-"          json:"role,omitempty"` // the caller's role
+}
+
+// Membership gives a user a role in a workspace.
+type Membership struct {
+	WorkspaceID string    `bson:"workspace_id"     json:"workspace_id"`
+	UserID      string    `bson:"user_id"          json:"user_id"`
+	Role        string    `bson:"role"             json:"role"`
+	Source      string    `bson:"source,omitempty" json:"source,omitempty"` // MembershipSSO when granted by sign-in
+	JoinedAt    time.Time `bson:"joined_at"        json:"joined_at"`
+}
+
+// Invitation lets whoever holds its token, or only UserID when set, join a
+// workspace once before it expires. Only a hash of the token is stored.
+type Invitation struct {
+	ID          string    `bson:"_id"                   json:"id"`
+	WorkspaceID string    `bson:"workspace_id"          json:"workspace_id"`
+	Role        string    `bson:"role"                  json:"role"`
+	UserID      string    `bson:"user_id,omitempty"     json:"user_id,omitempty"`
+	TokenHash   string    `bson:"token_hash"            json:"-"`
+	Token       string    `bson:"-"                     json:"token,omitempty"` // only returned when created
+	InvitedBy   string    `bson:"invited_by"            json:"invited_by"`
+	CreatedAt   time.Time `bson:"created_at"            json:"created_at"`
+	ExpiresAt   time.Time `bson:"expires_at"            json:"expires_at"`
+	AcceptedBy  string    `bson:"accepted_by,omitempty" json:"accepted_by,omitempty"`
+	AcceptedAt  time.Time `bson:"accepted_at,omitempty" json:"accepted_at,omitempty"`
+}
+
+// CreateWorkspaceRequest is the body accepted by POST /workspaces.
+type CreateWorkspaceRequest struct {
+	Name string `json:"name"`
+}
+
+// InviteRequest is the body accepted by POST /workspaces/{id}/invitations.
+type InviteRequest struct {
+	Role   string `json:"role"`
+	UserID string `json:"user_id"`
+}
+
+// AcceptInvitationRequest is the body accepted by POST /invitations/accept.
+type AcceptInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+// SetRoleRequest is the body accepted by PUT /workspaces/{id}/members/{user_id}.
+type SetRoleRequest struct {
+	Role string `json:"role"`
+}
@@ -0,0 +1,173 @@
+// Package oidc signs users in with an OpenID Connect provider using the
+// authorization code flow with PKCE, and verifies the ID tokens it issues
+// against the keys the provider publishes.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+// ErrNoIDToken is returned when the token response carries no ID token,
+// usually because the "openid" scope was not granted.
+var ErrNoIDToken = errors.New("oidc: token response has no id_token")
+
+// signingMethods are the algorithms accepted on ID tokens. "none" and the
+// HMAC family are never accepted, whatever the token header says.
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// clockSkew is the leeway given to the provider's clock on exp, iat and nbf.
+const clockSkew = time.Minute
+
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string // empty for public clients
+	RedirectURL  string
+	Scopes       []string // "openid" is always requested
+}
+
+// Provider is an OpenID Connect provider found by discovery.
+type Provider struct {
+	issuer string
+	config Config
+	oauth  *oauth2.Config
+	keys   *KeySet
+	client *http.Client
+}
+
+// IDToken is a verified ID token.
+type IDToken struct {
+	Raw     string
+	Subject string
+	Nonce   string
+	Expiry  time.Time
+	Claims  jwt.MapClaims
+}
+
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Discover reads the provider's configuration from its well-known document.
+// A nil client uses a default one with a timeout.
+func Discover(ctx context.Context, config Config, client *http.Client) (*Provider, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	issuer := strings.TrimSuffix(config.Issuer, "/")
+	var doc discovery
+	if err := getJSON(ctx, client, issuer+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(doc.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("oidc: provider reports issuer %q, expected %q", doc.Issuer, config.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, errors.New("oidc: discovery document is missing an endpoint")
+	}
+
+	scopes := []string{"openid"}
+	for _, scope := range config.Scopes {
+		if scope != "openid" && scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return &Provider{
+		issuer: doc.Issuer,
+		config: config,
+		oauth: &oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			RedirectURL:  config.RedirectURL,
+			Scopes:       scopes,
+			Endpoint:     oauth2.Endpoint{AuthURL: doc.AuthorizationEndpoint, TokenURL: doc.TokenEndpoint},
+		},
+		keys:   NewKeySet(doc.JWKSURI, client),
+		client: client,
+	}, nil
+}
+
+// AuthCodeURL is where to send the user to sign in. verifier is the PKCE
+// code verifier; only its S256 challenge leaves the service. loginHint, when
+// set, suggests the account to sign in with.
+func (p *Provider) AuthCodeURL(state, nonce, verifier, loginHint string) string {
+	opts := []oauth2.AuthCodeOption{oauth2.S256ChallengeOption(verifier), oauth2.SetAuthURLParam("nonce", nonce)}
+	if loginHint != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("login_hint", loginHint))
+	}
+	return p.oauth.AuthCodeURL(state, opts...)
+}
+
+// Exchange redeems an authorization code and verifies the ID token it
+// returns. The caller still has to compare the token's nonce.
+func (p *Provider) Exchange(ctx context.Context, code, verifier string) (*IDToken, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.client)
+	token, err := p.oauth.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("oidc: exchanging code: %w", err)
+	}
+	raw, _ := token.Extra("id_token").(string)
+	if raw == "" {
+		return nil, ErrNoIDToken
+	}
+	return p.Verify(ctx, raw)
+}
+
+// Verify checks the signature of an ID token against the provider's keys,
+// and that it was issued by the provider to this client and has not expired.
+func (p *Provider) Verify(ctx context.Context, raw string) (*IDToken, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.keys.Key(ctx, kid)
+	},
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(p.issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew))
+	if err != nil {
+		return nil, fmt.Errorf("oidc: %w", err)
+	}
+
+	subject, _ := claims.GetSubject()
+	if subject == "" {
+		return nil, errors.New("oidc: token has no subject")
+	}
+	expiry, _ := claims.GetExpirationTime()
+	nonce, _ := claims["nonce"].(string)
+	return &IDToken{Raw: raw, Subject: subject, Nonce: nonce, Expiry: expiry.Time, Claims: claims}, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("oidc: fetching %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc: fetching %s: status %d", url, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("oidc: decoding %s: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+
+	"github.com/sksmagr23/url-shortener-gofr/oidc"
+	"github.com/sksmagr23/url-shortener-gofr/oidc/oidctest"
+)
+
+func newProvider(t *testing.T) (*oidctest.Server, *oidc.Provider) {
+	t.Helper()
+	idp := oidctest.NewServer("shortener")
+	t.Cleanup(idp.Close)
+	provider, err := oidc.Discover(context.Background(), oidc.Config{
+		Issuer:      idp.URL,
+		ClientID:    "shortener",
+		RedirectURL: "http://localhost:8000/auth/callback",
+		Scopes:      []string{"openid", "email"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("discovering mock provider: %v", err)
+	}
+	return idp, provider
+}
+
+// authorize follows the sign-in redirect the way a browser would and returns
+// the callback parameters.
+func authorize(t *testing.T, authURL string) url.Values {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorizing: %v", err)
+	}
+	resp.Body.Close()
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if resp.StatusCode != http.StatusFound || err != nil {
+		t.Fatalf("authorizing: status %d, location %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	return callback.Query()
+}
+
+func TestProviderCodeFlow(t *testing.T) {
+	_, provider := newProvider(t)
+	verifier := oauth2.GenerateVerifier()
+
+	authURL := provider.AuthCodeURL("state-1", "nonce-1", verifier, "bob")
+	assert.Contains(t, authURL, "code_challenge_method=S256")
+	assert.NotContains(t, authURL, verifier)
+
+	callback := authorize(t, authURL)
+	assert.Equal(t, "state-1", callback.Get("state"))
+
+	t.Run("Wrong Verifier", func(t *testing.T) {
+		other := authorize(t, provider.AuthCodeURL("state-2", "nonce-2", verifier, ""))
+		_, err := provider.Exchange(context.Background(), other.Get("code"), oauth2.GenerateVerifier())
+		assert.Error(t, err)
+	})
+
+	token, err := provider.Exchange(context.Background(), callback.Get("code"), verifier)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "bob", token.Subject)
+	assert.Equal(t, "nonce-1", token.Nonce)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), token.Expiry, time.Minute)
+
+	_, err = provider.Exchange(context.Background(), callback.Get("code"), verifier)
+	assert.Error(t, err, "codes are single use")
+}
+
+func TestProviderVerify(t *testing.T) {
+	idp, provider := newProvider(t)
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	sign := func(method jwt.SigningMethod, key any, claims jwt.MapClaims) string {
+		signed, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		assert.NoError(t, err)
+		return signed
+	}
+	valid := jwt.MapClaims{"iss": idp.URL, "aud": "shortener", "sub": "bob", "exp": time.Now().Add(time.Hour).Unix()}
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{name: "Valid", token: idp.Sign("bob", map[string]any{"groups": []string{"eng"}}), valid: true},
+		{name: "Other Audience", token: idp.Sign("bob", map[string]any{"aud": "someone-else"})},
+		{name: "Other Issuer", token: idp.Sign("bob", map[string]any{"iss": "https://evil.example"})},
+		{name: "Expired", token: idp.Sign("bob", map[string]any{"exp": time.Now().Add(-time.Hour).Unix()})},
+		{name: "No Expiry", token: idp.Sign("bob", map[string]any{"exp": nil})},
+		{name: "No Subject", token: idp.Sign("", nil)},
+		{name: "Unknown Key", token: sign(jwt.SigningMethodRS256, otherKey, valid)},
+		{name: "HMAC", token: sign(jwt.SigningMethodHS256, []byte("shortener"), valid)},
+		{name: "Unsigned", token: sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, valid)},
+		{name: "Garbage", token: "not.a.token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := provider.Verify(context.Background(), tt.token)
+
+			if !tt.valid {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, "bob", token.Subject)
+				assert.Equal(t, []any{"eng"}, token.Claims["groups"])
+			}
+		})
+	}
+}
+
+func TestDiscoverIssuerMismatch(t *testing.T) {
+	idp := oidctest.NewServer("shortener")
+	defer idp.Close()
+
+	_, err := oidc.Discover(context.Background(), oidc.Config{Issuer: idp.URL + "/tenant", ClientID: "shortener"}, nil)
+
+	assert.Error(t, err)
+}
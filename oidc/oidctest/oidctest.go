@@ -0,0 +1,240 @@
+// Package oidctest runs a minimal OpenID Connect provider for tests and
+// local development. It signs in whoever asks, as whoever they ask to be:
+// never point a real deployment at it.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Server is the mock provider. Its URL is the issuer.
+type Server struct {
+	*httptest.Server
+	ClientID string
+	// Claims are added to every ID token, e.g. {"groups": [...]}. The
+	// login_hint sent to /authorize becomes the subject, "alice" by default.
+	Claims map[string]any
+	// TokenTTL is how long issued ID tokens stay valid.
+	TokenTTL time.Duration
+
+	mu     sync.Mutex
+	key    *rsa.PrivateKey
+	kid    string
+	grants map[string]grant
+}
+
+// grant is an authorization code waiting to be redeemed.
+type grant struct {
+	subject     string
+	nonce       string
+	challenge   string
+	redirectURI string
+}
+
+// NewServer starts a provider on a random local port.
+func NewServer(clientID string) *Server {
+	s := newServer(clientID)
+	s.Start()
+	return s
+}
+
+// Listen starts a provider on addr, such as "127.0.0.1:9000".
+func Listen(addr, clientID string) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := newServer(clientID)
+	s.Listener.Close()
+	s.Listener = listener
+	s.Start()
+	return s, nil
+}
+
+func newServer(clientID string) *Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	s := &Server{
+		ClientID: clientID,
+		Claims:   map[string]any{},
+		TokenTTL: time.Hour,
+		key:      key,
+		kid:      randomHex(8),
+		grants:   map[string]grant{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("GET /jwks", s.jwks)
+	mux.HandleFunc("GET /authorize", s.authorize)
+	mux.HandleFunc("POST /token", s.token)
+	s.Server = httptest.NewUnstartedServer(mux)
+	return s
+}
+
+// Sign issues an ID token for subject carrying claims, on top of the
+// standard ones. Tests use it to skip the browser part of the flow.
+func (s *Server) Sign(subject string, claims map[string]any) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	token := jwt.MapClaims{
+		"iss": s.URL,
+		"aud": s.ClientID,
+		"sub": subject,
+		"iat": now.Unix(),
+		"exp": now.Add(s.TokenTTL).Unix(),
+	}
+	for name, value := range s.Claims {
+		token[name] = value
+	}
+	for name, value := range claims {
+		token[name] = value
+	}
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodRS256, token)
+	jwtToken.Header["kid"] = s.kid
+	signed, err := jwtToken.SignedString(s.key)
+	if err != nil {
+		panic(err)
+	}
+	return signed
+}
+
+func (s *Server) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                s.URL,
+		"authorization_endpoint":                s.URL + "/authorize",
+		"token_endpoint":                        s.URL + "/token",
+		"jwks_uri":                              s.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	public := s.key.PublicKey
+	kid := s.kid
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+	}}})
+}
+
+// authorize approves every request at once and sends the browser back with
+// a code, as a real provider would after the user signed in.
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirect, err := url.Parse(query.Get("redirect_uri"))
+	switch {
+	case err != nil || redirect.Scheme == "":
+		http.Error(w, "redirect_uri required", http.StatusBadRequest)
+		return
+	case query.Get("client_id") != s.ClientID:
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	case query.Get("response_type") != "code":
+		http.Error(w, "response_type must be code", http.StatusBadRequest)
+		return
+	case query.Get("code_challenge") == "" || query.Get("code_challenge_method") != "S256":
+		http.Error(w, "PKCE with S256 is required", http.StatusBadRequest)
+		return
+	}
+
+	subject := query.Get("login_hint")
+	if subject == "" {
+		subject = "alice"
+	}
+	code := randomHex(16)
+	s.mu.Lock()
+	s.grants[code] = grant{
+		subject:     subject,
+		nonce:       query.Get("nonce"),
+		challenge:   query.Get("code_challenge"),
+		redirectURI: query.Get("redirect_uri"),
+	}
+	s.mu.Unlock()
+
+	params := redirect.Query()
+	params.Set("code", code)
+	params.Set("state", query.Get("state"))
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+	clientID := r.PostForm.Get("client_id")
+	if user, _, ok := r.BasicAuth(); ok {
+		clientID = user
+	}
+	code := r.PostForm.Get("code")
+	s.mu.Lock()
+	granted, ok := s.grants[code]
+	delete(s.grants, code)
+	s.mu.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	switch {
+	case r.PostForm.Get("grant_type") != "authorization_code" || clientID != s.ClientID:
+		tokenError(w, "invalid_client")
+	case !ok || r.PostForm.Get("redirect_uri") != granted.redirectURI:
+		tokenError(w, "invalid_grant")
+	case base64.RawURLEncoding.EncodeToString(verifier[:]) != granted.challenge:
+		tokenError(w, "invalid_grant")
+	default:
+		claims := map[string]any{}
+		if granted.nonce != "" {
+			claims["nonce"] = granted.nonce
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"access_token": randomHex(16),
+			"token_type":   "Bearer",
+			"expires_in":   int(s.TokenTTL.Seconds()),
+			"id_token":     s.Sign(granted.subject, claims),
+		})
+	}
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
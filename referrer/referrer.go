@@ -0,0 +1,286 @@
+// Package referrer normalizes Referer headers to domains and attributes
+// visits to a source and a marketing channel.
+package referrer
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Channels a visit can be attributed to.
+const (
+	ChannelSearch   = "search"
+	ChannelSocial   = "social"
+	ChannelEmail    = "email"
+	ChannelDirect   = "direct"
+	ChannelInternal = "internal"
+	ChannelReferral = "referral" // any other site
+)
+
+// Channels lists every channel in the order they are reported.
+var Channels = []string{ChannelSearch, ChannelSocial, ChannelEmail, ChannelReferral, ChannelInternal, ChannelDirect}
+
+// Domains reported for visits without a usable referrer.
+const (
+	Direct  = "direct"
+	Unknown = "unknown"
+)
+
+// OtherSource is reported for utm_source values that are too long, contain
+// unexpected characters or arrive after MaxSources distinct values were seen.
+const OtherSource = "other"
+
+// Limits on utm_source values, which come from visitors and would otherwise
+// let anyone add any number of keys to the analytics of a link.
+const (
+	MaxSourceLength = 64
+	MaxSources      = 1000
+)
+
+// Rule attributes referrers from Domain and its subdomains to a channel. A
+// Domain ending in ".*" matches under any top level domain, so "google.*"
+// covers google.com and google.co.uk. Source names the site in reports and
+// defaults to the referrer domain.
+type Rule struct {
+	Domain  string
+	Channel string
+	Source  string
+}
+
+// DefaultRules cover the common webmail providers, search engines and social
+// networks. Rules are checked in order, so webmail comes before the search
+// engines whose domains it shares.
+var DefaultRules = []Rule{
+	{"mail.google.com", ChannelEmail, "gmail"},
+	{"outlook.live.com", ChannelEmail, "outlook"},
+	{"outlook.office.com", ChannelEmail, "outlook"},
+	{"mail.yahoo.com", ChannelEmail, "yahoo mail"},
+	{"mail.proton.me", ChannelEmail, "proton mail"},
+	{"google.*", ChannelSearch, "google"},
+	{"bing.com", ChannelSearch, "bing"},
+	{"duckduckgo.com", ChannelSearch, "duckduckgo"},
+	{"yahoo.*", ChannelSearch, "yahoo"},
+	{"yandex.*", ChannelSearch, "yandex"},
+	{"baidu.com", ChannelSearch, "baidu"},
+	{"ecosia.org", ChannelSearch, "ecosia"},
+	{"search.brave.com", ChannelSearch, "brave"},
+	{"facebook.com", ChannelSocial, "facebook"},
+	{"fb.me", ChannelSocial, "facebook"},
+	{"instagram.com", ChannelSocial, "instagram"},
+	{"t.co", ChannelSocial, "twitter"},
+	{"twitter.com", ChannelSocial, "twitter"},
+	{"x.com", ChannelSocial, "twitter"},
+	{"linkedin.com", ChannelSocial, "linkedin"},
+	{"lnkd.in", ChannelSocial, "linkedin"},
+	{"reddit.com", ChannelSocial, "reddit"},
+	{"youtube.com", ChannelSocial, "youtube"},
+	{"tiktok.com", ChannelSocial, "tiktok"},
+	{"pinterest.*", ChannelSocial, "pinterest"},
+	{"news.ycombinator.com", ChannelSocial, "hackernews"},
+	{"mastodon.social", ChannelSocial, "mastodon"},
+	{"threads.net", ChannelSocial, "threads"},
+}
+
+// mediumChannels maps common utm_medium values to channels.
+var mediumChannels = map[string]string{
+	"email":      ChannelEmail,
+	"e-mail":     ChannelEmail,
+	"newsletter": ChannelEmail,
+	"social":     ChannelSocial,
+	"cpc":        ChannelSearch,
+	"ppc":        ChannelSearch,
+	"organic":    ChannelSearch,
+	"search":     ChannelSearch,
+	"referral":   ChannelReferral,
+}
+
+// Domain normalizes a Referer header to a lower-case host without "www." or
+// port. Empty referrers are Direct, unparsable ones Unknown.
+func Domain(raw string) string {
+	if raw == "" {
+		return Direct
+	}
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || parsed.Hostname() == "" {
+		return Unknown
+	}
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	return strings.TrimPrefix(host, "www.")
+}
+
+// Attribution is where a visit came from.
+type Attribution struct {
+	Domain  string
+	Source  string
+	Channel string
+}
+
+// Classifier attributes visits using a rules table. Domains listed as
+// internal, such as the short link host itself, count as ChannelInternal.
+type Classifier struct {
+	rules []Rule
+
+	mu      sync.Mutex
+	sources map[string]struct{} // utm_source values admitted so far
+}
+
+// NewClassifier returns a classifier checking rules before DefaultRules.
+func NewClassifier(internal []string, rules ...Rule) *Classifier {
+	c := &Classifier{sources: make(map[string]struct{})}
+	for _, domain := range internal {
+		if domain = Domain(domain); domain != Direct && domain != Unknown {
+			c.rules = append(c.rules, Rule{Domain: domain, Channel: ChannelInternal})
+		}
+	}
+	c.rules = append(c.rules, rules...)
+	c.rules = append(c.rules, DefaultRules...)
+	return c
+}
+
+// ParseRules reads a rules table of comma-separated "domain=channel" or
+// "domain=channel:source" entries. Entries with an unknown channel are skipped.
+func ParseRules(table string) []Rule {
+	var rules []Rule
+	for _, entry := range strings.Split(table, ",") {
+		domain, target, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || domain == "" {
+			continue
+		}
+		channel, source, _ := strings.Cut(target, ":")
+		channel = strings.ToLower(strings.TrimSpace(channel))
+		if !validChannel(channel) {
+			continue
+		}
+		rules = append(rules, Rule{
+			Domain:  strings.ToLower(strings.TrimSpace(domain)),
+			Channel: channel,
+			Source:  strings.ToLower(strings.TrimSpace(source)),
+		})
+	}
+	return rules
+}
+
+// Classify attributes a visit from its Referer header and the query string
+// of the inbound request. utm_source, when present, names the source; its
+// channel comes from utm_medium, then from a rule naming that source, and
+// otherwise from the referrer. Sources no rule names are reported as
+// OtherSource once MaxSources of them have been seen.
+func (c *Classifier) Classify(raw string, query url.Values) Attribution {
+	domain := Domain(raw)
+	attribution := Attribution{Domain: domain, Source: domain, Channel: ChannelReferral}
+	switch domain {
+	case Direct:
+		attribution.Channel = ChannelDirect
+	case Unknown:
+	default:
+		if rule, ok := c.match(domain); ok {
+			attribution.Channel = rule.Channel
+			if rule.Source != "" {
+				attribution.Source = rule.Source
+			}
+		}
+	}
+
+	source := strings.ToLower(strings.TrimSpace(query.Get("utm_source")))
+	if source == "" {
+		return attribution
+	}
+	rule, known := c.matchSource(source)
+	if !known {
+		source = c.admit(source)
+	}
+	attribution.Source = source
+	if channel, ok := mediumChannels[strings.ToLower(strings.TrimSpace(query.Get("utm_medium")))]; ok {
+		attribution.Channel = channel
+	} else if known {
+		attribution.Channel = rule.Channel
+	} else if attribution.Channel == ChannelDirect {
+		// A tagged link was followed, just not from a page that sent a referrer.
+		attribution.Channel = ChannelReferral
+	}
+	return attribution
+}
+
+// admit returns source when it is well formed and either seen before or
+// within MaxSources, and OtherSource otherwise.
+func (c *Classifier) admit(source string) string {
+	if !validSource(source) {
+		return OtherSource
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.sources[source]; ok {
+		return source
+	}
+	if len(c.sources) >= MaxSources {
+		return OtherSource
+	}
+	c.sources[source] = struct{}{}
+	return source
+}
+
+// validSource accepts up to MaxSourceLength letters, digits and the
+// punctuation found in source names and domains.
+func validSource(source string) bool {
+	if len(source) > MaxSourceLength {
+		return false
+	}
+	for _, r := range source {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && !strings.ContainsRune("._-+ ", r) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Classifier) match(domain string) (Rule, bool) {
+	for _, rule := range c.rules {
+		if matches(rule.Domain, domain) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+func (c *Classifier) matchSource(source string) (Rule, bool) {
+	for _, rule := range c.rules {
+		if rule.Source == source || matches(rule.Domain, source) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// secondLevels are the labels country code domains register names under, as
+// in google.co.uk or yahoo.com.au.
+var secondLevels = map[string]bool{"co": true, "com": true, "net": true, "org": true, "ac": true,
+	"gov": true, "edu": true, "ne": true, "or": true, "go": true}
+
+// matches reports whether domain is pattern or one of its subdomains.
+func matches(pattern, domain string) bool {
+	if name, ok := strings.CutSuffix(pattern, ".*"); ok {
+		// Compare everything up to the top level domain. Only a country code
+		// domain under a known second level, such as co.uk, spans two labels,
+		// so google.* does not match google.evil.com.
+		labels := strings.Split(domain, ".")
+		suffix := 1
+		if n := len(labels); n > 2 && len(labels[n-1]) == 2 && secondLevels[labels[n-2]] {
+			suffix = 2
+		}
+		if len(labels) <= suffix {
+			return false
+		}
+		return matches(name, strings.Join(labels[:len(labels)-suffix], "."))
+	}
+	return domain == pattern || strings.HasSuffix(domain, "."+pattern)
+}
+
+func validChannel(channel string) bool {
+	for _, c := range Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package referrer_test
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sksmagr23/url-shortener-gofr/referrer"
+)
+
+func TestDomain(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected string
+	}{
+		{raw: "", expected: referrer.Direct},
+		{raw: "https://WWW.Example.com:8443/path?q=1", expected: "example.com"},
+		{raw: "https://news.ycombinator.com/item?id=1", expected: "news.ycombinator.com"},
+		{raw: "https://t.co./abc", expected: "t.co"},
+		{raw: "not a url", expected: referrer.Unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			assert.Equal(t, tt.expected, referrer.Domain(tt.raw))
+		})
+	}
+}
+
+func TestClassifierClassify(t *testing.T) {
+	classifier := referrer.NewClassifier([]string{"http://sho.rt/"},
+		referrer.ParseRules("intranet.example.com=internal, blog.example.com=referral:blog, bad=nonsense")...)
+
+	tests := []struct {
+		name     string
+		raw      string
+		query    url.Values
+		expected referrer.Attribution
+	}{
+		{
+			name:     "Direct",
+			expected: referrer.Attribution{Domain: referrer.Direct, Source: referrer.Direct, Channel: referrer.ChannelDirect},
+		},
+		{
+			name:     "Search Under Country Domain",
+			raw:      "https://www.google.co.uk/",
+			expected: referrer.Attribution{Domain: "google.co.uk", Source: "google", Channel: referrer.ChannelSearch},
+		},
+		{
+			name:     "Search Name Under Another Domain",
+			raw:      "https://google.evil.com/",
+			expected: referrer.Attribution{Domain: "google.evil.com", Source: "google.evil.com", Channel: referrer.ChannelReferral},
+		},
+		{
+			name:     "Webmail Before Search",
+			raw:      "https://mail.google.com/mail/u/0/",
+			expected: referrer.Attribution{Domain: "mail.google.com", Source: "gmail", Channel: referrer.ChannelEmail},
+		},
+		{
+			name:     "Social Subdomain",
+			raw:      "https://l.facebook.com/l.php?u=x",
+			expected: referrer.Attribution{Domain: "l.facebook.com", Source: "facebook", Channel: referrer.ChannelSocial},
+		},
+		{
+			name:     "Short Link Host",
+			raw:      "http://sho.rt/abc123",
+			expected: referrer.Attribution{Domain: "sho.rt", Source: "sho.rt", Channel: referrer.ChannelInternal},
+		},
+		{
+			name:     "Configured Rule",
+			raw:      "https://blog.example.com/post",
+			expected: referrer.Attribution{Domain: "blog.example.com", Source: "blog", Channel: referrer.ChannelReferral},
+		},
+		{
+			name:     "Configured Internal Domain",
+			raw:      "https://intranet.example.com/",
+			expected: referrer.Attribution{Domain: "intranet.example.com", Source: "intranet.example.com", Channel: referrer.ChannelInternal},
+		},
+		{
+			name:     "Other Site",
+			raw:      "https://someblog.dev/",
+			expected: referrer.Attribution{Domain: "someblog.dev", Source: "someblog.dev", Channel: referrer.ChannelReferral},
+		},
+		{
+			name:     "utm_source With Medium",
+			query:    url.Values{"utm_source": {"Newsletter"}, "utm_medium": {"email"}},
+			expected: referrer.Attribution{Domain: referrer.Direct, Source: "newsletter", Channel: referrer.ChannelEmail},
+		},
+		{
+			name:     "utm_source Naming A Known Site",
+			raw:      "https://someblog.dev/",
+			query:    url.Values{"utm_source": {"linkedin"}},
+			expected: referrer.Attribution{Domain: "someblog.dev", Source: "linkedin", Channel: referrer.ChannelSocial},
+		},
+		{
+			name:     "utm_source Malformed",
+			query:    url.Values{"utm_source": {"<script>alert(1)</script>"}},
+			expected: referrer.Attribution{Domain: referrer.Direct, Source: referrer.OtherSource, Channel: referrer.ChannelReferral},
+		},
+		{
+			name:     "utm_source Without Referrer",
+			query:    url.Values{"utm_source": {"partner"}},
+			expected: referrer.Attribution{Domain: referrer.Direct, Source: "partner", Channel: referrer.ChannelReferral},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, classifier.Classify(tt.raw, tt.query))
+		})
+	}
+}
+
+func TestClassifierLimitsSources(t *testing.T) {
+	classifier := referrer.NewClassifier(nil)
+	for i := 0; i < referrer.MaxSources; i++ {
+		source := fmt.Sprintf("partner-%d", i)
+		assert.Equal(t, source, classifier.Classify("", url.Values{"utm_source": {source}}).Source)
+	}
+
+	assert.Equal(t, referrer.OtherSource, classifier.Classify("", url.Values{"utm_source": {"one-more"}}).Source)
+	assert.Equal(t, "partner-0", classifier.Classify("", url.Values{"utm_source": {"partner-0"}}).Source)
+	assert.Equal(t, "linkedin", classifier.Classify("", url.Values{"utm_source": {"linkedin"}}).Source)
+	assert.Equal(t, referrer.OtherSource,
+		classifier.Classify("", url.Values{"utm_source": {strings.Repeat("a", referrer.MaxSourceLength+1)}}).Source)
+}
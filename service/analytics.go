@@ -0,0 +1,132 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/hll"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+const (
+	defaultAnalyticsRange = 7 * 24 * time.Hour
+	topDimensionValues    = 10
+)
+
+type AnalyticsServiceImpl struct {
+	URLs      *store.URLStore
+	Rollups   *store.RollupStore
+	Workspace *WorkspaceAccess
+}
+
+func NewAnalyticsService(urls *store.URLStore, rollups *store.RollupStore, access *WorkspaceAccess) AnalyticsService {
+	return &AnalyticsServiceImpl{URLs: urls, Rollups: rollups, Workspace: access}
+}
+
+type AnalyticsService interface {
+	Link(ctx *gofr.Context, code string, query model.AnalyticsQuery) (*model.LinkAnalytics, error)
+	Export(ctx *gofr.Context, scope model.ReportScope, query model.AnalyticsQuery) ([]model.ExportRow, error)
+}
+
+// Link reports the clicks of a link the caller may view from the rollups. The
+// range defaults to the last seven days at day granularity.
+func (s *AnalyticsServiceImpl) Link(ctx *gofr.Context, code string, query model.AnalyticsQuery) (*model.LinkAnalytics, error) {
+	if _, err := s.Workspace.FindVisible(ctx, s.URLs, code); err != nil {
+		return nil, err
+	}
+
+	query = withQueryDefaults(query)
+	// Widen the range to whole buckets so partial buckets at the edges are included.
+	from := BucketStart(query.Granularity, query.From)
+	rollups, err := s.Rollups.Find(ctx, code, query.Granularity, from, query.To)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(rollups, func(i, j int) bool { return rollups[i].Bucket.Before(rollups[j].Bucket) })
+
+	result := &model.LinkAnalytics{
+		ShortCode:   code,
+		Granularity: query.Granularity,
+		GroupBy:     query.GroupBy,
+		From:        from,
+		To:          query.To,
+		Series:      make([]model.AnalyticsPoint, 0, len(rollups)),
+	}
+	visitors := hll.New()
+	dimensions := make(map[string]map[string]int64)
+	for _, rollup := range rollups {
+		bucketVisitors := sketchOf(rollup.Visitors)
+		visitors.Merge(bucketVisitors)
+		result.Clicks += rollup.Clicks
+		result.BotClicks += rollup.BotClicks
+		result.Series = append(result.Series, model.AnalyticsPoint{
+			Bucket:    rollup.Bucket,
+			Clicks:    rollup.Clicks,
+			BotClicks: rollup.BotClicks,
+			Uniques:   int64(bucketVisitors.Estimate()),
+		})
+		for dimension, values := range rollup.Dimensions {
+			if dimensions[dimension] == nil {
+				dimensions[dimension] = make(map[string]int64)
+			}
+			for value, count := range values {
+				dimensions[dimension][value] += count
+			}
+		}
+	}
+	result.Uniques = int64(visitors.Estimate())
+	result.Top = make(map[string][]model.DimensionCount, len(dimensions))
+	for dimension, values := range dimensions {
+		counts := rankValues(values)
+		if len(counts) > topDimensionValues {
+			counts = counts[:topDimensionValues]
+		}
+		result.Top[dimension] = counts
+	}
+	result.Channels = rankValues(dimensions[model.DimensionChannel])
+	if query.GroupBy != "" {
+		result.Groups = rankValues(dimensions[query.GroupBy])
+	}
+	return result, nil
+}
+
+func withQueryDefaults(query model.AnalyticsQuery) model.AnalyticsQuery {
+	if query.Granularity == "" {
+		query.Granularity = model.GranularityDay
+	}
+	if query.To.IsZero() {
+		query.To = time.Now().UTC()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.Add(-defaultAnalyticsRange)
+	}
+	return query
+}
+
+// rankValues orders the values of a dimension by clicks, with each value's
+// share of the dimension's clicks in percent.
+func rankValues(values map[string]int64) []model.DimensionCount {
+	var total int64
+	for _, clicks := range values {
+		total += clicks
+	}
+	counts := make([]model.DimensionCount, 0, len(values))
+	for value, clicks := range values {
+		counts = append(counts, model.DimensionCount{
+			Value:      value,
+			Clicks:     clicks,
+			Percentage: math.Round(float64(clicks)/float64(total)*10000) / 100,
+		})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Clicks != counts[j].Clicks {
+			return counts[i].Clicks > counts[j].Clicks
+		}
+		return counts[i].Value < counts[j].Value
+	})
+	return counts
+}
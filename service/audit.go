@@ -0,0 +1,134 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"go.opentelemetry.io/otel/trace"
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+// Auditor appends mutating operations to the audit log. A nil *Auditor is
+// valid and records nothing, so services can treat auditing as optional.
+type Auditor struct {
+	Store *store.AuditStore
+}
+
+func NewAuditor(store *store.AuditStore) *Auditor {
+	return &Auditor{Store: store}
+}
+
+type AuditService interface {
+	Query(ctx *gofr.Context, filter model.AuditFilter) ([]model.AuditEntry, error)
+}
+
+// Record stores who performed action on resource and how it changed. The
+// operation itself has already happened, so a failed write is only logged.
+func (a *Auditor) Record(ctx *gofr.Context, action, resource string, before, after any) {
+	if a == nil {
+		return
+	}
+	entry := &model.AuditEntry{
+		Action:    action,
+		Resource:  resource,
+		Actor:     middleware.Actor(ctx),
+		IP:        middleware.ClientIP(ctx),
+		RequestID: requestID(ctx),
+		Changes:   Diff(before, after),
+	}
+	if err := a.Store.Insert(ctx, entry); err != nil {
+		ctx.Logger.Errorf("writing audit entry %s %s: %v", action, resource, err)
+	}
+}
+
+// Query returns matching audit entries, newest first.
+func (a *Auditor) Query(ctx *gofr.Context, filter model.AuditFilter) ([]model.AuditEntry, error) {
+	entries, err := a.Store.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.After(entries[j].Timestamp)
+	})
+	return entries, nil
+}
+
+// requestID is the trace ID GoFr assigns to the request, which it also
+// returns to clients as X-Correlation-ID.
+func requestID(ctx *gofr.Context) string {
+	spanContext := trace.SpanFromContext(ctx).SpanContext()
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
+
+// Diff compares the JSON form of before and after and lists the fields that
+// differ. Either side may be nil, e.g. for creations and deletions.
+func Diff(before, after any) []model.AuditChange {
+	beforeFields := flatten(before)
+	afterFields := flatten(after)
+
+	fields := make(map[string]bool, len(beforeFields)+len(afterFields))
+	for field := range beforeFields {
+		fields[field] = true
+	}
+	for field := range afterFields {
+		fields[field] = true
+	}
+
+	changes := make([]model.AuditChange, 0)
+	for field := range fields {
+		if reflect.DeepEqual(beforeFields[field], afterFields[field]) {
+			continue
+		}
+		changes = append(changes, model.AuditChange{
+			Field:  field,
+			Before: beforeFields[field],
+			After:  afterFields[field],
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
+
+func flatten(value any) map[string]any {
+	fields := make(map[string]any)
+	if value == nil {
+		return fields
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fields
+	}
+	var decoded any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return fields
+	}
+	flattenInto(fields, "", decoded)
+	return fields
+}
+
+func flattenInto(fields map[string]any, prefix string, value any) {
+	object, ok := value.(map[string]any)
+	if !ok {
+		if prefix != "" {
+			fields[prefix] = value
+		}
+		return
+	}
+	for key, nested := range object {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		flattenInto(fields, path, nested)
+	}
+}
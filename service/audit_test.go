@@ -0,0 +1,106 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+func TestDiff(t *testing.T) {
+	before := model.LinkSettings{Original: "https://example.com/old", Wildcard: true}
+	after := model.LinkSettings{Original: "https://example.com/new", Wildcard: true}
+
+	assert.Equal(t, []model.AuditChange{
+		{Field: "original_url", Before: "https://example.com/old", After: "https://example.com/new"},
+	}, service.Diff(before, after))
+
+	created := service.Diff(nil, map[string]any{"settings": map[string]any{"wildcard": true}})
+	assert.Equal(t, []model.AuditChange{{Field: "settings.wildcard", After: true}}, created)
+}
+
+func TestAuditorRecord(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	auditor := service.NewAuditor(store.NewAuditStore())
+
+	var recorded *model.AuditEntry
+	mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "audit_log", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, document any) (any, error) {
+			recorded = document.(*model.AuditEntry)
+			return "id", nil
+		})
+
+	meta := middleware.GetRequestMeta(context.Background())
+	meta.Header.Set(middleware.ActorHeader, "alice")
+	meta.Header.Set("X-Forwarded-For", "198.51.100.9, 203.0.113.7, 10.0.0.1")
+	meta.RemoteAddr = "10.0.0.2:41000"
+	proxies, _ := middleware.ParseTrustedProxies("10.0.0.0/8")
+	meta.ClientIP = proxies.ClientIP(meta.Header, meta.RemoteAddr)
+	ctx := &gofr.Context{
+		Context:   middleware.WithRequestMeta(context.Background(), meta),
+		Container: mockContainer,
+	}
+
+	auditor.Record(ctx, model.AuditLinkMove, "link:abc123",
+		map[string]any{"folder": ""}, map[string]any{"folder": "marketing"})
+
+	assert.Equal(t, model.AuditLinkMove, recorded.Action)
+	assert.Equal(t, "link:abc123", recorded.Resource)
+	assert.Equal(t, "alice", recorded.Actor)
+	assert.Equal(t, "203.0.113.7", recorded.IP)
+	assert.Equal(t, []model.AuditChange{{Field: "folder", Before: "", After: "marketing"}}, recorded.Changes)
+	assert.NotZero(t, recorded.Timestamp)
+}
+
+func TestTrustedProxiesClientIP(t *testing.T) {
+	proxies, err := middleware.ParseTrustedProxies("10.0.0.0/8, 192.0.2.1")
+	assert.NoError(t, err)
+
+	header := http.Header{}
+	header.Set("X-Forwarded-For", "198.51.100.9")
+	assert.Equal(t, "203.0.113.7", proxies.ClientIP(header, "203.0.113.7:41000"), "untrusted peer")
+	assert.Equal(t, "198.51.100.9", proxies.ClientIP(header, "192.0.2.1:41000"))
+	assert.Equal(t, "192.0.2.1", proxies.ClientIP(http.Header{}, "192.0.2.1:41000"))
+
+	_, err = middleware.ParseTrustedProxies("10.0.0.0/40")
+	assert.Error(t, err)
+}
+
+func TestAuditorNilIsNoop(t *testing.T) {
+	var auditor *service.Auditor
+	assert.NotPanics(t, func() {
+		auditor.Record(&gofr.Context{Context: context.Background()}, model.AuditLinkCreate, "link:x", nil, nil)
+	})
+}
+
+func TestAuditorQueryNewestFirst(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	auditor := service.NewAuditor(store.NewAuditStore())
+
+	now := time.Now().UTC()
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "audit_log", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.AuditEntry) = []model.AuditEntry{
+				{ID: "old", Timestamp: now.Add(-time.Hour)},
+				{ID: "new", Timestamp: now},
+			}
+			return nil
+		})
+
+	ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+
+	entries, err := auditor.Query(ctx, model.AuditFilter{Actor: "alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, "new", entries[0].ID)
+	assert.Equal(t, "old", entries[1].ID)
+}
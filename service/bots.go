@@ -0,0 +1,62 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/useragent"
+)
+
+// OtherBot is reported for bots that are not on the pattern list.
+const OtherBot = useragent.OtherBot
+
+// Names reported for bots recognised by heuristics rather than by user agent.
+const (
+	BotNoUserAgent = "No user agent"
+	BotHeadRequest = "HEAD request"
+	BotPrefetch    = "Prefetch"
+)
+
+// BotClassifier tells crawlers, link unfurlers and monitors apart from people.
+// It recognises bots by user agent like useragent.Parse does, and by how
+// they request a link.
+type BotClassifier struct {
+	agents *useragent.BotMatcher
+}
+
+// NewBotClassifier returns a classifier using the built-in pattern list plus
+// extra user agent tokens, which are reported under their own name.
+func NewBotClassifier(extra ...string) *BotClassifier {
+	return &BotClassifier{agents: useragent.NewBotMatcher(extra...)}
+}
+
+// Classify returns the name of the bot behind the request, or "" for a person.
+func (c *BotClassifier) Classify(meta middleware.RequestMeta) string {
+	userAgent := meta.Header.Get("User-Agent")
+	if userAgent == "" {
+		return BotNoUserAgent
+	}
+	if name := c.agents.Match(userAgent); name != "" {
+		return name
+	}
+	// Unfurlers often check a link with HEAD before fetching it.
+	if meta.Method == "HEAD" {
+		return BotHeadRequest
+	}
+	if isPrefetch(meta) {
+		return BotPrefetch
+	}
+	return ""
+}
+
+// isPrefetch reports whether a browser fetched the link speculatively,
+// before anyone clicked it.
+func isPrefetch(meta middleware.RequestMeta) bool {
+	for _, header := range []string{"Purpose", "Sec-Purpose", "X-Purpose", "X-Moz"} {
+		value := strings.ToLower(meta.Header.Get(header))
+		if strings.Contains(value, "prefetch") || strings.Contains(value, "preview") {
+			return true
+		}
+	}
+	return false
+}
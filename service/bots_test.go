@@ -0,0 +1,64 @@
+package service_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+)
+
+func TestBotClassifierClassify(t *testing.T) {
+	const browser = "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_4) AppleWebKit/605.1.15 Version/17.4 Safari/605.1.15"
+
+	tests := []struct {
+		name      string
+		method    string
+		userAgent string
+		header    http.Header
+		expected  string
+	}{
+		{name: "Browser", userAgent: browser, expected: ""},
+		{name: "Slack Unfurl", userAgent: "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)", expected: "Slackbot"},
+		{name: "Twitter Card", userAgent: "Twitterbot/1.0", expected: "Twitterbot"},
+		{name: "Uptime Monitor", userAgent: "Mozilla/5.0+(compatible; UptimeRobot/2.0)", expected: "UptimeRobot"},
+		{name: "Generic Crawler", userAgent: "ExampleCrawler/3.1", expected: service.OtherBot},
+		{name: "Generic Bot Word", userAgent: "Mozilla/5.0 (compatible; bot; +https://example.com)", expected: service.OtherBot},
+		{
+			name:      "Phone Maker Ending In Bot",
+			userAgent: "Mozilla/5.0 (Linux; Android 12; Cubot X30) AppleWebKit/537.36 Chrome/112.0 Mobile Safari/537.36",
+			expected:  "",
+		},
+		{name: "curl", userAgent: "curl/8.4.0", expected: "curl"},
+		{name: "Wget", userAgent: "Wget/1.21.4", expected: "Wget"},
+		{name: "Token Inside Word", userAgent: "Mozilla/5.0 (X11; Linux x86_64) NotSlackbotReally/2.0", expected: ""},
+		{name: "Extra Pattern", userAgent: "AcmeLinkChecker/1.0", expected: "AcmeLinkChecker"},
+		{name: "Missing User Agent", expected: service.BotNoUserAgent},
+		{name: "HEAD Request", method: http.MethodHead, userAgent: browser, expected: service.BotHeadRequest},
+		{name: "Prefetch", userAgent: browser, header: http.Header{"Sec-Purpose": {"prefetch;prerender"}}, expected: service.BotPrefetch},
+		{name: "Preview", userAgent: browser, header: http.Header{"X-Purpose": {"preview"}}, expected: service.BotPrefetch},
+	}
+
+	classifier := service.NewBotClassifier("AcmeLinkChecker", "")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for key, values := range tt.header {
+				header[key] = values
+			}
+			if tt.userAgent != "" {
+				header.Set("User-Agent", tt.userAgent)
+			}
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			got := classifier.Classify(middleware.RequestMeta{Method: method, Header: header})
+
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
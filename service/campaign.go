@@ -0,0 +1,228 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+var (
+	ErrCampaignNotFound = &apierror.Error{Status: http.StatusNotFound, Message: "campaign not found"}
+	ErrCampaignName     = &apierror.Error{Status: http.StatusBadRequest, Message: "campaign name is required"}
+	ErrInvalidApplyAt   = &apierror.Error{Status: http.StatusBadRequest, Message: "invalid apply_at"}
+)
+
+type CampaignServiceImpl struct {
+	Store     *store.CampaignStore
+	URLStore  *store.URLStore
+	Workspace *WorkspaceAccess
+	Audit     *Auditor
+}
+
+func NewCampaignService(
+	campaignStore *store.CampaignStore, urlStore *store.URLStore, access *WorkspaceAccess, auditor *Auditor,
+) CampaignService {
+	return &CampaignServiceImpl{Store: campaignStore, URLStore: urlStore, Workspace: access, Audit: auditor}
+}
+
+type CampaignService interface {
+	Create(ctx *gofr.Context, req *model.CreateCampaignRequest) (*model.Campaign, error)
+	Get(ctx *gofr.Context, id string) (*model.Campaign, error)
+	List(ctx *gofr.Context, workspace string) ([]model.Campaign, error)
+	Analytics(ctx *gofr.Context, id string) (*model.CampaignAnalytics, error)
+}
+
+func (s *CampaignServiceImpl) Create(ctx *gofr.Context, req *model.CreateCampaignRequest) (*model.Campaign, error) {
+	if req.Name == "" {
+		return nil, ErrCampaignName
+	}
+	applyAt := req.ApplyAt
+	if applyAt == "" {
+		applyAt = model.CampaignApplyAtRedirect
+	}
+	if applyAt != model.CampaignApplyAtCreate && applyAt != model.CampaignApplyAtRedirect {
+		return nil, ErrInvalidApplyAt
+	}
+	actor := middleware.Actor(ctx)
+	if actor == "" {
+		return nil, ErrActorRequired
+	}
+	if req.Workspace != "" {
+		if _, err := s.Workspace.Require(ctx, req.Workspace, model.RoleEditor); err != nil {
+			return nil, err
+		}
+	}
+	campaign := &model.Campaign{
+		Name:      req.Name,
+		UTM:       req.UTM,
+		ApplyAt:   applyAt,
+		Owner:     actor,
+		Workspace: req.Workspace,
+	}
+	if err := s.Store.Insert(ctx, campaign); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, model.AuditCampaignCreate, "campaign:"+campaign.ID, nil, campaign)
+	return campaign, nil
+}
+
+func (s *CampaignServiceImpl) Get(ctx *gofr.Context, id string) (*model.Campaign, error) {
+	campaign, err := s.Store.FindByID(ctx, id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrCampaignNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	visible, err := campaignVisible(ctx, s.Workspace, campaign)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, ErrCampaignNotFound
+	}
+	return campaign, nil
+}
+
+// List returns the campaigns of workspace, or the caller's own campaigns when
+// workspace is empty.
+func (s *CampaignServiceImpl) List(ctx *gofr.Context, workspace string) ([]model.Campaign, error) {
+	if workspace != "" {
+		if _, err := s.Workspace.Require(ctx, workspace, model.RoleViewer); err != nil {
+			return nil, err
+		}
+		return s.Store.Find(ctx, "", workspace)
+	}
+	actor := middleware.Actor(ctx)
+	if actor == "" {
+		return nil, ErrActorRequired
+	}
+	return s.Store.Find(ctx, actor, "")
+}
+
+// Analytics aggregates the click counters of the links tagged with the
+// campaign that the caller may view.
+func (s *CampaignServiceImpl) Analytics(ctx *gofr.Context, id string) (*model.CampaignAnalytics, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	links, err := s.URLStore.FindByCampaign(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	result := &model.CampaignAnalytics{
+		CampaignID: id,
+		Links:      make([]model.CampaignLinkStats, 0, len(links)),
+	}
+	for i := range links {
+		link := &links[i]
+		visible, err := s.Workspace.CanView(ctx, link)
+		if err != nil {
+			return nil, err
+		}
+		if !visible {
+			continue
+		}
+		result.TotalLinks++
+		result.TotalClicks += link.ClickCount
+		result.Links = append(result.Links, model.CampaignLinkStats{
+			ShortCode: link.ShortCode,
+			Clicks:    link.ClickCount,
+		})
+	}
+	return result, nil
+}
+
+// campaignVisible reports whether the caller may see campaign and attach
+// links to it: its owner, or for a workspace campaign any member of the
+// workspace.
+func campaignVisible(ctx *gofr.Context, access *WorkspaceAccess, campaign *model.Campaign) (bool, error) {
+	if campaign.Workspace == "" {
+		actor := middleware.Actor(ctx)
+		return actor != "" && campaign.Owner == actor, nil
+	}
+	_, err := access.Require(ctx, campaign.Workspace, model.RoleViewer)
+	if errors.Is(err, ErrWorkspaceNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ApplyUTM adds the campaign's UTM parameters to destination. Parameters
+// already present on the destination are left untouched so hand-tagged
+// links keep their values.
+func ApplyUTM(destination string, utm model.UTMParams) (string, error) {
+	dest, err := url.Parse(destination)
+	if err != nil {
+		return "", err
+	}
+	query := dest.Query()
+	defaults := map[string]string{
+		"utm_source":   utm.Source,
+		"utm_medium":   utm.Medium,
+		"utm_campaign": utm.Campaign,
+		"utm_term":     utm.Term,
+		"utm_content":  utm.Content,
+	}
+	changed := false
+	for key, value := range defaults {
+		if value == "" || query.Has(key) {
+			continue
+		}
+		query.Set(key, value)
+		changed = true
+	}
+	if !changed {
+		return destination, nil
+	}
+	dest.RawQuery = query.Encode()
+	return dest.String(), nil
+}
+
+// CampaignCache keeps recently used campaigns in memory so redirects of
+// campaign links do not load the campaign every time. Campaigns cannot be
+// edited, so entries only expire to bound how long a deleted campaign lingers.
+type CampaignCache struct {
+	Store *store.CampaignStore
+	TTL   time.Duration
+
+	mu        sync.Mutex
+	campaigns map[string]cachedCampaign
+}
+
+type cachedCampaign struct {
+	campaign *model.Campaign
+	fetched  time.Time
+}
+
+func NewCampaignCache(campaigns *store.CampaignStore) *CampaignCache {
+	return &CampaignCache{Store: campaigns, TTL: 5 * time.Minute, campaigns: map[string]cachedCampaign{}}
+}
+
+// FindByID returns the campaign id, loading it when it is not cached.
+func (c *CampaignCache) FindByID(ctx *gofr.Context, id string) (*model.Campaign, error) {
+	c.mu.Lock()
+	cached, ok := c.campaigns[id]
+	c.mu.Unlock()
+	if ok && time.Since(cached.fetched) < c.TTL {
+		return cached.campaign, nil
+	}
+	campaign, err := c.Store.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.campaigns[id] = cachedCampaign{campaign: campaign, fetched: time.Now()}
+	c.mu.Unlock()
+	return campaign, nil
+}
@@ -0,0 +1,251 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+func TestApplyUTM(t *testing.T) {
+	utm := model.UTMParams{Source: "newsletter", Medium: "email", Campaign: "spring"}
+
+	tests := []struct {
+		name        string
+		destination string
+		expected    string
+	}{
+		{
+			name:        "Adds Defaults",
+			destination: "https://example.com/sale",
+			expected:    "https://example.com/sale?utm_campaign=spring&utm_medium=email&utm_source=newsletter",
+		},
+		{
+			name:        "Keeps Hand Tagged Values",
+			destination: "https://example.com/sale?utm_source=partner",
+			expected:    "https://example.com/sale?utm_campaign=spring&utm_medium=email&utm_source=partner",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.ApplyUTM(tt.destination, utm)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestCampaignServiceCreate(t *testing.T) {
+	tests := []struct {
+		name            string
+		actor           string
+		request         *model.CreateCampaignRequest
+		expectedApplyAt string
+		expectedStatus  int
+	}{
+		{
+			name:            "Defaults To Redirect Time",
+			actor:           "alice",
+			request:         &model.CreateCampaignRequest{Name: "spring", UTM: model.UTMParams{Source: "x"}},
+			expectedApplyAt: model.CampaignApplyAtRedirect,
+		},
+		{
+			name:            "Create Time",
+			actor:           "alice",
+			request:         &model.CreateCampaignRequest{Name: "spring", ApplyAt: model.CampaignApplyAtCreate},
+			expectedApplyAt: model.CampaignApplyAtCreate,
+		},
+		{
+			name:           "Missing Name",
+			actor:          "alice",
+			request:        &model.CreateCampaignRequest{},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid Apply At",
+			actor:          "alice",
+			request:        &model.CreateCampaignRequest{Name: "spring", ApplyAt: "never"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Anonymous Caller",
+			request:        &model.CreateCampaignRequest{Name: "spring"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContainer, mocks := container.NewMockContainer(t)
+			campaignService := service.NewCampaignService(store.NewCampaignStore(), store.NewURLStore(), nil, nil)
+
+			if tt.expectedStatus == 0 {
+				mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "campaigns", gomock.Any()).Return("id", nil)
+			}
+
+			ctx := &gofr.Context{Context: actorContext(tt.actor), Container: mockContainer}
+
+			result, err := campaignService.Create(ctx, tt.request)
+			if tt.expectedStatus != 0 {
+				assert.Equal(t, tt.expectedStatus, statusOf(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotEmpty(t, result.ID)
+			assert.Equal(t, tt.actor, result.Owner)
+			assert.Equal(t, tt.expectedApplyAt, result.ApplyAt)
+		})
+	}
+}
+
+func expectCampaign(mocks *container.Mocks, campaign model.Campaign) {
+	mocks.Mongo.EXPECT().FindOne(gomock.Any(), "campaigns", bson.M{"_id": campaign.ID}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, result any) error {
+			*result.(*model.Campaign) = campaign
+			return nil
+		})
+}
+
+func TestCampaignServiceList(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	campaignService := service.NewCampaignService(store.NewCampaignStore(), store.NewURLStore(), nil, nil)
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "campaigns",
+		bson.M{"owner": "alice", "workspace": bson.M{"$exists": false}}, gomock.Any()).Return(nil)
+
+	_, err := campaignService.List(&gofr.Context{Context: actorContext("alice"), Container: mockContainer}, "")
+	assert.NoError(t, err)
+
+	_, err = campaignService.List(&gofr.Context{Context: context.Background(), Container: mockContainer}, "")
+	assert.Equal(t, service.ErrActorRequired, err)
+}
+
+func TestCampaignServiceAnalytics(t *testing.T) {
+	t.Run("Counts Visible Links", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		campaignService := service.NewCampaignService(store.NewCampaignStore(), store.NewURLStore(), nil, nil)
+
+		expectCampaign(mocks, model.Campaign{ID: "c1", Owner: "alice"})
+		mocks.Mongo.EXPECT().Find(gomock.Any(), "urls", bson.M{"campaign_id": "c1"}, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+				*results.(*[]model.URL) = []model.URL{
+					{ShortCode: "a", Owner: "alice", ClickCount: 3},
+					{ShortCode: "b", Owner: "alice", ClickCount: 4},
+					// Another tenant attached before campaigns were scoped.
+					{ShortCode: "c", Owner: "bob", ClickCount: 5},
+				}
+				return nil
+			})
+
+		ctx := &gofr.Context{Context: actorContext("alice"), Container: mockContainer}
+
+		result, err := campaignService.Analytics(ctx, "c1")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.TotalLinks)
+		assert.Equal(t, int64(7), result.TotalClicks)
+		assert.Len(t, result.Links, 2)
+	})
+
+	t.Run("Other Owner", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		campaignService := service.NewCampaignService(store.NewCampaignStore(), store.NewURLStore(), nil, nil)
+		expectCampaign(mocks, model.Campaign{ID: "c1", Owner: "alice"})
+
+		ctx := &gofr.Context{Context: actorContext("bob"), Container: mockContainer}
+
+		_, err := campaignService.Analytics(ctx, "c1")
+		assert.Equal(t, service.ErrCampaignNotFound, err)
+	})
+}
+
+func TestURLServiceCreateWithCampaign(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	urlService := service.NewURLService(store.NewURLStore(), "http://localhost:8000/",
+		service.WithCampaigns(store.NewCampaignStore()))
+
+	expectCampaign(mocks, model.Campaign{
+		ID:      "c1",
+		Owner:   "alice",
+		UTM:     model.UTMParams{Source: "newsletter"},
+		ApplyAt: model.CampaignApplyAtCreate,
+	})
+	mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "urls", gomock.Any()).Return("id", nil)
+
+	ctx := &gofr.Context{Context: actorContext("alice"), Container: mockContainer}
+
+	result, err := urlService.Create(ctx, &model.CreateURLRequest{
+		OriginalURL: "https://example.com/sale",
+		CampaignID:  "c1",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "c1", result.CampaignID)
+	assert.Equal(t, "https://example.com/sale?utm_source=newsletter", result.Original)
+}
+
+func TestURLServiceCreateWithUnknownCampaign(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	urlService := service.NewURLService(store.NewURLStore(), "http://localhost:8000/",
+		service.WithCampaigns(store.NewCampaignStore()))
+
+	mocks.Mongo.EXPECT().FindOne(gomock.Any(), "campaigns", bson.M{"_id": "missing"}, gomock.Any()).
+		Return(mongo.ErrNoDocuments)
+
+	ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+
+	result, err := urlService.Create(ctx, &model.CreateURLRequest{
+		OriginalURL: "https://example.com/sale",
+		CampaignID:  "missing",
+	})
+	assert.Nil(t, result)
+	assert.Equal(t, service.ErrCampaignNotFound, err)
+}
+
+func TestURLServiceCreateWithOtherOwnersCampaign(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	urlService := service.NewURLService(store.NewURLStore(), "http://localhost:8000/",
+		service.WithCampaigns(store.NewCampaignStore()))
+	expectCampaign(mocks, model.Campaign{ID: "c1", Owner: "bob"})
+
+	ctx := &gofr.Context{Context: actorContext("alice"), Container: mockContainer}
+
+	result, err := urlService.Create(ctx, &model.CreateURLRequest{
+		OriginalURL: "https://example.com/sale",
+		CampaignID:  "c1",
+	})
+	assert.Nil(t, result)
+	assert.Equal(t, service.ErrCampaignNotFound, err)
+}
+
+func TestURLServiceResolveCachesCampaign(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	urlService := service.NewURLService(store.NewURLStore(), "http://localhost:8000/",
+		service.WithCampaigns(store.NewCampaignStore()))
+	link := model.URL{ShortCode: "abc123", Original: "https://example.com/sale", CampaignID: "c1"}
+	expectLink(mocks, link)
+	expectLink(mocks, link)
+	mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "urls", bson.M{"short_code": "abc123"}, gomock.Any()).Return(nil).Times(2)
+	mocks.Mongo.EXPECT().FindOne(gomock.Any(), "campaigns", bson.M{"_id": "c1"}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, result any) error {
+			*result.(*model.Campaign) = model.Campaign{ID: "c1", UTM: model.UTMParams{Source: "newsletter"},
+				ApplyAt: model.CampaignApplyAtRedirect}
+			return nil
+		})
+
+	ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+
+	for range 2 {
+		destination, err := urlService.Resolve(ctx, "abc123", "", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://example.com/sale?utm_source=newsletter", destination)
+	}
+}
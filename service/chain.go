@@ -0,0 +1,135 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+var (
+	ErrSelfReference = &apierror.Error{Status: http.StatusBadRequest, Message: "destination is on this shortener but is not a short link"}
+	ErrRedirectLoop  = &apierror.Error{Status: http.StatusBadRequest, Message: "destination leads back to this link"}
+	ErrChainTooDeep  = &apierror.Error{Status: http.StatusBadRequest, Message: "destination chains through too many short links"}
+	ErrChainPath     = &apierror.Error{Status: http.StatusBadRequest,
+		Message: "destination adds a path to a short link that does not pass paths through"}
+)
+
+// DefaultShorteners are well-known URL shortener domains.
+var DefaultShorteners = []string{
+	"bit.ly", "bitly.com", "j.mp", "tinyurl.com", "t.co", "goo.gl", "ow.ly", "is.gd", "v.gd",
+	"buff.ly", "cutt.ly", "rebrand.ly", "tiny.cc", "shorturl.at", "rb.gy", "t.ly", "s.id", "lnkd.in",
+}
+
+// ChainGuard keeps links from pointing back at this shortener in a loop or
+// hiding their destination behind a chain of shorteners. Destinations on
+// Hosts are this shortener's own links and are followed through the stored
+// links; destinations on Shorteners cannot be followed offline and count as
+// the last hop.
+type ChainGuard struct {
+	URLs       *store.URLStore
+	Hosts      map[string]bool
+	Shorteners map[string]bool
+	MaxDepth   int  // shortener hops allowed after a link
+	Resolve    bool // replace a destination that is one of our links with that link's destination
+}
+
+func NewChainGuard(urls *store.URLStore, hosts, shorteners []string, maxDepth int, resolve bool) *ChainGuard {
+	return &ChainGuard{
+		URLs:       urls,
+		Hosts:      hostSet(hosts),
+		Shorteners: hostSet(append(shorteners, DefaultShorteners...)),
+		MaxDepth:   maxDepth,
+		Resolve:    resolve,
+	}
+}
+
+// hostSet collects host names, accepting bare hosts as well as URLs such as
+// SHORT_URL_HOST.
+func hostSet(entries []string) map[string]bool {
+	hosts := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if parsed, err := url.Parse(entry); err == nil && parsed.Hostname() != "" {
+			entry = parsed.Hostname()
+		}
+		hosts[strings.TrimPrefix(entry, "www.")] = true
+	}
+	return hosts
+}
+
+// Check follows destination for the link code ("" for a new link) and
+// returns the destination to store, which differs from destination only when
+// Resolve replaced one of our links by its own destination, and the target
+// visitors finally reach through our links, which is destination itself when
+// it is not one of our links.
+func (g *ChainGuard) Check(ctx *gofr.Context, code, destination string) (stored, target string, err error) {
+	seen := map[string]bool{code: true}
+	current := destination
+	resolved := false
+	for depth := 0; ; depth++ {
+		if depth > g.MaxDepth {
+			return "", "", ErrChainTooDeep
+		}
+		host, next, suffix, query := g.hop(current)
+		switch {
+		case g.Hosts[host]:
+			if next == "" {
+				return "", "", ErrSelfReference
+			}
+			if seen[next] {
+				return "", "", ErrRedirectLoop
+			}
+			seen[next] = true
+			target, err := g.URLs.FindByShortCode(ctx, next)
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return "", "", ErrSelfReference
+			}
+			if err != nil {
+				return "", "", err
+			}
+			// Follow the hop the way a redirect through target would, with
+			// its path and query passthrough.
+			current, err = BuildDestination(target, suffix, query)
+			if errors.Is(err, ErrWildcardDisabled) {
+				return "", "", ErrChainPath
+			}
+			if err != nil {
+				return "", "", err
+			}
+			if g.Resolve && !resolved {
+				// Flattening one level removes this hop from the chain.
+				destination, resolved = current, true
+				depth--
+			}
+		case g.Shorteners[host]:
+			if depth+1 > g.MaxDepth {
+				return "", "", ErrChainTooDeep
+			}
+			return destination, current, nil
+		default:
+			return destination, current, nil
+		}
+	}
+}
+
+// hop returns the host of destination and, for our own hosts, the short
+// code it redirects through with the path suffix and query it passes on.
+func (g *ChainGuard) hop(destination string) (host, code, suffix string, query url.Values) {
+	parsed, err := url.Parse(destination)
+	if err != nil {
+		return "", "", "", nil
+	}
+	host = strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	code, suffix, _ = strings.Cut(strings.TrimPrefix(parsed.Path, "/"), "/")
+	return host, code, suffix, parsed.Query()
+}
@@ -0,0 +1,109 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+func TestChainGuardCheck(t *testing.T) {
+	// Stored links: hop1 -> hop2 -> example.com, back -> abc123, short -> bit.ly,
+	// docs -> example.com passing paths and queries through.
+	links := map[string]model.URL{
+		"hop1":   {Original: "https://sho.rt/hop2"},
+		"hop2":   {Original: "https://example.com/final"},
+		"back":   {Original: "https://sho.rt/abc123"},
+		"short":  {Original: "https://bit.ly/xyz"},
+		"abc123": {Original: "https://example.com/current"},
+		"docs":   {Original: "https://example.com/docs?lang=en", Wildcard: true, ForwardQuery: true},
+	}
+
+	tests := []struct {
+		name        string
+		code        string
+		destination string
+		maxDepth    int
+		resolve     bool
+		expected    string
+		target      string
+		expectedErr error
+	}{
+		{name: "Plain Destination", destination: "https://example.com/", maxDepth: 1, expected: "https://example.com/"},
+		{name: "Own Host Without Link", destination: "https://sho.rt/", maxDepth: 1, expectedErr: service.ErrSelfReference},
+		{name: "Own Host Unknown Code", destination: "https://www.SHO.rt/nope", maxDepth: 1, expectedErr: service.ErrSelfReference},
+		{name: "Custom Domain Self Reference", destination: "https://go.example.org/abc123", code: "abc123", maxDepth: 3,
+			expectedErr: service.ErrRedirectLoop},
+		{name: "Loop Through Another Link", destination: "https://sho.rt/back", code: "abc123", maxDepth: 3,
+			expectedErr: service.ErrRedirectLoop},
+		{name: "One Hop Allowed", destination: "https://sho.rt/hop2", maxDepth: 1, expected: "https://sho.rt/hop2",
+			target: "https://example.com/final"},
+		{name: "Two Hops Rejected", destination: "https://sho.rt/hop1", maxDepth: 1, expectedErr: service.ErrChainTooDeep},
+		{name: "Two Hops Within Cap", destination: "https://sho.rt/hop1", maxDepth: 2, expected: "https://sho.rt/hop1",
+			target: "https://example.com/final"},
+		{name: "Known Shortener", destination: "https://bit.ly/xyz", maxDepth: 1, expected: "https://bit.ly/xyz"},
+		{name: "Known Shortener Without Hops", destination: "https://bit.ly/xyz", maxDepth: 0, expectedErr: service.ErrChainTooDeep},
+		{name: "Configured Shortener", destination: "https://sl.example.net/abc", maxDepth: 0, expectedErr: service.ErrChainTooDeep},
+		{name: "Own Link To Shortener", destination: "https://sho.rt/short", maxDepth: 1, expectedErr: service.ErrChainTooDeep},
+		{name: "Resolve One Level", destination: "https://sho.rt/hop2", resolve: true, expected: "https://example.com/final"},
+		{name: "Resolve Keeps Remaining Hops", destination: "https://sho.rt/hop1", maxDepth: 1, resolve: true, expected: "https://sho.rt/hop2"},
+		{name: "Resolve Still Caps Depth", destination: "https://sho.rt/short", resolve: true, expectedErr: service.ErrChainTooDeep},
+		{name: "Resolve Passes Path And Query", destination: "https://sho.rt/docs/guide/start?ref=mail", resolve: true,
+			expected: "https://example.com/docs/guide/start?lang=en&ref=mail"},
+		{name: "Resolve Drops Query Not Forwarded", destination: "https://sho.rt/hop2?ref=mail", resolve: true,
+			expected: "https://example.com/final"},
+		{name: "Path On Link Without Passthrough", destination: "https://sho.rt/hop2/extra", maxDepth: 1,
+			expectedErr: service.ErrChainPath},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContainer, mocks := container.NewMockContainer(t)
+			mocks.Mongo.EXPECT().FindOne(gomock.Any(), "urls", gomock.Any(), gomock.Any()).AnyTimes().
+				DoAndReturn(func(_ context.Context, _ string, filter any, result any) error {
+					code := filter.(bson.M)["short_code"].(string)
+					link, ok := links[code]
+					if !ok {
+						return mongo.ErrNoDocuments
+					}
+					link.ShortCode = code
+					*result.(*model.URL) = link
+					return nil
+				})
+
+			guard := service.NewChainGuard(store.NewURLStore(), []string{"http://sho.rt/", "go.example.org"},
+				[]string{"sl.example.net"}, tt.maxDepth, tt.resolve)
+			destination, target, err := guard.Check(&gofr.Context{Context: context.Background(), Container: mockContainer},
+				tt.code, tt.destination)
+
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expected, destination)
+			if tt.target != "" {
+				assert.Equal(t, tt.target, target)
+			}
+		})
+	}
+}
+
+func TestURLServiceUpdateRejectsLoop(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	expectLink(mocks, model.URL{ShortCode: "abc123", Owner: "alice", Original: "https://example.com", Revision: 1})
+	expectLink(mocks, model.URL{ShortCode: "back", Original: "https://sho.rt/abc123"})
+
+	svc := service.NewURLService(store.NewURLStore(), "https://sho.rt/",
+		service.WithChainGuard(service.NewChainGuard(store.NewURLStore(), []string{"https://sho.rt/"}, nil, 1, false)))
+	destination := "https://sho.rt/back"
+	_, err := svc.Update(&gofr.Context{Context: actorContext("alice"), Container: mockContainer}, "abc123",
+		&model.UpdateURLRequest{OriginalURL: &destination})
+
+	assert.Equal(t, service.ErrRedirectLoop, err)
+}
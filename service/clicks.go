@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+// Metrics exported by the click pipeline.
+const (
+	ClickQueueDepthMetric = "click_queue_depth"
+	ClicksWrittenMetric   = "clicks_written_total"
+	ClicksDroppedMetric   = "clicks_dropped_total"
+)
+
+// ClickIngester buffers clicks in a bounded in-process queue and writes them
+// in batches on background workers, so redirects never wait on the database.
+// Clicks arriving while the queue is full are dropped and counted.
+type ClickIngester struct {
+	Clicks        *store.ClickStore
+	URLs          *store.URLStore
+	Rollups       *store.RollupStore
+	BatchSize     int
+	FlushInterval time.Duration
+
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan clickJob
+	workers sync.WaitGroup
+	dropped atomic.Int64
+}
+
+// clickJob carries the app container because batches are written outside any request.
+type clickJob struct {
+	container *container.Container
+	click     model.Click
+}
+
+func NewClickIngester(clicks *store.ClickStore, urls *store.URLStore, rollups *store.RollupStore, queueSize int) *ClickIngester {
+	return &ClickIngester{
+		Clicks:        clicks,
+		URLs:          urls,
+		Rollups:       rollups,
+		BatchSize:     100,
+		FlushInterval: time.Second,
+		queue:         make(chan clickJob, queueSize),
+	}
+}
+
+// RegisterMetrics registers the pipeline's metrics with the app.
+func (i *ClickIngester) RegisterMetrics(metrics container.Metrics) {
+	metrics.NewGauge(ClickQueueDepthMetric, "Clicks waiting to be written")
+	metrics.NewCounter(ClicksWrittenMetric, "Clicks written to the database")
+	metrics.NewCounter(ClicksDroppedMetric, "Clicks dropped because the queue was full or the write failed")
+}
+
+// Start launches the batching workers.
+func (i *ClickIngester) Start(workers int) {
+	for range workers {
+		i.workers.Add(1)
+		go func() {
+			defer i.workers.Done()
+			i.work()
+		}()
+	}
+}
+
+// Stop stops accepting clicks and waits until the workers have flushed
+// everything still queued.
+func (i *ClickIngester) Stop() {
+	i.mu.Lock()
+	if !i.closed {
+		i.closed = true
+		close(i.queue)
+	}
+	i.mu.Unlock()
+	i.workers.Wait()
+}
+
+// Record queues click without blocking.
+func (i *ClickIngester) Record(ctx *gofr.Context, click model.Click) {
+	i.mu.RLock()
+	accepted := false
+	if !i.closed {
+		select {
+		case i.queue <- clickJob{container: ctx.Container, click: click}:
+			accepted = true
+		default:
+		}
+	}
+	i.mu.RUnlock()
+
+	if !accepted {
+		i.dropped.Add(1)
+		ctx.Metrics().IncrementCounter(ctx, ClicksDroppedMetric, "reason", "overflow")
+		return
+	}
+	ctx.Metrics().SetGauge(ClickQueueDepthMetric, float64(len(i.queue)))
+}
+
+// Dropped returns how many clicks were lost since the ingester was created.
+func (i *ClickIngester) Dropped() int64 {
+	return i.dropped.Load()
+}
+
+func (i *ClickIngester) work() {
+	ticker := time.NewTicker(i.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]clickJob, 0, i.BatchSize)
+	for {
+		select {
+		case job, ok := <-i.queue:
+			if !ok {
+				i.flush(batch)
+				return
+			}
+			batch = append(batch, job)
+			if len(batch) >= i.BatchSize {
+				i.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			i.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+type clickCounts struct {
+	people, bots int64
+}
+
+// flush writes a batch of clicks and adds them to the links' click counts and
+// analytics rollups.
+func (i *ClickIngester) flush(batch []clickJob) {
+	if len(batch) == 0 {
+		return
+	}
+	ctx := &gofr.Context{Context: context.Background(), Container: batch[0].container}
+	defer ctx.Metrics().SetGauge(ClickQueueDepthMetric, float64(len(i.queue)))
+
+	clicks := make([]model.Click, len(batch))
+	counts := make(map[string]*clickCounts)
+	for n, job := range batch {
+		clicks[n] = job.click
+		count, ok := counts[job.click.ShortCode]
+		if !ok {
+			count = &clickCounts{}
+			counts[job.click.ShortCode] = count
+		}
+		if job.click.Bot != "" {
+			count.bots++
+		} else {
+			count.people++
+		}
+	}
+
+	if err := i.Clicks.InsertMany(ctx, clicks); err != nil {
+		ctx.Logger.Errorf("writing %d clicks: %v", len(clicks), err)
+		i.dropped.Add(int64(len(clicks)))
+		for range clicks {
+			ctx.Metrics().IncrementCounter(ctx, ClicksDroppedMetric, "reason", "write_error")
+		}
+	} else {
+		for range clicks {
+			ctx.Metrics().IncrementCounter(ctx, ClicksWrittenMetric)
+		}
+	}
+
+	// Counts and rollups are kept even when the raw clicks could not be written.
+	for code, count := range counts {
+		if err := i.URLs.IncrementClicks(ctx, code, count.people, count.bots); err != nil {
+			ctx.Logger.Errorf("counting clicks for %s: %v", code, err)
+		}
+	}
+	if i.Rollups == nil {
+		return
+	}
+	for _, delta := range aggregateClicks(clicks) {
+		if err := i.Rollups.Add(ctx, delta); err != nil {
+			ctx.Logger.Errorf("updating rollup %s: %v", delta.ID, err)
+		}
+	}
+}
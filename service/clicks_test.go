@@ -0,0 +1,82 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+func newIngester(queueSize, batchSize int) *service.ClickIngester {
+	ingester := service.NewClickIngester(store.NewClickStore(), store.NewURLStore(), nil, queueSize)
+	ingester.BatchSize = batchSize
+	ingester.FlushInterval = time.Hour
+	return ingester
+}
+
+func TestClickIngesterBatches(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	mocks.Metrics.EXPECT().SetGauge(service.ClickQueueDepthMetric, gomock.Any()).AnyTimes()
+	mocks.Metrics.EXPECT().IncrementCounter(gomock.Any(), service.ClicksWrittenMetric).Times(3)
+
+	var batches [][]model.Click
+	mocks.Mongo.EXPECT().InsertMany(gomock.Any(), "clicks", gomock.Any()).Times(2).
+		DoAndReturn(func(_ context.Context, _ string, documents []any) ([]any, error) {
+			batch := make([]model.Click, len(documents))
+			for i, document := range documents {
+				batch[i] = document.(model.Click)
+			}
+			batches = append(batches, batch)
+			return nil, nil
+		})
+	mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "urls", bson.M{"short_code": "abc123"},
+		bson.M{"$inc": bson.M{"click_count": int64(1), "bot_click_count": int64(1)}}).Return(nil)
+	mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "urls", bson.M{"short_code": "xyz789"},
+		bson.M{"$inc": bson.M{"click_count": int64(1), "bot_click_count": int64(0)}}).Return(nil)
+
+	ingester := newIngester(10, 2)
+	ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+	ingester.Record(ctx, model.Click{ShortCode: "abc123"})
+	ingester.Record(ctx, model.Click{ShortCode: "abc123", Bot: "Slackbot"})
+	ingester.Record(ctx, model.Click{ShortCode: "xyz789"})
+	ingester.Start(1)
+	// The third click only reaches the database through the shutdown flush.
+	ingester.Stop()
+
+	assert.Len(t, batches, 2)
+	assert.Len(t, batches[0], 2)
+	assert.Len(t, batches[1], 1)
+	assert.NotEmpty(t, batches[0][0].ID)
+	assert.Zero(t, ingester.Dropped())
+}
+
+func TestClickIngesterOverflow(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	mocks.Metrics.EXPECT().SetGauge(service.ClickQueueDepthMetric, gomock.Any()).AnyTimes()
+	mocks.Metrics.EXPECT().IncrementCounter(gomock.Any(), service.ClicksDroppedMetric, "reason", "overflow").Times(2)
+	mocks.Metrics.EXPECT().IncrementCounter(gomock.Any(), service.ClicksDroppedMetric, "reason", "write_error")
+	mocks.Mongo.EXPECT().InsertMany(gomock.Any(), "clicks", gomock.Any()).Return(nil, errors.New("mongo down"))
+	mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "urls", gomock.Any(), gomock.Any()).Return(nil)
+
+	ingester := newIngester(1, 10)
+	ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+	ingester.Record(ctx, model.Click{ShortCode: "abc123"})
+	ingester.Record(ctx, model.Click{ShortCode: "abc123"})
+	ingester.Start(1)
+	ingester.Stop()
+
+	// Clicks recorded after shutdown are dropped too.
+	ingester.Record(ctx, model.Click{ShortCode: "abc123"})
+
+	assert.Equal(t, int64(3), ingester.Dropped())
+}
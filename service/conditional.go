@@ -0,0 +1,81 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+var (
+	ErrNotModified          = &apierror.Error{Status: http.StatusNotModified, Message: "not modified"}
+	ErrPreconditionFailed   = &apierror.Error{Status: http.StatusPreconditionFailed, Message: "link was changed since it was read, fetch it again and retry"}
+	ErrPreconditionRequired = &apierror.Error{Status: http.StatusPreconditionRequired, Message: "send the link's ETag in If-Match to change it"}
+)
+
+// ETag returns the entity tag of the link's current version. It changes with
+// every edit to the link, but not with its click counts or screening.
+func ETag(link *model.URL) string {
+	return `"` + strconv.Itoa(link.Revision) + "-" + strconv.FormatInt(LastModified(link).UnixMilli(), 36) + `"`
+}
+
+// LastModified returns when the link was last edited.
+func LastModified(link *model.URL) time.Time {
+	if link.UpdatedAt.IsZero() {
+		return link.CreatedAt
+	}
+	return link.UpdatedAt
+}
+
+// NotModified reports whether a conditional GET of link can be answered with
+// 304. If-None-Match takes precedence over If-Modified-Since.
+func NotModified(header http.Header, link *model.URL) bool {
+	if match := header.Get("If-None-Match"); match != "" {
+		// GET compares entity tags weakly.
+		return matchesETag(match, link, true)
+	}
+	since, err := http.ParseTime(header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !LastModified(link).Truncate(time.Second).After(since)
+}
+
+// checkIfMatch refuses to change link when the request's If-Match names
+// another version. Requests without If-Match are not checked; the handlers
+// insist on it for clients.
+func checkIfMatch(ctx *gofr.Context, link *model.URL) error {
+	match := middleware.GetRequestMeta(ctx).Header.Get("If-Match")
+	if match == "" || matchesETag(match, link, false) {
+		return nil
+	}
+	return ErrPreconditionFailed
+}
+
+// conflict is returned when a link changed between reading and writing it.
+func conflict(ctx *gofr.Context) error {
+	if middleware.GetRequestMeta(ctx).Header.Get("If-Match") != "" {
+		return ErrPreconditionFailed
+	}
+	return ErrRevisionConflict
+}
+
+func matchesETag(list string, link *model.URL, weak bool) bool {
+	current := ETag(link)
+	for _, tag := range strings.Split(list, ",") {
+		tag = strings.TrimSpace(tag)
+		if weak {
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == "*" || tag == current {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+var versionedLink = model.URL{
+	ShortCode: "abc123",
+	Owner:     "alice",
+	Original:  "https://example.com",
+	Revision:  2,
+	CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	UpdatedAt: time.Date(2024, 5, 2, 9, 30, 15, 250e6, time.UTC),
+}
+
+func ifMatchContext(actor, etag string) context.Context {
+	ctx := actorContext(actor)
+	middleware.GetRequestMeta(ctx).Header.Set("If-Match", etag)
+	return ctx
+}
+
+func TestETag(t *testing.T) {
+	link := versionedLink
+	etag := service.ETag(&link)
+
+	link.Tags = []string{"launch"}
+	assert.Equal(t, etag, service.ETag(&link), "only edits change the version")
+	link.ClickCount = 10
+	assert.Equal(t, etag, service.ETag(&link), "clicks do not change the version")
+
+	link.UpdatedAt = link.UpdatedAt.Add(time.Millisecond)
+	assert.NotEqual(t, etag, service.ETag(&link))
+
+	link.UpdatedAt = time.Time{}
+	assert.Equal(t, link.CreatedAt, service.LastModified(&link), "links from before updated_at was kept")
+}
+
+func TestNotModified(t *testing.T) {
+	etag := service.ETag(&versionedLink)
+	tests := []struct {
+		name   string
+		header http.Header
+		want   bool
+	}{
+		{name: "Unconditional", header: http.Header{}},
+		{name: "Matching ETag", header: http.Header{"If-None-Match": {etag}}, want: true},
+		{name: "Weak ETag In List", header: http.Header{"If-None-Match": {`"1-x", W/` + etag}}, want: true},
+		{name: "Stale ETag", header: http.Header{"If-None-Match": {`"1-x"`}}},
+		{name: "Any", header: http.Header{"If-None-Match": {"*"}}, want: true},
+		{name: "Not Modified Since", header: http.Header{"If-Modified-Since": {"Thu, 02 May 2024 09:30:15 GMT"}}, want: true},
+		{name: "Modified Since", header: http.Header{"If-Modified-Since": {"Thu, 02 May 2024 09:30:14 GMT"}}},
+		{name: "ETag Wins Over Date", header: http.Header{
+			"If-None-Match":     {`"1-x"`},
+			"If-Modified-Since": {"Thu, 02 May 2024 09:30:15 GMT"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, service.NotModified(tt.header, &versionedLink))
+		})
+	}
+}
+
+func TestURLServiceUpdateIfMatch(t *testing.T) {
+	destination := "https://example.com/new"
+	req := &model.UpdateURLRequest{OriginalURL: &destination}
+
+	t.Run("Stale", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectLink(mocks, versionedLink)
+		svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/")
+
+		_, err := svc.Update(&gofr.Context{Context: ifMatchContext("alice", `"1-x"`), Container: mockContainer}, "abc123", req)
+
+		assert.Equal(t, service.ErrPreconditionFailed, err)
+	})
+
+	t.Run("Current", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectLink(mocks, versionedLink)
+		mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "urls",
+			bson.M{"owner": "alice", "short_code": "abc123", "updated_at": versionedLink.UpdatedAt, "revision": 2}, gomock.Any()).Return(int64(1), nil)
+		svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/")
+		etag := service.ETag(&versionedLink)
+
+		updated, err := svc.Update(&gofr.Context{Context: ifMatchContext("alice", etag), Container: mockContainer}, "abc123", req)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, 3, updated.Revision)
+			assert.True(t, updated.UpdatedAt.After(versionedLink.UpdatedAt))
+			assert.NotEqual(t, etag, service.ETag(updated))
+		}
+	})
+}
+
+func TestURLServiceSetTagsIfMatch(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	expectLink(mocks, versionedLink)
+	svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/")
+	ctx := &gofr.Context{Context: ifMatchContext("alice", service.ETag(&versionedLink)), Container: mockContainer}
+
+	// Another editor changes the link between reading and writing it.
+	mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "urls",
+		bson.M{"owner": "alice", "short_code": "abc123", "updated_at": versionedLink.UpdatedAt}, gomock.Any()).
+		Return(int64(0), nil)
+
+	_, err := svc.SetTags(ctx, "abc123", &model.SetTagsRequest{Tags: []string{"launch"}})
+
+	assert.Equal(t, service.ErrPreconditionFailed, err)
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+// ErrWildcardDisabled answers paths under a link that does not pass them
+// through, as if no such short URL existed.
+var ErrWildcardDisabled = &apierror.Error{Status: http.StatusNotFound, Message: "path passthrough is not enabled for this link"}
+
+// BuildDestination applies the link's passthrough options to its original URL.
+// suffix is the part of the request path after the short code and incoming
+// holds the query parameters of the redirect request.
+func BuildDestination(link *model.URL, suffix string, incoming url.Values) (string, error) {
+	dest, err := url.Parse(link.Original)
+	if err != nil {
+		return "", err
+	}
+
+	if suffix != "" {
+		if !link.Wildcard {
+			return "", ErrWildcardDisabled
+		}
+		// Cleaning against a rooted path keeps "../" segments from escaping
+		// the destination's base path. Clean also drops a trailing slash,
+		// which destinations may treat as a different resource.
+		cleaned := strings.TrimPrefix(path.Clean("/"+suffix), "/")
+		if cleaned != "" && strings.HasSuffix(suffix, "/") {
+			cleaned += "/"
+		}
+		if cleaned != "" {
+			dest.Path = strings.TrimSuffix(dest.Path, "/") + "/" + cleaned
+			dest.RawPath = ""
+		}
+	}
+
+	if link.ForwardQuery && len(incoming) > 0 {
+		query := dest.Query()
+		mergeQuery(query, incoming, link.QueryConflict)
+		dest.RawQuery = query.Encode()
+	}
+
+	return dest.String(), nil
+}
+
+func mergeQuery(dst, incoming url.Values, mode string) {
+	for key, values := range incoming {
+		_, exists := dst[key]
+		switch {
+		case !exists:
+			dst[key] = append([]string(nil), values...)
+		case mode == model.QueryConflictOverride:
+			dst[key] = append([]string(nil), values...)
+		case mode == model.QueryConflictAppend:
+			dst[key] = append(dst[key], values...)
+		}
+	}
+}
+
+func validQueryConflict(mode string) bool {
+	switch mode {
+	case "", model.QueryConflictPreserve, model.QueryConflictOverride, model.QueryConflictAppend:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package service_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+)
+
+func TestBuildDestination(t *testing.T) {
+	tests := []struct {
+		name        string
+		link        *model.URL
+		suffix      string
+		query       url.Values
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "Plain Link Drops Query",
+			link:     &model.URL{Original: "https://example.com/page"},
+			query:    url.Values{"ref": {"tw"}},
+			expected: "https://example.com/page",
+		},
+		{
+			name:     "Forward Query Without Conflicts",
+			link:     &model.URL{Original: "https://example.com/page", ForwardQuery: true},
+			query:    url.Values{"ref": {"tw"}},
+			expected: "https://example.com/page?ref=tw",
+		},
+		{
+			name: "Conflict Preserves Destination By Default",
+			link: &model.URL{Original: "https://example.com/page?ref=site", ForwardQuery: true},
+			query: url.Values{
+				"ref":  {"tw"},
+				"page": {"2"},
+			},
+			expected: "https://example.com/page?page=2&ref=site",
+		},
+		{
+			name: "Conflict Override",
+			link: &model.URL{
+				Original:      "https://example.com/page?ref=site",
+				ForwardQuery:  true,
+				QueryConflict: model.QueryConflictOverride,
+			},
+			query:    url.Values{"ref": {"tw"}},
+			expected: "https://example.com/page?ref=tw",
+		},
+		{
+			name: "Conflict Append",
+			link: &model.URL{
+				Original:      "https://example.com/page?ref=site",
+				ForwardQuery:  true,
+				QueryConflict: model.QueryConflictAppend,
+			},
+			query:    url.Values{"ref": {"tw"}},
+			expected: "https://example.com/page?ref=site&ref=tw",
+		},
+		{
+			name:     "Wildcard Appends Path",
+			link:     &model.URL{Original: "https://docs.example.com/v2/", Wildcard: true},
+			suffix:   "guide/intro",
+			expected: "https://docs.example.com/v2/guide/intro",
+		},
+		{
+			name:     "Wildcard Keeps Trailing Slash",
+			link:     &model.URL{Original: "https://docs.example.com/v2", Wildcard: true},
+			suffix:   "guide/",
+			expected: "https://docs.example.com/v2/guide/",
+		},
+		{
+			name:     "Wildcard Cannot Escape Base Path",
+			link:     &model.URL{Original: "https://docs.example.com/v2", Wildcard: true},
+			suffix:   "../../admin",
+			expected: "https://docs.example.com/v2/admin",
+		},
+		{
+			name:        "Suffix On Non Wildcard Link",
+			link:        &model.URL{Original: "https://example.com/page"},
+			suffix:      "extra",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest, err := service.BuildDestination(tt.link, tt.suffix, tt.query)
+			if tt.expectError {
+				assert.ErrorIs(t, err, service.ErrWildcardDisabled)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, dest)
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+// Headers sent with every webhook delivery. The signature is the hex HMAC-SHA256
+// of "<timestamp>.<body>" keyed with the webhook secret, prefixed by "sha256=".
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+const maxBackoff = time.Hour
+
+// claimLease is how long an attempt may take before another instance may
+// retry the delivery, e.g. because the one attempting it crashed.
+const claimLease = 2 * time.Minute
+
+// WebhookDispatcher delivers link events to subscribed webhooks on background
+// workers. Failed deliveries are retried with exponential backoff; the time
+// of the next attempt is stored with the delivery and RetryDue picks it up,
+// so retries survive restarts.
+type WebhookDispatcher struct {
+	Store       *store.WebhookStore
+	Client      *http.Client
+	MaxAttempts int
+	BaseBackoff time.Duration
+
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan dispatchJob
+	workers sync.WaitGroup
+}
+
+// dispatchJob is either an event to fan out or a single delivery to attempt.
+// Jobs carry the app container because they run outside any request.
+type dispatchJob struct {
+	container *container.Container
+	event     *model.Event
+	webhook   *model.Webhook
+	delivery  *model.WebhookDelivery
+}
+
+func NewWebhookDispatcher(store *store.WebhookStore, queueSize int) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		Store:       store,
+		Client:      newOutboundClient(10 * time.Second),
+		MaxAttempts: 5,
+		BaseBackoff: time.Minute,
+		queue:       make(chan dispatchJob, queueSize),
+	}
+}
+
+// Start launches the delivery workers.
+func (d *WebhookDispatcher) Start(workers int) {
+	for range workers {
+		d.workers.Add(1)
+		go func() {
+			defer d.workers.Done()
+			for job := range d.queue {
+				d.run(job)
+			}
+		}()
+	}
+}
+
+// Stop stops accepting events and waits until the workers have finished
+// what is queued. Deliveries still pending are retried by RetryDue.
+func (d *WebhookDispatcher) Stop() {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+	d.workers.Wait()
+}
+
+// Notify queues event for fan-out to the owner's subscribed webhooks.
+func (d *WebhookDispatcher) Notify(ctx *gofr.Context, event model.Event) {
+	if event.Owner == "" {
+		// Links made without signing in have no owner who could subscribe.
+		return
+	}
+	if !d.enqueue(dispatchJob{container: ctx.Container, event: &event}) {
+		ctx.Logger.Errorf("webhook queue full, dropping event %s %s", event.Type, event.ID)
+	}
+}
+
+// Redeliver resets a delivery and queues a fresh series of attempts. When
+// the queue is full the delivery is left to RetryDue.
+func (d *WebhookDispatcher) Redeliver(ctx *gofr.Context, webhook *model.Webhook, delivery *model.WebhookDelivery) error {
+	delivery.Status = model.DeliveryPending
+	delivery.Attempts = 0
+	delivery.LastError = ""
+	// Stored times have millisecond precision, and the claim compares them.
+	delivery.NextAttemptAt = time.Now().UTC().Truncate(time.Millisecond)
+	if err := d.Store.UpdateDelivery(ctx, delivery); err != nil {
+		return err
+	}
+	d.enqueue(dispatchJob{container: ctx.Container, webhook: webhook, delivery: delivery})
+	return nil
+}
+
+// RetryDue queues every pending delivery whose next attempt is due. It runs
+// as a cron job on every instance; each attempt is claimed first, so a
+// delivery is attempted by one instance only.
+func (d *WebhookDispatcher) RetryDue(ctx *gofr.Context) {
+	deliveries, err := d.Store.FindDueDeliveries(ctx, time.Now().UTC())
+	if err != nil {
+		ctx.Logger.Errorf("loading due webhook deliveries: %v", err)
+		return
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+	ids := make([]string, 0, len(deliveries))
+	for i := range deliveries {
+		ids = append(ids, deliveries[i].WebhookID)
+	}
+	webhooks, err := d.Store.FindByIDs(ctx, ids)
+	if err != nil {
+		ctx.Logger.Errorf("loading webhooks of due deliveries: %v", err)
+		return
+	}
+	byID := make(map[string]*model.Webhook, len(webhooks))
+	for i := range webhooks {
+		byID[webhooks[i].ID] = &webhooks[i]
+	}
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		webhook, ok := byID[delivery.WebhookID]
+		if !ok || !webhook.Active {
+			delivery.Status = model.DeliveryFailed
+			delivery.LastError = "webhook was deleted or deactivated"
+			delivery.NextAttemptAt = time.Time{}
+			if err := d.Store.UpdateDelivery(ctx, delivery); err != nil {
+				ctx.Logger.Errorf("updating delivery %s: %v", delivery.ID, err)
+			}
+			continue
+		}
+		if !d.enqueue(dispatchJob{container: ctx.Container, webhook: webhook, delivery: delivery}) {
+			// The rest stays due for the next run.
+			return
+		}
+	}
+}
+
+func (d *WebhookDispatcher) enqueue(job dispatchJob) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return false
+	}
+	select {
+	case d.queue <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+func (d *WebhookDispatcher) run(job dispatchJob) {
+	ctx := &gofr.Context{Context: context.Background(), Container: job.container}
+	if job.event != nil {
+		d.fanOut(ctx, job.event)
+		return
+	}
+	claimed, err := d.Store.ClaimDelivery(ctx, job.delivery, time.Now().UTC().Add(claimLease).Truncate(time.Millisecond))
+	if err != nil {
+		ctx.Logger.Errorf("claiming delivery %s: %v", job.delivery.ID, err)
+		return
+	}
+	if claimed {
+		d.Deliver(ctx, job.webhook, job.delivery)
+	}
+}
+
+func (d *WebhookDispatcher) fanOut(ctx *gofr.Context, event *model.Event) {
+	webhooks, err := d.Store.FindSubscribed(ctx, event.Owner, event.Type)
+	if err != nil {
+		ctx.Logger.Errorf("loading webhooks for %s: %v", event.Type, err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		ctx.Logger.Errorf("encoding event %s: %v", event.ID, err)
+		return
+	}
+	for i := range webhooks {
+		delivery := &model.WebhookDelivery{
+			WebhookID: webhooks[i].ID,
+			EventID:   event.ID,
+			EventType: event.Type,
+			Payload:   string(payload),
+			Status:    model.DeliveryPending,
+			// Attempted right away; RetryDue takes over if that never finishes.
+			NextAttemptAt: time.Now().UTC().Add(claimLease),
+		}
+		if err := d.Store.InsertDelivery(ctx, delivery); err != nil {
+			ctx.Logger.Errorf("logging delivery of %s to webhook %s: %v", event.ID, webhooks[i].ID, err)
+			continue
+		}
+		d.Deliver(ctx, &webhooks[i], delivery)
+	}
+}
+
+// Deliver makes one attempt to post delivery to webhook, records the outcome
+// and sets the time of the next attempt when attempts remain.
+func (d *WebhookDispatcher) Deliver(ctx *gofr.Context, webhook *model.Webhook, delivery *model.WebhookDelivery) {
+	delivery.Attempts++
+	statusCode, err := d.post(ctx, webhook, delivery)
+	delivery.LastStatusCode = statusCode
+	delivery.NextAttemptAt = time.Time{}
+
+	switch {
+	case err == nil:
+		delivery.Status = model.DeliverySucceeded
+		delivery.LastError = ""
+	case delivery.Attempts >= d.MaxAttempts:
+		delivery.Status = model.DeliveryFailed
+		delivery.LastError = err.Error()
+	default:
+		delivery.Status = model.DeliveryPending
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = time.Now().UTC().Add(d.backoff(delivery.Attempts))
+	}
+
+	if err := d.Store.UpdateDelivery(ctx, delivery); err != nil {
+		ctx.Logger.Errorf("updating delivery %s: %v", delivery.ID, err)
+	}
+}
+
+func (d *WebhookDispatcher) post(ctx *gofr.Context, webhook *model.Webhook, delivery *model.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignPayload(webhook.Secret, timestamp, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff doubles the wait after every failed attempt, capped at maxBackoff.
+func (d *WebhookDispatcher) backoff(attempts int) time.Duration {
+	wait := d.BaseBackoff << (attempts - 1)
+	if wait <= 0 || wait > maxBackoff {
+		return maxBackoff
+	}
+	return wait
+}
+
+// SignPayload returns the value of the X-Webhook-Signature header.
+func SignPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,204 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+// newReceiver starts a local webhook receiver answering with status.
+func newReceiver(t *testing.T, status int) (*httptest.Server, chan receivedWebhook) {
+	received := make(chan receivedWebhook, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- receivedWebhook{header: r.Header.Clone(), body: body}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func TestSignPayload(t *testing.T) {
+	// echo -n '1700000000.{}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t,
+		"sha256=b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163",
+		service.SignPayload("secret", 1700000000, []byte("{}")))
+}
+
+func TestWebhookDispatcherDeliver(t *testing.T) {
+	tests := []struct {
+		name           string
+		receiverStatus int
+		attempts       int
+		expectedStatus string
+	}{
+		{name: "Success", receiverStatus: http.StatusOK, expectedStatus: model.DeliverySucceeded},
+		{name: "Failure - Retry Scheduled", receiverStatus: http.StatusInternalServerError, expectedStatus: model.DeliveryPending},
+		{
+			name:           "Failure - Attempts Exhausted",
+			receiverStatus: http.StatusInternalServerError,
+			attempts:       4,
+			expectedStatus: model.DeliveryFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, received := newReceiver(t, tt.receiverStatus)
+			mockContainer, mocks := container.NewMockContainer(t)
+
+			dispatcher := service.NewWebhookDispatcher(store.NewWebhookStore(), 10)
+			dispatcher.Client = server.Client()
+			dispatcher.BaseBackoff = time.Hour
+
+			mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "webhook_deliveries", bson.M{"_id": "d1"}, gomock.Any()).
+				Return(nil)
+
+			webhook := &model.Webhook{ID: "w1", URL: server.URL, Secret: "secret"}
+			delivery := &model.WebhookDelivery{
+				ID:        "d1",
+				WebhookID: "w1",
+				EventType: model.EventLinkCreated,
+				Payload:   `{"type":"link.created"}`,
+				Attempts:  tt.attempts,
+			}
+			ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+
+			dispatcher.Deliver(ctx, webhook, delivery)
+
+			got := <-received
+			timestamp, err := strconv.ParseInt(got.header.Get(service.WebhookTimestampHeader), 10, 64)
+			assert.NoError(t, err)
+			assert.Equal(t, service.SignPayload("secret", timestamp, got.body), got.header.Get(service.WebhookSignatureHeader))
+			assert.Equal(t, model.EventLinkCreated, got.header.Get(service.WebhookEventHeader))
+			assert.Equal(t, "d1", got.header.Get(service.WebhookDeliveryHeader))
+
+			assert.Equal(t, tt.expectedStatus, delivery.Status)
+			assert.Equal(t, tt.attempts+1, delivery.Attempts)
+			assert.Equal(t, tt.receiverStatus, delivery.LastStatusCode)
+			if tt.expectedStatus == model.DeliveryPending {
+				assert.WithinDuration(t, time.Now().Add(time.Hour), delivery.NextAttemptAt, time.Minute)
+			}
+		})
+	}
+}
+
+func TestWebhookDispatcherNotify(t *testing.T) {
+	server, received := newReceiver(t, http.StatusNoContent)
+	mockContainer, mocks := container.NewMockContainer(t)
+
+	dispatcher := service.NewWebhookDispatcher(store.NewWebhookStore(), 10)
+	dispatcher.Client = server.Client()
+	dispatcher.Start(1)
+
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "webhooks",
+		bson.M{"owner": "alice", "active": true, "events": model.EventLinkClicked}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.Webhook) = []model.Webhook{{ID: "w1", URL: server.URL, Secret: "secret"}}
+			return nil
+		})
+	mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "webhook_deliveries", gomock.Any()).Return("id", nil)
+	updated := make(chan struct{})
+	mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "webhook_deliveries", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(context.Context, string, any, any) error {
+			close(updated)
+			return nil
+		})
+
+	ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+	dispatcher.Notify(ctx, model.Event{
+		ID:    "e1",
+		Type:  model.EventLinkClicked,
+		Owner: "alice",
+		Data:  model.ClickData{ShortCode: "abc123"},
+	})
+
+	select {
+	case got := <-received:
+		assert.JSONEq(t,
+			`{"id":"e1","type":"link.clicked","occurred_at":"0001-01-01T00:00:00Z","data":{"short_code":"abc123","destination":""}}`,
+			string(got.body))
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+	<-updated
+}
+
+func TestWebhookDispatcherSkipsLinksWithoutOwner(t *testing.T) {
+	mockContainer, _ := container.NewMockContainer(t)
+	dispatcher := service.NewWebhookDispatcher(store.NewWebhookStore(), 10)
+	dispatcher.Start(1)
+
+	ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+	// No webhooks are loaded: the mock fails the test on any call.
+	dispatcher.Notify(ctx, model.Event{ID: "e1", Type: model.EventLinkClicked, Data: model.ClickData{ShortCode: "abc123"}})
+	dispatcher.Stop()
+}
+
+func TestWebhookDispatcherRetryDue(t *testing.T) {
+	server, received := newReceiver(t, http.StatusOK)
+	mockContainer, mocks := container.NewMockContainer(t)
+
+	dispatcher := service.NewWebhookDispatcher(store.NewWebhookStore(), 10)
+	dispatcher.Client = server.Client()
+	dispatcher.Start(1)
+
+	due := time.Now().UTC().Add(-time.Minute).Truncate(time.Millisecond)
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "webhook_deliveries", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.WebhookDelivery) = []model.WebhookDelivery{
+				{ID: "d1", WebhookID: "w1", Status: model.DeliveryPending, Attempts: 1, NextAttemptAt: due},
+				{ID: "d2", WebhookID: "gone", Status: model.DeliveryPending, Attempts: 1, NextAttemptAt: due},
+			}
+			return nil
+		})
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "webhooks", bson.M{"_id": bson.M{"$in": []string{"w1", "gone"}}}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.Webhook) = []model.Webhook{{ID: "w1", URL: server.URL, Secret: "secret", Active: true}}
+			return nil
+		})
+	mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "webhook_deliveries", bson.M{"_id": "d2"}, gomock.Any()).Return(nil)
+	mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "webhook_deliveries",
+		bson.M{"_id": "d1", "status": model.DeliveryPending, "next_attempt_at": due}, gomock.Any()).Return(int64(1), nil)
+	mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "webhook_deliveries", bson.M{"_id": "d1"}, gomock.Any()).Return(nil)
+
+	dispatcher.RetryDue(&gofr.Context{Context: context.Background(), Container: mockContainer})
+	dispatcher.Stop()
+
+	got := <-received
+	assert.Equal(t, "d1", got.header.Get(service.WebhookDeliveryHeader))
+}
+
+func TestWebhookDispatcherRefusesPrivateAddresses(t *testing.T) {
+	server, received := newReceiver(t, http.StatusOK)
+	mockContainer, mocks := container.NewMockContainer(t)
+
+	dispatcher := service.NewWebhookDispatcher(store.NewWebhookStore(), 10)
+	mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "webhook_deliveries", bson.M{"_id": "d1"}, gomock.Any()).Return(nil)
+
+	delivery := &model.WebhookDelivery{ID: "d1", WebhookID: "w1", Payload: "{}"}
+	dispatcher.Deliver(&gofr.Context{Context: context.Background(), Container: mockContainer},
+		&model.Webhook{ID: "w1", URL: server.URL}, delivery)
+
+	assert.Contains(t, delivery.LastError, service.ErrPrivateAddress.Error())
+	assert.Empty(t, received)
+}
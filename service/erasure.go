@@ -0,0 +1,281 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+var (
+	ErrErasureUser      = &apierror.Error{Status: http.StatusBadRequest, Message: "user_id is required"}
+	ErrErasureLastOwner = &apierror.Error{Status: http.StatusConflict,
+		Message: "user is the last owner of a workspace; transfer its ownership first"}
+)
+
+type ErasureService interface {
+	Erase(ctx *gofr.Context, userID string) (*model.ErasureReport, error)
+}
+
+// ErasureServiceImpl deletes everything tied to a user: their links with the
+// clicks, rollups, revisions, abuse reports and idempotency records of those
+// links, their folders, webhooks, reports, workspace memberships,
+// subscription and usage, the invitations addressed to them, and the audit
+// entries they recorded or that concern their resources. Links they made in a
+// workspace belong to its team, so they are handed to another owner of the
+// workspace instead; a user who is the last owner of a workspace cannot be
+// erased until they hand it over. Records kept for the team, the revisions
+// they made to other links, the workspaces they created and the invitations
+// they sent, name model.ErasedUser instead of them.
+// Pending single sign-on logins are not tied to a user and expire on their
+// own.
+type ErasureServiceImpl struct {
+	URLs         *store.URLStore
+	Clicks       *store.ClickStore
+	Rollups      *store.RollupStore
+	Revisions    *store.RevisionStore
+	Folders      *store.FolderStore
+	Webhooks     *store.WebhookStore
+	Reports      *store.ReportStore
+	Members      *store.WorkspaceStore
+	AbuseReports *store.AbuseReportStore
+	Quotas       *store.QuotaStore
+	Idempotency  *store.IdempotencyStore
+	AuditLog     *store.AuditStore
+	Audit        *Auditor
+}
+
+func NewErasureService(
+	urls *store.URLStore, clicks *store.ClickStore, rollups *store.RollupStore, revisions *store.RevisionStore,
+	folders *store.FolderStore, webhooks *store.WebhookStore, reports *store.ReportStore,
+	workspaces *store.WorkspaceStore, abuseReports *store.AbuseReportStore, quotas *store.QuotaStore,
+	idempotency *store.IdempotencyStore, auditLog *store.AuditStore, auditor *Auditor,
+) ErasureService {
+	return &ErasureServiceImpl{
+		URLs:         urls,
+		Clicks:       clicks,
+		Rollups:      rollups,
+		Revisions:    revisions,
+		Folders:      folders,
+		Webhooks:     webhooks,
+		Reports:      reports,
+		Members:      workspaces,
+		AbuseReports: abuseReports,
+		Quotas:       quotas,
+		Idempotency:  idempotency,
+		AuditLog:     auditLog,
+		Audit:        auditor,
+	}
+}
+
+// Erase removes the user's data and reports what was removed. It can be
+// repeated safely when it fails part way.
+func (s *ErasureServiceImpl) Erase(ctx *gofr.Context, userID string) (*model.ErasureReport, error) {
+	if userID == "" {
+		return nil, ErrErasureUser
+	}
+	memberships, err := s.Members.FindMemberships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkOwnership(ctx, memberships); err != nil {
+		return nil, err
+	}
+	owned, err := s.URLs.Find(ctx, model.URLFilter{Owner: userID})
+	if err != nil {
+		return nil, err
+	}
+	report := &model.ErasureReport{UserID: userID}
+	var links []model.URL
+	shared := map[string][]string{}
+	for _, link := range owned {
+		if link.Workspace == "" {
+			links = append(links, link)
+		} else {
+			shared[link.Workspace] = append(shared[link.Workspace], link.ShortCode)
+		}
+	}
+	// Handing over first keeps the clicks and rollups of those links out of
+	// the deletions below.
+	if report.LinksHandedOver, err = s.handOver(ctx, userID, shared); err != nil {
+		return nil, err
+	}
+	released, err := s.leaveOwnerships(ctx, memberships)
+	if err != nil {
+		return nil, err
+	}
+	folders, err := s.Folders.FindByOwner(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	webhooks, err := s.Webhooks.FindByOwner(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	reports, err := s.Reports.FindByOwner(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, len(links))
+	resources := make([]string, 0, len(links)+len(folders)+len(webhooks)+len(reports))
+	for i := range links {
+		codes[i] = links[i].ShortCode
+		resources = append(resources, "link:"+links[i].ShortCode)
+	}
+	for i := range folders {
+		resources = append(resources, "folder:"+folders[i].ID)
+	}
+	webhookIDs := make([]string, len(webhooks))
+	for i := range webhooks {
+		webhookIDs[i] = webhooks[i].ID
+		resources = append(resources, "webhook:"+webhooks[i].ID)
+	}
+	for i := range reports {
+		resources = append(resources, "report:"+reports[i].ID)
+	}
+
+	steps := []struct {
+		count *int64
+		run   func() (int64, error)
+	}{
+		{&report.Clicks, func() (int64, error) { return s.Clicks.DeleteByOwner(ctx, userID) }},
+		{&report.Rollups, func() (int64, error) { return s.Rollups.DeleteByOwner(ctx, userID) }},
+		{&report.Revisions, func() (int64, error) { return s.Revisions.DeleteByShortCodes(ctx, codes) }},
+		{&report.AbuseReports, func() (int64, error) { return s.AbuseReports.DeleteByShortCodes(ctx, codes) }},
+		{&report.Invitations, func() (int64, error) { return s.Members.DeleteInvitationsOf(ctx, userID) }},
+		{&report.Pseudonymized, func() (int64, error) { return s.pseudonymize(ctx, userID) }},
+		{&report.IdempotencyKeys, func() (int64, error) { return s.Idempotency.DeleteByOwner(ctx, userID) }},
+		{&report.WebhookDeliveries, func() (int64, error) { return s.Webhooks.DeleteDeliveries(ctx, webhookIDs) }},
+		{&report.Webhooks, func() (int64, error) { return s.Webhooks.DeleteByOwner(ctx, userID) }},
+		{&report.Folders, func() (int64, error) { return s.Folders.DeleteByOwner(ctx, userID) }},
+		{&report.Reports, func() (int64, error) { return s.Reports.DeleteByOwner(ctx, userID) }},
+		{&report.Memberships, func() (int64, error) {
+			n, err := s.Members.DeleteMemberships(ctx, userID)
+			return released + n, err
+		}},
+		{&report.Subscriptions, func() (int64, error) { return s.Quotas.DeleteSubscription(ctx, userID) }},
+		{&report.Usage, func() (int64, error) { return s.Quotas.DeleteUsage(ctx, userID) }},
+		{&report.AuditEntries, func() (int64, error) { return s.AuditLog.DeleteForUser(ctx, userID, resources) }},
+		// Links go last so that a failed erasure can find them again on retry.
+		{&report.Links, func() (int64, error) { return s.URLs.DeleteByOwner(ctx, userID) }},
+	}
+	for _, step := range steps {
+		if *step.count, err = step.run(); err != nil {
+			return nil, err
+		}
+	}
+	report.ErasedAt = time.Now().UTC()
+	// The erasure itself is recorded with counts only, to prove it happened.
+	s.Audit.Record(ctx, model.AuditUserErase, "user:"+userID, nil, report)
+	return report, nil
+}
+
+// pseudonymize replaces userID with model.ErasedUser in the records that stay
+// with others once the user's own data is gone.
+func (s *ErasureServiceImpl) pseudonymize(ctx *gofr.Context, userID string) (int64, error) {
+	var total int64
+	for _, replace := range []func(*gofr.Context, string, string) (int64, error){
+		s.Revisions.SetChangedBy,
+		s.Members.SetCreatedBy,
+		s.Members.SetInvitedBy,
+	} {
+		n, err := replace(ctx, userID, model.ErasedUser)
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// checkOwnership refuses erasure when the user is the last owner of one of
+// their workspaces, before anything is deleted.
+func (s *ErasureServiceImpl) checkOwnership(ctx *gofr.Context, memberships []model.Membership) error {
+	var ids []string
+	for i := range memberships {
+		if memberships[i].Role == model.RoleOwner {
+			ids = append(ids, memberships[i].WorkspaceID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	workspaces, err := s.Members.FindByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range workspaces {
+		if workspaces[i].Owners <= 1 {
+			return ErrErasureLastOwner
+		}
+	}
+	return nil
+}
+
+// handOver gives the user's links in each workspace, with their clicks and
+// rollups, to another owner of that workspace.
+func (s *ErasureServiceImpl) handOver(ctx *gofr.Context, userID string, shared map[string][]string) (int64, error) {
+	var handed int64
+	for workspaceID, codes := range shared {
+		owner, err := s.otherOwner(ctx, workspaceID, userID)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := s.Clicks.SetOwner(ctx, codes, owner); err != nil {
+			return 0, err
+		}
+		if _, err := s.Rollups.SetOwner(ctx, codes, owner); err != nil {
+			return 0, err
+		}
+		n, err := s.URLs.SetOwner(ctx, codes, owner)
+		if err != nil {
+			return 0, err
+		}
+		handed += n
+	}
+	return handed, nil
+}
+
+func (s *ErasureServiceImpl) otherOwner(ctx *gofr.Context, workspaceID, userID string) (string, error) {
+	members, err := s.Members.FindMembers(ctx, workspaceID)
+	if err != nil {
+		return "", err
+	}
+	for i := range members {
+		if members[i].Role == model.RoleOwner && members[i].UserID != userID {
+			return members[i].UserID, nil
+		}
+	}
+	return "", ErrErasureLastOwner
+}
+
+// leaveOwnerships removes the user from the workspaces they own, stepping
+// down as owner first so that a concurrent change cannot leave a workspace
+// without one. It returns how many memberships it removed.
+func (s *ErasureServiceImpl) leaveOwnerships(ctx *gofr.Context, memberships []model.Membership) (int64, error) {
+	var removed int64
+	for i := range memberships {
+		member := &memberships[i]
+		if member.Role != model.RoleOwner {
+			continue
+		}
+		err := releaseOwner(ctx, s.Members, member.WorkspaceID)
+		if errors.Is(err, ErrLastOwner) {
+			return removed, ErrErasureLastOwner
+		}
+		if err != nil {
+			return removed, err
+		}
+		if err := s.Members.RemoveMember(ctx, member.WorkspaceID, member.UserID); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
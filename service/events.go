@@ -0,0 +1,54 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+// EventListener is notified of link events after the change is stored.
+// Implementations must not block the request; slow work belongs on a queue.
+type EventListener interface {
+	Notify(ctx *gofr.Context, event model.Event)
+}
+
+// WithListener subscribes l to the link events of the URL service.
+func WithListener(l EventListener) URLOption {
+	return func(s *URLServiceImpl) {
+		s.Listeners = append(s.Listeners, l)
+	}
+}
+
+func (s *URLServiceImpl) emit(ctx *gofr.Context, eventType, owner string, data any) {
+	notify(ctx, s.Listeners, eventType, owner, data)
+}
+
+// notify delivers a new event to listeners.
+func notify(ctx *gofr.Context, listeners []EventListener, eventType, owner string, data any) {
+	if len(listeners) == 0 {
+		return
+	}
+	event := model.Event{
+		ID:         randomHex(16),
+		Type:       eventType,
+		Owner:      owner,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+	for _, l := range listeners {
+		l.Notify(ctx, event)
+	}
+}
+
+// randomHex returns n random bytes hex encoded.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
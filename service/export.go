@@ -0,0 +1,191 @@
+package service
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/hll"
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+var ErrInvalidScope = &apierror.Error{Status: http.StatusBadRequest,
+	Message: "exactly one of short_code, tag or campaign_id is required"}
+
+// Export returns the rollup buckets of the caller's links in scope, ordered
+// by link and time.
+func (s *AnalyticsServiceImpl) Export(ctx *gofr.Context, scope model.ReportScope, query model.AnalyticsQuery) ([]model.ExportRow, error) {
+	codes, err := s.linksInScope(ctx, middleware.Actor(ctx), scope)
+	if err != nil {
+		return nil, err
+	}
+	query = withQueryDefaults(query)
+	rows := []model.ExportRow{}
+	if len(codes) == 0 {
+		return rows, nil
+	}
+	rollups, err := s.Rollups.FindMany(ctx, codes, query.Granularity, BucketStart(query.Granularity, query.From), query.To)
+	if err != nil {
+		return nil, err
+	}
+	for _, rollup := range rollups {
+		rows = append(rows, model.ExportRow{
+			ShortCode: rollup.ShortCode,
+			Bucket:    rollup.Bucket,
+			Clicks:    rollup.Clicks,
+			BotClicks: rollup.BotClicks,
+			Uniques:   int64(sketchOf(rollup.Visitors).Estimate()),
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].ShortCode != rows[j].ShortCode {
+			return rows[i].ShortCode < rows[j].ShortCode
+		}
+		return rows[i].Bucket.Before(rows[j].Bucket)
+	})
+	return rows, nil
+}
+
+// summarize totals the clicks of owner's links in scope over whole days [from, to).
+func (s *AnalyticsServiceImpl) summarize(ctx *gofr.Context, owner string, scope model.ReportScope, from, to time.Time) (*model.ReportSummary, error) {
+	codes, err := s.linksInScope(ctx, owner, scope)
+	if err != nil {
+		return nil, err
+	}
+	summary := &model.ReportSummary{Scope: scope, From: from, To: to, Links: []model.LinkSummary{}}
+	if len(codes) == 0 {
+		return summary, nil
+	}
+	rollups, err := s.Rollups.FindMany(ctx, codes, model.GranularityDay, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	links := make(map[string]*model.LinkSummary, len(codes))
+	sketches := make(map[string]hll.Sketch, len(codes))
+	for _, code := range codes {
+		links[code] = &model.LinkSummary{ShortCode: code}
+		sketches[code] = hll.New()
+	}
+	visitors := hll.New()
+	referrers := make(map[string]int64)
+	for _, rollup := range rollups {
+		link, ok := links[rollup.ShortCode]
+		if !ok {
+			continue
+		}
+		link.Clicks += rollup.Clicks
+		link.BotClicks += rollup.BotClicks
+		sketch := sketchOf(rollup.Visitors)
+		sketches[rollup.ShortCode].Merge(sketch)
+		visitors.Merge(sketch)
+		for value, clicks := range rollup.Dimensions[model.DimensionReferrer] {
+			referrers[value] += clicks
+		}
+	}
+
+	sort.Strings(codes)
+	for _, code := range codes {
+		link := links[code]
+		link.Uniques = int64(sketches[code].Estimate())
+		summary.Clicks += link.Clicks
+		summary.BotClicks += link.BotClicks
+		summary.Links = append(summary.Links, *link)
+	}
+	summary.Uniques = int64(visitors.Estimate())
+	summary.TopReferrers = rankValues(referrers)
+	if len(summary.TopReferrers) > topDimensionValues {
+		summary.TopReferrers = summary.TopReferrers[:topDimensionValues]
+	}
+	return summary, nil
+}
+
+// linksInScope returns the short codes of the links selected by scope that
+// owner may view: their own links and the links of their workspaces.
+func (s *AnalyticsServiceImpl) linksInScope(ctx *gofr.Context, owner string, scope model.ReportScope) ([]string, error) {
+	if err := validateScope(scope); err != nil {
+		return nil, err
+	}
+	if owner == "" {
+		// Links made without signing in have no owner to export them.
+		return nil, ErrActorRequired
+	}
+	workspaces, err := s.Workspace.Workspaces(ctx, owner, model.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	visible := func(link *model.URL) bool {
+		if link.Workspace != "" {
+			return workspaces[link.Workspace]
+		}
+		return link.Owner == owner
+	}
+	var links []model.URL
+	switch {
+	case scope.ShortCode != "":
+		link, err := s.URLs.FindByShortCode(ctx, scope.ShortCode)
+		if err != nil {
+			return nil, err
+		}
+		if !visible(link) {
+			return nil, mongo.ErrNoDocuments
+		}
+		links = []model.URL{*link}
+	case scope.Tag != "":
+		owned, err := s.URLs.Find(ctx, model.URLFilter{Owner: owner, Tags: []string{scope.Tag}})
+		if err != nil {
+			return nil, err
+		}
+		for _, link := range owned {
+			// The owner's workspace links come with their workspace below.
+			if link.Workspace == "" {
+				links = append(links, link)
+			}
+		}
+		ids := make([]string, 0, len(workspaces))
+		for id := range workspaces {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			shared, err := s.URLs.Find(ctx, model.URLFilter{Workspace: id, Tags: []string{scope.Tag}})
+			if err != nil {
+				return nil, err
+			}
+			links = append(links, shared...)
+		}
+	default:
+		campaignLinks, err := s.URLs.FindByCampaign(ctx, scope.CampaignID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range campaignLinks {
+			if visible(&campaignLinks[i]) {
+				links = append(links, campaignLinks[i])
+			}
+		}
+	}
+	codes := make([]string, len(links))
+	for i, link := range links {
+		codes[i] = link.ShortCode
+	}
+	return codes, nil
+}
+
+func validateScope(scope model.ReportScope) error {
+	set := 0
+	for _, field := range []string{scope.ShortCode, scope.Tag, scope.CampaignID} {
+		if field != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return ErrInvalidScope
+	}
+	return nil
+}
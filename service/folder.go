@@ -0,0 +1,151 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+const (
+	maxFolderDepth = 10
+	maxTagLength   = 64
+)
+
+var (
+	ErrInvalidFolder  = &apierror.Error{Status: http.StatusBadRequest, Message: "invalid folder path"}
+	ErrFolderNotFound = &apierror.Error{Status: http.StatusNotFound, Message: "folder not found"}
+	ErrFolderNotEmpty = &apierror.Error{Status: http.StatusConflict, Message: "folder is not empty"}
+	ErrInvalidTag     = &apierror.Error{Status: http.StatusBadRequest, Message: "invalid tag"}
+	ErrNoFolders      = &apierror.Error{Status: http.StatusNotImplemented, Message: "folders are not enabled"}
+)
+
+type FolderServiceImpl struct {
+	Store    *store.FolderStore
+	URLStore *store.URLStore
+	Audit    *Auditor
+}
+
+func NewFolderService(folderStore *store.FolderStore, urlStore *store.URLStore, auditor *Auditor) FolderService {
+	return &FolderServiceImpl{Store: folderStore, URLStore: urlStore, Audit: auditor}
+}
+
+type FolderService interface {
+	Create(ctx *gofr.Context, req *model.CreateFolderRequest) (*model.Folder, error)
+	List(ctx *gofr.Context) ([]model.Folder, error)
+	Delete(ctx *gofr.Context, id string) error
+}
+
+// Create adds the folder at req.Path, creating any missing ancestors.
+func (s *FolderServiceImpl) Create(ctx *gofr.Context, req *model.CreateFolderRequest) (*model.Folder, error) {
+	folderPath, err := NormalizeFolderPath(req.Path)
+	if err != nil {
+		return nil, err
+	}
+	if folderPath == "" {
+		return nil, ErrInvalidFolder
+	}
+	owner := middleware.Actor(ctx)
+
+	segments := strings.Split(folderPath, "/")
+	var folder *model.Folder
+	for i := range segments {
+		current := strings.Join(segments[:i+1], "/")
+		existing, err := s.Store.FindByPath(ctx, owner, current)
+		if err == nil {
+			folder = existing
+			continue
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, err
+		}
+		folder = &model.Folder{
+			Owner:  owner,
+			Path:   current,
+			Parent: strings.Join(segments[:i], "/"),
+		}
+		if err := s.Store.Insert(ctx, folder); err != nil {
+			return nil, err
+		}
+		s.Audit.Record(ctx, model.AuditFolderCreate, "folder:"+folder.ID, nil, folder)
+	}
+	return folder, nil
+}
+
+func (s *FolderServiceImpl) List(ctx *gofr.Context) ([]model.Folder, error) {
+	return s.Store.FindByOwner(ctx, middleware.Actor(ctx))
+}
+
+// Delete removes an empty folder. Folders that still hold links or
+// subfolders are rejected so links are never orphaned.
+func (s *FolderServiceImpl) Delete(ctx *gofr.Context, id string) error {
+	owner := middleware.Actor(ctx)
+	folder, err := s.Store.FindByID(ctx, owner, id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrFolderNotFound
+	}
+	if err != nil {
+		return err
+	}
+	children, err := s.Store.CountChildren(ctx, owner, folder.Path)
+	if err != nil {
+		return err
+	}
+	links, err := s.URLStore.CountInFolder(ctx, owner, folder.Path)
+	if err != nil {
+		return err
+	}
+	if children > 0 || links > 0 {
+		return ErrFolderNotEmpty
+	}
+	if err := s.Store.Delete(ctx, owner, id); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, model.AuditFolderDelete, "folder:"+id, folder, nil)
+	return nil
+}
+
+// NormalizeFolderPath trims surrounding slashes and rejects empty, relative
+// or overly deep paths. The empty string stands for the root.
+func NormalizeFolderPath(raw string) (string, error) {
+	trimmed := strings.Trim(strings.TrimSpace(raw), "/")
+	if trimmed == "" {
+		return "", nil
+	}
+	segments := strings.Split(trimmed, "/")
+	if len(segments) > maxFolderDepth {
+		return "", ErrInvalidFolder
+	}
+	for _, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." {
+			return "", ErrInvalidFolder
+		}
+	}
+	return path.Join(segments...), nil
+}
+
+// NormalizeTags lower-cases, trims and de-duplicates tags, keeping their order.
+func NormalizeTags(raw []string) ([]string, error) {
+	seen := make(map[string]bool, len(raw))
+	tags := make([]string, 0, len(raw))
+	for _, tag := range raw {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || len(tag) > maxTagLength {
+			return nil, ErrInvalidTag
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
@@ -0,0 +1,211 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	gofrHttp "gofr.dev/pkg/gofr/http"
+
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+func actorContext(actor string) context.Context {
+	meta := middleware.GetRequestMeta(context.Background())
+	meta.Header.Set(middleware.ActorHeader, actor)
+	return middleware.WithRequestMeta(context.Background(), meta)
+}
+
+func TestNormalizeFolderPath(t *testing.T) {
+	tests := []struct {
+		raw         string
+		expected    string
+		expectError bool
+	}{
+		{raw: "", expected: ""},
+		{raw: "/marketing/2024/", expected: "marketing/2024"},
+		{raw: "marketing//2024", expectError: true},
+		{raw: "marketing/../admin", expectError: true},
+		{raw: "a/b/c/d/e/f/g/h/i/j/k", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			result, err := service.NormalizeFolderPath(tt.raw)
+			if tt.expectError {
+				assert.ErrorIs(t, err, service.ErrInvalidFolder)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := service.NormalizeTags([]string{" Docs", "beta", "docs"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"docs", "beta"}, tags)
+
+	_, err = service.NormalizeTags([]string{"  "})
+	assert.ErrorIs(t, err, service.ErrInvalidTag)
+}
+
+func TestFolderServiceCreateAddsMissingAncestors(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	folderService := service.NewFolderService(store.NewFolderStore(), store.NewURLStore(), nil)
+
+	mocks.Mongo.EXPECT().FindOne(gomock.Any(), "folders", bson.M{"owner": "alice", "path": "marketing"}, gomock.Any()).
+		Return(nil)
+	mocks.Mongo.EXPECT().FindOne(gomock.Any(), "folders", bson.M{"owner": "alice", "path": "marketing/2024"}, gomock.Any()).
+		Return(mongo.ErrNoDocuments)
+	mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "folders", gomock.Any()).Return("id", nil)
+
+	ctx := &gofr.Context{Context: actorContext("alice"), Container: mockContainer}
+
+	folder, err := folderService.Create(ctx, &model.CreateFolderRequest{Path: "/marketing/2024"})
+	assert.NoError(t, err)
+	assert.Equal(t, "marketing/2024", folder.Path)
+	assert.Equal(t, "marketing", folder.Parent)
+	assert.Equal(t, "alice", folder.Owner)
+}
+
+func TestFolderServiceDeleteRejectsNonEmpty(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	folderService := service.NewFolderService(store.NewFolderStore(), store.NewURLStore(), nil)
+
+	mocks.Mongo.EXPECT().FindOne(gomock.Any(), "folders", bson.M{"owner": "alice", "_id": "f1"}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, result any) error {
+			*result.(*model.Folder) = model.Folder{ID: "f1", Owner: "alice", Path: "marketing"}
+			return nil
+		})
+	mocks.Mongo.EXPECT().CountDocuments(gomock.Any(), "folders", bson.M{"owner": "alice", "parent": "marketing"}).
+		Return(int64(0), nil)
+	mocks.Mongo.EXPECT().CountDocuments(gomock.Any(), "urls", bson.M{"owner": "alice", "folder": "marketing"}).
+		Return(int64(2), nil)
+
+	ctx := &gofr.Context{Context: actorContext("alice"), Container: mockContainer}
+
+	err := folderService.Delete(ctx, "f1")
+	assert.ErrorIs(t, err, service.ErrFolderNotEmpty)
+	assert.Equal(t, http.StatusConflict, statusOf(err))
+}
+
+func TestURLServiceListTags(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	urlService := service.NewURLService(store.NewURLStore(), "http://localhost:8000/")
+
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "urls", bson.M{"owner": "alice"}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.URL) = []model.URL{
+				{ShortCode: "a", Tags: []string{"docs", "beta"}},
+				{ShortCode: "b", Tags: []string{"docs"}},
+			}
+			return nil
+		})
+
+	ctx := &gofr.Context{Context: actorContext("alice"), Container: mockContainer}
+
+	tags, err := urlService.ListTags(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.TagCount{{Tag: "docs", Count: 2}, {Tag: "beta", Count: 1}}, tags)
+}
+
+func TestURLServiceBulkTag(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	urlService := service.NewURLService(store.NewURLStore(), "http://localhost:8000/")
+
+	filter := bson.M{"owner": "alice", "workspace": bson.M{"$exists": false}, "short_code": bson.M{"$in": []string{"a", "b"}}}
+	mocks.Mongo.EXPECT().CountDocuments(gomock.Any(), "urls", filter).Return(int64(2), nil)
+	mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "urls", filter, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, update any) (int64, error) {
+			assert.Equal(t, bson.M{"tags": bson.M{"$each": []string{"q3"}}}, update.(bson.M)["$addToSet"])
+			assert.Contains(t, update.(bson.M)["$set"], "updated_at")
+			return 1, nil
+		})
+	mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "urls", filter, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, update any) (int64, error) {
+			assert.Equal(t, bson.M{"tags": bson.M{"$in": []string{"q2"}}}, update.(bson.M)["$pull"])
+			return 1, nil
+		})
+
+	ctx := &gofr.Context{Context: actorContext("alice"), Container: mockContainer}
+
+	result, err := urlService.BulkTag(ctx, &model.BulkTagRequest{
+		ShortCodes: []string{"a", "b"},
+		Add:        []string{"Q3"},
+		Remove:     []string{"q2"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), result.Updated)
+}
+
+func TestURLServiceBulkTagWorkspaceLinks(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	urlService := service.NewURLService(store.NewURLStore(), "http://localhost:8000/",
+		service.WithWorkspaces(service.NewWorkspaceAccess(store.NewWorkspaceStore())))
+
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "workspace_members", bson.M{"user_id": "eve"}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.Membership) = []model.Membership{
+				{WorkspaceID: "ws2", UserID: "eve", Role: model.RoleViewer},
+				{WorkspaceID: "ws1", UserID: "eve", Role: model.RoleEditor},
+			}
+			return nil
+		})
+	filter := bson.M{
+		"short_code": bson.M{"$in": []string{"a"}},
+		"$or": bson.A{
+			bson.M{"owner": "eve", "workspace": bson.M{"$exists": false}},
+			bson.M{"workspace": bson.M{"$in": []string{"ws1"}}},
+		},
+	}
+	mocks.Mongo.EXPECT().CountDocuments(gomock.Any(), "urls", filter).Return(int64(1), nil)
+	mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "urls", filter, gomock.Any()).Return(int64(1), nil)
+
+	ctx := &gofr.Context{Context: actorContext("eve"), Container: mockContainer}
+
+	result, err := urlService.BulkTag(ctx, &model.BulkTagRequest{ShortCodes: []string{"a"}, Add: []string{"q3"}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), result.Updated)
+}
+
+func TestURLServiceOrganizeErrors(t *testing.T) {
+	t.Run("Bulk Tag Without Codes", func(t *testing.T) {
+		mockContainer, _ := container.NewMockContainer(t)
+		urlService := service.NewURLService(store.NewURLStore(), "http://localhost:8000/")
+		ctx := &gofr.Context{Context: actorContext("alice"), Container: mockContainer}
+
+		_, err := urlService.BulkTag(ctx, &model.BulkTagRequest{Add: []string{"q3"}})
+		assert.Equal(t, gofrHttp.ErrorMissingParam{Params: []string{"short_codes"}}, err)
+	})
+
+	t.Run("Bulk Tag Without Signing In", func(t *testing.T) {
+		mockContainer, _ := container.NewMockContainer(t)
+		urlService := service.NewURLService(store.NewURLStore(), "http://localhost:8000/")
+		ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+
+		_, err := urlService.BulkTag(ctx, &model.BulkTagRequest{ShortCodes: []string{"a"}, Add: []string{"q3"}})
+		assert.Equal(t, http.StatusUnauthorized, statusOf(err))
+	})
+
+	t.Run("Folders Disabled", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		urlService := service.NewURLService(store.NewURLStore(), "http://localhost:8000/")
+		expectLink(mocks, model.URL{ShortCode: "abc123", Owner: "alice"})
+		ctx := &gofr.Context{Context: actorContext("alice"), Container: mockContainer}
+
+		_, err := urlService.Move(ctx, "abc123", &model.MoveRequest{Folder: "docs"})
+		assert.Equal(t, http.StatusNotImplemented, statusOf(err))
+	})
+}
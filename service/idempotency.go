@@ -0,0 +1,143 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+
+	gofrHttp "gofr.dev/pkg/gofr/http"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+var (
+	ErrIdempotencyKeyReused = &apierror.Error{Status: http.StatusUnprocessableEntity,
+		Message: "Idempotency-Key was already used with a different request"}
+	ErrIdempotencyInProgress = &apierror.Error{Status: http.StatusConflict,
+		Message: "a request with this Idempotency-Key is still in progress, retry later"}
+)
+
+const maxIdempotencyKeyLength = 255
+
+// Idempotency makes retries of link creation safe. The first request with a
+// key creates the link; retries with the same key and body get that link
+// back instead of creating another one until the key expires. A request
+// holds its key for Lease, so a retry can take over the key of a request
+// that never finished.
+type Idempotency struct {
+	Store *store.IdempotencyStore
+	TTL   time.Duration
+	Lease time.Duration
+}
+
+func NewIdempotency(store *store.IdempotencyStore, ttl time.Duration) *Idempotency {
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return &Idempotency{Store: store, TTL: ttl, Lease: time.Minute}
+}
+
+// Create calls create at most once for the client's key and req. Requests
+// that fail are forgotten, so the client can retry them with the same key.
+func (i *Idempotency) Create(ctx *gofr.Context, key string, req *model.CreateURLRequest,
+	create func() (*model.URL, error)) (*model.URL, error) {
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, gofrHttp.ErrorInvalidParam{Params: []string{model.IdempotencyHeader}}
+	}
+	now := time.Now().UTC()
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	record := &model.IdempotencyRecord{
+		ID:          hashToken(idempotencyClient(ctx) + "\x00" + key),
+		Fingerprint: hashToken(string(body)),
+		LeaseUntil:  now.Add(i.Lease),
+		ExpiresAt:   now.Add(i.TTL),
+	}
+	err = i.Store.Reserve(ctx, record)
+	if mongo.IsDuplicateKeyError(err) {
+		link, err := i.replay(ctx, record, now)
+		if link != nil || err != nil {
+			return link, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	link, err := create()
+	if err != nil {
+		if err := i.Store.Release(ctx, record.ID); err != nil {
+			ctx.Logger.Errorf("releasing idempotency key: %v", err)
+		}
+		return nil, err
+	}
+	if err := i.Store.Complete(ctx, record.ID, link); err != nil {
+		// The link exists, so keep the key reserved: retries are refused
+		// until it expires rather than creating a duplicate.
+		ctx.Logger.Errorf("storing response for idempotency key: %v", err)
+	}
+	return link, nil
+}
+
+// replay answers a retry with the link created by the first request. It
+// returns neither a link nor an error when the retry took over the key, and
+// must create the link itself.
+func (i *Idempotency) replay(ctx *gofr.Context, record *model.IdempotencyRecord, now time.Time) (*model.URL, error) {
+	first, err := i.Store.Find(ctx, record.ID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// The first request failed and released the key just now.
+		return nil, ErrIdempotencyInProgress
+	}
+	if err != nil {
+		return nil, err
+	}
+	if now.Before(first.ExpiresAt) {
+		if first.Fingerprint != record.Fingerprint {
+			return nil, ErrIdempotencyKeyReused
+		}
+		if first.Link != nil {
+			return first.Link, nil
+		}
+		if now.Before(first.LeaseUntil) {
+			return nil, ErrIdempotencyInProgress
+		}
+	}
+	// The key expired before it was deleted, or its first request stopped
+	// without finishing.
+	taken, err := i.Store.TakeOver(ctx, record, now)
+	if err != nil {
+		return nil, err
+	}
+	if !taken {
+		return nil, ErrIdempotencyInProgress
+	}
+	return nil, nil
+}
+
+// Expire deletes the keys that expired. It runs as a cron job; a request
+// that finds an expired key first takes it over instead.
+func (i *Idempotency) Expire(ctx *gofr.Context) {
+	n, err := i.Store.DeleteExpired(ctx, time.Now().UTC())
+	if err != nil {
+		ctx.Logger.Errorf("deleting expired idempotency keys: %v", err)
+		return
+	}
+	ctx.Logger.Infof("deleted %d expired idempotency keys", n)
+}
+
+// idempotencyClient scopes keys to the signed-in user, or to the client
+// address for anonymous requests, so clients cannot replay each other's links.
+func idempotencyClient(ctx *gofr.Context) string {
+	if actor := middleware.Actor(ctx); actor != "" {
+		return "user:" + actor
+	}
+	return "ip:" + middleware.ClientIP(ctx)
+}
@@ -0,0 +1,198 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+func idempotentContext(actor, key string) context.Context {
+	ctx := actorContext(actor)
+	middleware.GetRequestMeta(ctx).Header.Set(model.IdempotencyHeader, key)
+	return ctx
+}
+
+// expectIdempotencyKeys backs the idempotency_keys collection with a map.
+func expectIdempotencyKeys(mocks *container.Mocks) map[string]*model.IdempotencyRecord {
+	records := map[string]*model.IdempotencyRecord{}
+	mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "idempotency_keys", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, document any) (any, error) {
+			record := document.(*model.IdempotencyRecord)
+			if _, ok := records[record.ID]; ok {
+				return nil, mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}
+			}
+			copied := *record
+			records[record.ID] = &copied
+			return record.ID, nil
+		}).AnyTimes()
+	mocks.Mongo.EXPECT().FindOne(gomock.Any(), "idempotency_keys", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, filter any, result any) error {
+			record, ok := records[filter.(bson.M)["_id"].(string)]
+			if !ok {
+				return mongo.ErrNoDocuments
+			}
+			*result.(*model.IdempotencyRecord) = *record
+			return nil
+		}).AnyTimes()
+	mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "idempotency_keys", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, filter any, update any) error {
+			link := update.(bson.M)["$set"].(bson.M)["link"].(*model.URL)
+			records[filter.(bson.M)["_id"].(string)].Link = link
+			return nil
+		}).AnyTimes()
+	mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "idempotency_keys", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, filter any, update any) (int64, error) {
+			query := filter.(bson.M)
+			now := query["$or"].(bson.A)[0].(bson.M)["expires_at"].(bson.M)["$lt"].(time.Time)
+			record := records[query["_id"].(string)]
+			if record == nil || !record.ExpiresAt.Before(now) && (record.Link != nil || !record.LeaseUntil.Before(now)) {
+				return 0, nil
+			}
+			set := update.(bson.M)["$set"].(bson.M)
+			record.Link = nil
+			record.LeaseUntil, record.ExpiresAt = set["lease_until"].(time.Time), set["expires_at"].(time.Time)
+			return 1, nil
+		}).AnyTimes()
+	mocks.Mongo.EXPECT().DeleteOne(gomock.Any(), "idempotency_keys", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, filter any) (int64, error) {
+			delete(records, filter.(bson.M)["_id"].(string))
+			return 1, nil
+		}).AnyTimes()
+	return records
+}
+
+func TestURLServiceCreateIdempotent(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	expectIdempotencyKeys(mocks)
+	svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/",
+		service.WithIdempotency(service.NewIdempotency(store.NewIdempotencyStore(), 0)))
+	ctx := &gofr.Context{Context: idempotentContext("alice", "job-42"), Container: mockContainer}
+	req := &model.CreateURLRequest{OriginalURL: "https://example.com"}
+
+	mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "urls", gomock.Any()).Return("id", nil).Times(1)
+	first, err := svc.Create(ctx, req)
+	assert.NoError(t, err)
+
+	t.Run("Retry Replays", func(t *testing.T) {
+		retry, err := svc.Create(ctx, &model.CreateURLRequest{OriginalURL: "https://example.com"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, first.ShortCode, retry.ShortCode)
+		assert.Equal(t, first.ShortURL, retry.ShortURL)
+	})
+
+	t.Run("Different Body", func(t *testing.T) {
+		_, err := svc.Create(ctx, &model.CreateURLRequest{OriginalURL: "https://example.org"})
+
+		assert.Equal(t, service.ErrIdempotencyKeyReused, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, service.ErrIdempotencyKeyReused.StatusCode())
+	})
+
+	t.Run("Other Client", func(t *testing.T) {
+		mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "urls", gomock.Any()).Return("id", nil).Times(1)
+		other := &gofr.Context{Context: idempotentContext("bob", "job-42"), Container: mockContainer}
+
+		link, err := svc.Create(other, req)
+
+		assert.NoError(t, err)
+		assert.NotEqual(t, first.ShortCode, link.ShortCode)
+	})
+}
+
+func TestURLServiceCreateIdempotentInProgress(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	records := expectIdempotencyKeys(mocks)
+	svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/",
+		service.WithIdempotency(service.NewIdempotency(store.NewIdempotencyStore(), 0)))
+	ctx := &gofr.Context{Context: idempotentContext("alice", "job-42"), Container: mockContainer}
+	req := &model.CreateURLRequest{OriginalURL: "https://example.com"}
+
+	// The retry arrives while the first request is still inserting the link.
+	mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "urls", gomock.Any()).
+		DoAndReturn(func(context.Context, string, any) (any, error) {
+			assert.Len(t, records, 1)
+			_, err := svc.Create(ctx, req)
+			assert.Equal(t, service.ErrIdempotencyInProgress, err)
+			return "id", nil
+		})
+
+	_, err := svc.Create(ctx, req)
+
+	assert.NoError(t, err)
+}
+
+func TestURLServiceCreateIdempotentFailure(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	records := expectIdempotencyKeys(mocks)
+	svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/",
+		service.WithIdempotency(service.NewIdempotency(store.NewIdempotencyStore(), 0)))
+	ctx := &gofr.Context{Context: idempotentContext("alice", "job-42"), Container: mockContainer}
+	req := &model.CreateURLRequest{OriginalURL: "https://example.com"}
+
+	mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "urls", gomock.Any()).Return(nil, errors.New("connection reset"))
+	_, err := svc.Create(ctx, req)
+	assert.Error(t, err)
+	assert.Empty(t, records, "failed requests release their key")
+
+	mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "urls", gomock.Any()).Return("id", nil)
+	_, err = svc.Create(ctx, req)
+	assert.NoError(t, err)
+}
+
+func TestIdempotencyTakeOver(t *testing.T) {
+	t.Run("Abandoned Request", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectIdempotencyKeys(mocks)
+		idempotency := service.NewIdempotency(store.NewIdempotencyStore(), 0)
+		idempotency.Lease = 0
+		ctx := &gofr.Context{Context: idempotentContext("alice", "job-42"), Container: mockContainer}
+		req := &model.CreateURLRequest{OriginalURL: "https://example.com"}
+
+		// The first request still holds the key after its lease ran out, as
+		// if its instance had stopped, so the retry serves the request itself.
+		first, err := idempotency.Create(ctx, "job-42", req, func() (*model.URL, error) {
+			retry, err := idempotency.Create(ctx, "job-42", req, func() (*model.URL, error) {
+				return &model.URL{ShortCode: "retry1"}, nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, "retry1", retry.ShortCode)
+			return &model.URL{ShortCode: "first1"}, nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "first1", first.ShortCode)
+	})
+
+	t.Run("Expired Key", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectIdempotencyKeys(mocks)
+		idempotency := service.NewIdempotency(store.NewIdempotencyStore(), 0)
+		idempotency.TTL = -time.Minute
+		ctx := &gofr.Context{Context: idempotentContext("alice", "job-42"), Container: mockContainer}
+		req := &model.CreateURLRequest{OriginalURL: "https://example.com"}
+
+		_, err := idempotency.Create(ctx, "job-42", req, func() (*model.URL, error) {
+			return &model.URL{ShortCode: "first1"}, nil
+		})
+		assert.NoError(t, err)
+		link, err := idempotency.Create(ctx, "job-42", &model.CreateURLRequest{OriginalURL: "https://example.org"},
+			func() (*model.URL, error) { return &model.URL{ShortCode: "again1"}, nil })
+
+		assert.NoError(t, err)
+		assert.Equal(t, "again1", link.ShortCode)
+	})
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+
+	gofrHttp "gofr.dev/pkg/gofr/http"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+var (
+	ErrLinkNotFound    = &apierror.Error{Status: http.StatusNotFound, Message: "link not found"}
+	ErrAlreadyReported = &apierror.Error{Status: http.StatusConflict, Message: "this link has already been reported from your address"}
+	ErrTooManyReports  = &apierror.Error{Status: http.StatusTooManyRequests,
+		Message: "too many open reports from your address, try again once they are reviewed"}
+)
+
+// ErrLinkDisabled is returned instead of redirecting through a link an admin
+// has taken down.
+var ErrLinkDisabled = &apierror.Error{Status: http.StatusGone, Message: "this link has been disabled"}
+
+// maxReportDetails caps the free text of an abuse report.
+const maxReportDetails = 2000
+
+// maxOpenReports caps the open reports filed from one network across all links.
+const maxOpenReports = 10
+
+var abuseReasons = map[string]bool{
+	model.AbusePhishing: true,
+	model.AbuseMalware:  true,
+	model.AbuseSpam:     true,
+	model.AbuseFraud:    true,
+	model.AbuseIllegal:  true,
+	model.AbuseOther:    true,
+}
+
+type ModerationService interface {
+	Report(ctx *gofr.Context, code string, req *model.ReportAbuseRequest) (*model.AbuseReport, error)
+	Queue(ctx *gofr.Context) ([]model.ModerationItem, error)
+	Act(ctx *gofr.Context, code string, req *model.ModerationRequest) (*model.URL, error)
+}
+
+// ModerationServiceImpl collects abuse reports from anyone and lets admins
+// review reported links, and links screening flagged, from one queue.
+type ModerationServiceImpl struct {
+	URLs      *store.URLStore
+	Reports   *store.AbuseReportStore
+	Host      string
+	Audit     *Auditor
+	Listeners []EventListener
+}
+
+func NewModerationService(
+	urls *store.URLStore, reports *store.AbuseReportStore, host string, auditor *Auditor,
+	listeners ...EventListener,
+) ModerationService {
+	return &ModerationServiceImpl{
+		URLs:      urls,
+		Reports:   reports,
+		Host:      host,
+		Audit:     auditor,
+		Listeners: listeners,
+	}
+}
+
+// Report files an abuse report against a link. Reporters are told apart by
+// the network of the connection, /24 for IPv4 and /48 for IPv6, taken through
+// trusted proxies only and whatever the click anonymization mode. Each
+// network may hold one open report per link and maxOpenReports in all, so a
+// single visitor cannot flood the queue.
+func (s *ModerationServiceImpl) Report(
+	ctx *gofr.Context, code string, req *model.ReportAbuseRequest,
+) (*model.AbuseReport, error) {
+	if !abuseReasons[req.Reason] {
+		return nil, gofrHttp.ErrorInvalidParam{Params: []string{"reason"}}
+	}
+	if len(req.Details) > maxReportDetails {
+		return nil, gofrHttp.ErrorInvalidParam{Params: []string{"details"}}
+	}
+	if _, err := s.findLink(ctx, code); err != nil {
+		return nil, err
+	}
+	reporter := truncateIP(middleware.ClientIP(ctx))
+	if reporter != "" {
+		open, err := s.Reports.CountOpenByReporter(ctx, code, reporter)
+		if err != nil {
+			return nil, err
+		}
+		if open > 0 {
+			return nil, ErrAlreadyReported
+		}
+		if open, err = s.Reports.CountOpenByReporter(ctx, "", reporter); err != nil {
+			return nil, err
+		}
+		if open >= maxOpenReports {
+			return nil, ErrTooManyReports
+		}
+	}
+	report := &model.AbuseReport{
+		ShortCode: code,
+		Reason:    req.Reason,
+		Details:   req.Details,
+		Reporter:  reporter,
+	}
+	if err := s.Reports.Insert(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Queue lists the links waiting for review: those with open reports and
+// those screening flagged that were never reviewed. The most reported links
+// come first.
+func (s *ModerationServiceImpl) Queue(ctx *gofr.Context) ([]model.ModerationItem, error) {
+	reports, err := s.Reports.FindOpen(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byCode := make(map[string][]model.AbuseReport)
+	codes := make([]string, 0)
+	for i := range reports {
+		code := reports[i].ShortCode
+		if _, ok := byCode[code]; !ok {
+			codes = append(codes, code)
+		}
+		byCode[code] = append(byCode[code], reports[i])
+	}
+
+	var links []model.URL
+	if len(codes) > 0 {
+		if links, err = s.URLs.FindByShortCodes(ctx, codes); err != nil {
+			return nil, err
+		}
+	}
+	flagged, err := s.URLs.FindFlagged(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range flagged {
+		if _, ok := byCode[flagged[i].ShortCode]; !ok {
+			links = append(links, flagged[i])
+		}
+	}
+
+	// Reports on links deleted since are left out; nothing can be done about them.
+	items := make([]model.ModerationItem, 0, len(links))
+	for i := range links {
+		link := links[i]
+		link.ShortURL = s.Host + link.ShortCode
+		reports := byCode[link.ShortCode]
+		if reports == nil {
+			reports = []model.AbuseReport{}
+		}
+		items = append(items, model.ModerationItem{Link: link, Reports: reports})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if len(items[i].Reports) != len(items[j].Reports) {
+			return len(items[i].Reports) > len(items[j].Reports)
+		}
+		return items[i].Link.CreatedAt.Before(items[j].Link.CreatedAt)
+	})
+	return items, nil
+}
+
+// Act disables, deletes or clears a link and resolves its open reports.
+// Clearing also lets a link screening flagged redirect normally again.
+func (s *ModerationServiceImpl) Act(ctx *gofr.Context, code string, req *model.ModerationRequest) (*model.URL, error) {
+	var status string
+	switch req.Action {
+	case model.ModerationActionDisable:
+		status = model.ModerationDisabled
+	case model.ModerationActionClear:
+		status = model.ModerationCleared
+	case model.ModerationActionDelete:
+	default:
+		return nil, gofrHttp.ErrorInvalidParam{Params: []string{"action"}}
+	}
+	link, err := s.findLink(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	link.ShortURL = s.Host + link.ShortCode
+	before := *link
+
+	if req.Action == model.ModerationActionDelete {
+		deleted, err := s.URLs.DeleteByShortCode(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		if deleted == 0 {
+			return nil, ErrLinkNotFound
+		}
+	} else {
+		link.Moderation = &model.Moderation{Status: status, Note: req.Note, UpdatedAt: time.Now().UTC()}
+		if err := s.URLs.SetModeration(ctx, code, link.Moderation); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := s.Reports.Resolve(ctx, code, req.Action); err != nil {
+		// The link has been dealt with; the reports only stay in the queue.
+		ctx.Logger.Errorf("resolving reports of %s: %v", code, err)
+	}
+
+	if req.Action == model.ModerationActionDelete {
+		s.Audit.Record(ctx, model.AuditLinkModerate, "link:"+code, &before, nil)
+		notify(ctx, s.Listeners, model.EventLinkDeleted, link.Owner, link)
+		return link, nil
+	}
+	s.Audit.Record(ctx, model.AuditLinkModerate, "link:"+code, &before, link)
+	notify(ctx, s.Listeners, model.EventLinkUpdated, link.Owner, link)
+	return link, nil
+}
+
+// moderationOf returns the moderation status of link, "" when it was never reviewed.
+func moderationOf(link *model.URL) string {
+	if link.Moderation == nil {
+		return ""
+	}
+	return link.Moderation.Status
+}
+
+func (s *ModerationServiceImpl) findLink(ctx *gofr.Context, code string) (*model.URL, error) {
+	link, err := s.URLs.FindByShortCode(ctx, code)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrLinkNotFound
+	}
+	return link, err
+}
@@ -0,0 +1,233 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	gofrHttp "gofr.dev/pkg/gofr/http"
+
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+// reporterContext is an anonymous request from addr.
+func reporterContext(addr string) context.Context {
+	return middleware.WithRequestMeta(context.Background(),
+		middleware.RequestMeta{Method: http.MethodPost, Header: http.Header{}, RemoteAddr: addr})
+}
+
+func newModerationService() service.ModerationService {
+	return service.NewModerationService(store.NewURLStore(), store.NewAbuseReportStore(), "http://sho.rt/",
+		service.NewAuditor(store.NewAuditStore()))
+}
+
+func TestModerationServiceReport(t *testing.T) {
+	t.Run("Invalid Reason", func(t *testing.T) {
+		mockContainer, _ := container.NewMockContainer(t)
+		_, err := newModerationService().Report(&gofr.Context{Context: reporterContext("203.0.113.42:5000"), Container: mockContainer},
+			"abc123", &model.ReportAbuseRequest{Reason: "ugly"})
+
+		assert.Equal(t, gofrHttp.ErrorInvalidParam{Params: []string{"reason"}}, err)
+	})
+
+	t.Run("Unknown Link", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		mocks.Mongo.EXPECT().FindOne(gomock.Any(), "urls", bson.M{"short_code": "nope"}, gomock.Any()).Return(mongo.ErrNoDocuments)
+
+		_, err := newModerationService().Report(&gofr.Context{Context: reporterContext("203.0.113.42:5000"), Container: mockContainer},
+			"nope", &model.ReportAbuseRequest{Reason: model.AbusePhishing})
+
+		assert.Equal(t, service.ErrLinkNotFound, err)
+	})
+
+	t.Run("Already Reported", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectLink(mocks, model.URL{ShortCode: "abc123", Original: "https://example.com"})
+		mocks.Mongo.EXPECT().CountDocuments(gomock.Any(), "abuse_reports", bson.M{
+			"short_code": "abc123", "reporter": "203.0.113.0", "resolution": bson.M{"$exists": false},
+		}).Return(int64(1), nil)
+
+		_, err := newModerationService().Report(&gofr.Context{Context: reporterContext("203.0.113.42:5000"), Container: mockContainer},
+			"abc123", &model.ReportAbuseRequest{Reason: model.AbusePhishing})
+
+		assert.Equal(t, service.ErrAlreadyReported, err)
+	})
+
+	t.Run("Too Many Open Reports", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectLink(mocks, model.URL{ShortCode: "abc123", Original: "https://example.com"})
+		mocks.Mongo.EXPECT().CountDocuments(gomock.Any(), "abuse_reports", bson.M{
+			"short_code": "abc123", "reporter": "203.0.113.0", "resolution": bson.M{"$exists": false},
+		}).Return(int64(0), nil)
+		mocks.Mongo.EXPECT().CountDocuments(gomock.Any(), "abuse_reports", bson.M{
+			"reporter": "203.0.113.0", "resolution": bson.M{"$exists": false},
+		}).Return(int64(10), nil)
+
+		_, err := newModerationService().Report(&gofr.Context{Context: reporterContext("203.0.113.42:5000"), Container: mockContainer},
+			"abc123", &model.ReportAbuseRequest{Reason: model.AbusePhishing})
+
+		assert.Equal(t, service.ErrTooManyReports, err)
+	})
+
+	t.Run("Stored", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectLink(mocks, model.URL{ShortCode: "abc123", Original: "https://example.com"})
+		mocks.Mongo.EXPECT().CountDocuments(gomock.Any(), "abuse_reports", gomock.Any()).Return(int64(0), nil).Times(2)
+		mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "abuse_reports", gomock.Any()).Return("id", nil)
+
+		report, err := newModerationService().Report(&gofr.Context{Context: reporterContext("203.0.113.42:5000"), Container: mockContainer},
+			"abc123", &model.ReportAbuseRequest{Reason: model.AbusePhishing, Details: "asks for my bank password"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "abc123", report.ShortCode)
+		assert.Equal(t, model.AbusePhishing, report.Reason)
+		assert.Equal(t, "203.0.113.0", report.Reporter)
+		assert.NotEmpty(t, report.ID)
+	})
+}
+
+func TestModerationServiceQueue(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	created := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "abuse_reports", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.AbuseReport) = []model.AbuseReport{
+				{ID: "r1", ShortCode: "once", Reason: model.AbuseSpam},
+				{ID: "r2", ShortCode: "twice", Reason: model.AbusePhishing},
+				{ID: "r3", ShortCode: "twice", Reason: model.AbuseFraud},
+				{ID: "r4", ShortCode: "gone", Reason: model.AbuseSpam},
+			}
+			return nil
+		})
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "urls",
+		bson.M{"short_code": bson.M{"$in": []string{"once", "twice", "gone"}}}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.URL) = []model.URL{{ShortCode: "once", CreatedAt: created}, {ShortCode: "twice", CreatedAt: created}}
+			return nil
+		})
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "urls", bson.M{
+		"screening.verdict": bson.M{"$in": []string{model.VerdictSuspicious, model.VerdictMalicious}},
+		"moderation":        bson.M{"$exists": false},
+	}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.URL) = []model.URL{
+				{ShortCode: "once", CreatedAt: created},
+				{ShortCode: "flagged", CreatedAt: created.Add(-time.Hour),
+					Screening: &model.Screening{Verdict: model.VerdictSuspicious, Reasons: []string{service.ReasonIPHost}}},
+			}
+			return nil
+		})
+
+	items, err := newModerationService().Queue(&gofr.Context{Context: context.Background(), Container: mockContainer})
+
+	assert.NoError(t, err)
+	if assert.Len(t, items, 3) {
+		assert.Equal(t, "twice", items[0].Link.ShortCode)
+		assert.Len(t, items[0].Reports, 2)
+		assert.Equal(t, "http://sho.rt/twice", items[0].Link.ShortURL)
+		assert.Equal(t, "once", items[1].Link.ShortCode)
+		assert.Equal(t, "flagged", items[2].Link.ShortCode)
+		assert.Empty(t, items[2].Reports)
+		assert.Equal(t, model.VerdictSuspicious, items[2].Link.Screening.Verdict)
+	}
+}
+
+func TestModerationServiceAct(t *testing.T) {
+	t.Run("Invalid Action", func(t *testing.T) {
+		mockContainer, _ := container.NewMockContainer(t)
+		_, err := newModerationService().Act(&gofr.Context{Context: context.Background(), Container: mockContainer},
+			"abc123", &model.ModerationRequest{Action: "ban"})
+
+		assert.Equal(t, gofrHttp.ErrorInvalidParam{Params: []string{"action"}}, err)
+	})
+
+	for _, action := range []string{model.ModerationActionDisable, model.ModerationActionClear} {
+		t.Run(action, func(t *testing.T) {
+			mockContainer, mocks := container.NewMockContainer(t)
+			expectLink(mocks, model.URL{ShortCode: "abc123", Owner: "alice", Original: "https://example.com"})
+			var stored *model.Moderation
+			mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "urls", bson.M{"short_code": "abc123"}, gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, _ any, update any) error {
+					stored = update.(bson.M)["$set"].(bson.M)["moderation"].(*model.Moderation)
+					return nil
+				})
+			mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "abuse_reports",
+				bson.M{"short_code": "abc123", "resolution": bson.M{"$exists": false}}, gomock.Any()).Return(int64(2), nil)
+			mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "audit_log", gomock.Any()).Return("id", nil)
+
+			link, err := newModerationService().Act(&gofr.Context{Context: context.Background(), Container: mockContainer},
+				"abc123", &model.ModerationRequest{Action: action, Note: "reviewed"})
+
+			assert.NoError(t, err)
+			if assert.NotNil(t, stored) {
+				assert.Equal(t, link.Moderation, stored)
+				assert.Equal(t, "reviewed", stored.Note)
+			}
+		})
+	}
+
+	t.Run("Delete", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectLink(mocks, model.URL{ShortCode: "abc123", Owner: "alice", Original: "https://example.com"})
+		mocks.Mongo.EXPECT().DeleteOne(gomock.Any(), "urls", bson.M{"short_code": "abc123"}).Return(int64(1), nil)
+		mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "abuse_reports", gomock.Any(), gomock.Any()).Return(int64(1), nil)
+		mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "audit_log", gomock.Any()).Return("id", nil)
+
+		link, err := newModerationService().Act(&gofr.Context{Context: context.Background(), Container: mockContainer},
+			"abc123", &model.ModerationRequest{Action: model.ModerationActionDelete})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "abc123", link.ShortCode)
+		assert.Nil(t, link.Moderation)
+	})
+
+	t.Run("Delete Already Gone", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		// Anonymous links have no owner; they are deleted by short code alone.
+		expectLink(mocks, model.URL{ShortCode: "abc123", Original: "https://example.com"})
+		mocks.Mongo.EXPECT().DeleteOne(gomock.Any(), "urls", bson.M{"short_code": "abc123"}).Return(int64(0), nil)
+
+		_, err := newModerationService().Act(&gofr.Context{Context: context.Background(), Container: mockContainer},
+			"abc123", &model.ModerationRequest{Action: model.ModerationActionDelete})
+
+		assert.Equal(t, service.ErrLinkNotFound, err)
+	})
+}
+
+func TestURLServiceResolveModeration(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectLink(mocks, model.URL{ShortCode: "abc123", Original: "https://example.com",
+			Moderation: &model.Moderation{Status: model.ModerationDisabled}})
+		svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/")
+
+		_, err := svc.Resolve(&gofr.Context{Context: context.Background(), Container: mockContainer}, "abc123", "", nil)
+
+		assert.Equal(t, service.ErrLinkDisabled, err)
+	})
+
+	t.Run("Cleared Despite Verdict", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectLink(mocks, model.URL{ShortCode: "abc123", Original: "https://evil.example/",
+			Screening:  &model.Screening{Verdict: model.VerdictMalicious},
+			Moderation: &model.Moderation{Status: model.ModerationCleared}})
+		mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "urls", bson.M{"short_code": "abc123"}, gomock.Any()).Return(nil)
+		svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/")
+
+		destination, err := svc.Resolve(&gofr.Context{Context: context.Background(), Container: mockContainer}, "abc123", "", nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "https://evil.example/", destination)
+	})
+}
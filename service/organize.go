@@ -0,0 +1,190 @@
+package service
+
+import (
+	"errors"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+
+	gofrHttp "gofr.dev/pkg/gofr/http"
+
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+// WithFolders lets links be filed into the owner's folders.
+func WithFolders(folders *store.FolderStore) URLOption {
+	return func(s *URLServiceImpl) {
+		s.Folders = folders
+	}
+}
+
+// List returns the caller's links matching filter, or the links of
+// filter.Workspace when the caller is a member.
+func (s *URLServiceImpl) List(ctx *gofr.Context, filter model.URLFilter) ([]model.URL, error) {
+	tags, err := NormalizeTags(filter.Tags)
+	if err != nil {
+		return nil, err
+	}
+	folder, err := NormalizeFolderPath(filter.Folder)
+	if err != nil {
+		return nil, err
+	}
+	if filter.Workspace != "" {
+		if _, err := s.Workspace.Require(ctx, filter.Workspace, model.RoleViewer); err != nil {
+			return nil, err
+		}
+	} else {
+		filter.Owner = middleware.Actor(ctx)
+	}
+	filter.Tags = tags
+	filter.Folder = folder
+
+	links, err := s.Store.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for i := range links {
+		links[i].ShortURL = s.Host + links[i].ShortCode
+	}
+	return links, nil
+}
+
+// ListTags returns every tag used on the caller's links with its usage count.
+func (s *URLServiceImpl) ListTags(ctx *gofr.Context) ([]model.TagCount, error) {
+	links, err := s.Store.Find(ctx, model.URLFilter{Owner: middleware.Actor(ctx)})
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	for _, link := range links {
+		for _, tag := range link.Tags {
+			counts[tag]++
+		}
+	}
+	result := make([]model.TagCount, 0, len(counts))
+	for tag, count := range counts {
+		result = append(result, model.TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Tag < result[j].Tag
+	})
+	return result, nil
+}
+
+func (s *URLServiceImpl) SetTags(ctx *gofr.Context, code string, req *model.SetTagsRequest) (*model.URL, error) {
+	tags, err := NormalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	link, err := s.findOwned(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkIfMatch(ctx, link); err != nil {
+		return nil, err
+	}
+	ok, err := s.Store.SetTags(ctx, link, tags)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, conflict(ctx)
+	}
+	s.Audit.Record(ctx, model.AuditLinkTags, "link:"+code,
+		map[string]any{"tags": link.Tags}, map[string]any{"tags": tags})
+	link.Tags = tags
+	link.ShortURL = s.Host + link.ShortCode
+	s.emit(ctx, model.EventLinkUpdated, link.Owner, link)
+	return link, nil
+}
+
+func (s *URLServiceImpl) Move(ctx *gofr.Context, code string, req *model.MoveRequest) (*model.URL, error) {
+	link, err := s.findOwned(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkIfMatch(ctx, link); err != nil {
+		return nil, err
+	}
+	folder, err := s.checkFolder(ctx, link.Owner, req.Folder)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.Store.SetFolder(ctx, link, folder)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, conflict(ctx)
+	}
+	s.Audit.Record(ctx, model.AuditLinkMove, "link:"+code,
+		map[string]any{"folder": link.Folder}, map[string]any{"folder": folder})
+	link.Folder = folder
+	link.ShortURL = s.Host + link.ShortCode
+	s.emit(ctx, model.EventLinkUpdated, link.Owner, link)
+	return link, nil
+}
+
+func (s *URLServiceImpl) BulkTag(ctx *gofr.Context, req *model.BulkTagRequest) (*model.BulkTagResult, error) {
+	if len(req.ShortCodes) == 0 {
+		return nil, gofrHttp.ErrorMissingParam{Params: []string{"short_codes"}}
+	}
+	add, err := NormalizeTags(req.Add)
+	if err != nil {
+		return nil, err
+	}
+	remove, err := NormalizeTags(req.Remove)
+	if err != nil {
+		return nil, err
+	}
+	actor := middleware.Actor(ctx)
+	if actor == "" {
+		return nil, ErrActorRequired
+	}
+	// The same links SetTags would let the caller change: their own, and
+	// those of the workspaces where they are an editor.
+	editable, err := s.Workspace.Workspaces(ctx, actor, model.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	workspaces := make([]string, 0, len(editable))
+	for id := range editable {
+		workspaces = append(workspaces, id)
+	}
+	sort.Strings(workspaces)
+	updated, err := s.Store.BulkTag(ctx, actor, workspaces, req.ShortCodes, add, remove)
+	if err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, model.AuditLinkBulkTag, "links", nil, map[string]any{
+		"short_codes": req.ShortCodes,
+		"add":         add,
+		"remove":      remove,
+	})
+	return &model.BulkTagResult{Updated: updated}, nil
+}
+
+// checkFolder normalizes raw and verifies the owner has created it.
+func (s *URLServiceImpl) checkFolder(ctx *gofr.Context, owner, raw string) (string, error) {
+	folder, err := NormalizeFolderPath(raw)
+	if err != nil || folder == "" {
+		return folder, err
+	}
+	if s.Folders == nil {
+		return "", ErrNoFolders
+	}
+	_, err = s.Folders.FindByPath(ctx, owner, folder)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "", ErrFolderNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return folder, nil
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+
+	gofrHttp "gofr.dev/pkg/gofr/http"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+var ErrPolicyNotFound = &apierror.Error{Status: http.StatusNotFound, Message: "policy rule not found"}
+
+// PolicyViolation rejects a destination. It names the deny rule the
+// destination matched, or the allow rules it matched none of.
+type PolicyViolation struct {
+	Destination string
+	Denied      *model.PolicyRule
+	Allowed     []model.PolicyRule
+}
+
+func (v *PolicyViolation) Error() string {
+	if v.Denied != nil {
+		return fmt.Sprintf("destination %s is denied by policy rule %s (%s)", v.Destination, v.Denied.ID, describeRule(v.Denied))
+	}
+	allowed := make([]string, len(v.Allowed))
+	for i := range v.Allowed {
+		allowed[i] = fmt.Sprintf("%s (%s)", v.Allowed[i].ID, describeRule(&v.Allowed[i]))
+	}
+	return fmt.Sprintf("destination %s matches none of the allow rules: %s", v.Destination, strings.Join(allowed, ", "))
+}
+
+func (v *PolicyViolation) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+func describeRule(rule *model.PolicyRule) string {
+	scope := "all workspaces"
+	if rule.Workspace != "" {
+		scope = "workspace " + rule.Workspace
+	}
+	description := fmt.Sprintf("%s %s %q, %s", rule.Effect, rule.Match, rule.Pattern, scope)
+	if rule.Description != "" {
+		description += ": " + rule.Description
+	}
+	return description
+}
+
+// PolicyChecker enforces the allow and deny rules of a workspace, together
+// with the global rules, on link destinations. Rules cannot be changed once
+// created, so each is compiled the first time it is loaded.
+type PolicyChecker struct {
+	Store *store.PolicyStore
+
+	mu       sync.Mutex
+	matchers map[string]func(destination string) bool // by rule ID
+}
+
+func NewPolicyChecker(policies *store.PolicyStore) *PolicyChecker {
+	return &PolicyChecker{Store: policies, matchers: make(map[string]func(string) bool)}
+}
+
+// Check returns a *PolicyViolation when destination is not allowed for links
+// of workspace. Deny rules win over allow rules. Allow rules only restrict
+// their own scope: the destination must match one of the global allow rules,
+// if there are any, and one of the workspace's, if there are any.
+func (c *PolicyChecker) Check(ctx *gofr.Context, workspace, destination string) error {
+	rules, err := c.Store.Find(ctx, "", workspace)
+	if err != nil {
+		return err
+	}
+	allowed := make(map[string][]model.PolicyRule)
+	matched := make(map[string]bool)
+	var scopes []string
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Effect != model.PolicyAllow {
+			if c.matches(ctx, rule, destination) {
+				return &PolicyViolation{Destination: destination, Denied: rule}
+			}
+			continue
+		}
+		if _, ok := allowed[rule.Workspace]; !ok {
+			scopes = append(scopes, rule.Workspace)
+		}
+		allowed[rule.Workspace] = append(allowed[rule.Workspace], *rule)
+		if !matched[rule.Workspace] && c.matches(ctx, rule, destination) {
+			matched[rule.Workspace] = true
+		}
+	}
+	for _, scope := range scopes {
+		if !matched[scope] {
+			return &PolicyViolation{Destination: destination, Allowed: allowed[scope]}
+		}
+	}
+	return nil
+}
+
+func (c *PolicyChecker) matches(ctx *gofr.Context, rule *model.PolicyRule, destination string) bool {
+	c.mu.Lock()
+	matcher, ok := c.matchers[rule.ID]
+	if !ok {
+		var err error
+		if matcher, err = compileRule(rule.Match, rule.Pattern); err != nil {
+			// Rules are validated when created, so this is a corrupt document.
+			ctx.Logger.Errorf("policy rule %s: %v", rule.ID, err)
+			matcher = func(string) bool { return false }
+		}
+		c.matchers[rule.ID] = matcher
+	}
+	c.mu.Unlock()
+	return matcher(destination)
+}
+
+// compileRule returns a function reporting whether a destination matches
+// pattern. A regex must match the whole destination URL, with its scheme and
+// host in lower case since they are case-insensitive.
+func compileRule(match, pattern string) (func(destination string) bool, error) {
+	switch match {
+	case model.PolicyMatchDomain:
+		domain := strings.ToLower(strings.Trim(pattern, "."))
+		return func(destination string) bool {
+			host := destinationHost(destination)
+			return host == domain || strings.HasSuffix(host, "."+domain)
+		}, nil
+	case model.PolicyMatchWildcard:
+		quoted := regexp.QuoteMeta(strings.ToLower(pattern))
+		re := regexp.MustCompile("^" + strings.ReplaceAll(quoted, `\*`, ".*") + "$")
+		return func(destination string) bool {
+			return re.MatchString(destinationHost(destination))
+		}, nil
+	case model.PolicyMatchRegex:
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, err
+		}
+		re := regexp.MustCompile(`^(?:` + pattern + `)$`)
+		return func(destination string) bool {
+			return re.MatchString(lowerSchemeAndHost(destination))
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown match %q", match)
+	}
+}
+
+// lowerSchemeAndHost returns destination with its scheme and host in lower
+// case, leaving the rest as it was written.
+func lowerSchemeAndHost(destination string) string {
+	scheme, rest, ok := strings.Cut(destination, "://")
+	if !ok {
+		return destination
+	}
+	end := strings.IndexAny(rest, "/?#")
+	if end < 0 {
+		end = len(rest)
+	}
+	return strings.ToLower(scheme) + "://" + strings.ToLower(rest[:end]) + rest[end:]
+}
+
+func destinationHost(destination string) string {
+	parsed, err := url.Parse(destination)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+}
+
+type PolicyService interface {
+	Create(ctx *gofr.Context, req *model.CreatePolicyRuleRequest) (*model.PolicyRule, error)
+	List(ctx *gofr.Context, workspace string) ([]model.PolicyRule, error)
+	Delete(ctx *gofr.Context, id string) error
+}
+
+type PolicyServiceImpl struct {
+	Store *store.PolicyStore
+	Audit *Auditor
+}
+
+func NewPolicyService(policies *store.PolicyStore, auditor *Auditor) PolicyService {
+	return &PolicyServiceImpl{Store: policies, Audit: auditor}
+}
+
+func (s *PolicyServiceImpl) Create(ctx *gofr.Context, req *model.CreatePolicyRuleRequest) (*model.PolicyRule, error) {
+	if req.Effect != model.PolicyAllow && req.Effect != model.PolicyDeny {
+		return nil, gofrHttp.ErrorInvalidParam{Params: []string{"effect"}}
+	}
+	pattern := strings.TrimSpace(req.Pattern)
+	if pattern == "" {
+		return nil, gofrHttp.ErrorInvalidParam{Params: []string{"pattern"}}
+	}
+	if req.Match != model.PolicyMatchDomain && req.Match != model.PolicyMatchWildcard && req.Match != model.PolicyMatchRegex {
+		return nil, gofrHttp.ErrorInvalidParam{Params: []string{"match"}}
+	}
+	if _, err := compileRule(req.Match, pattern); err != nil {
+		return nil, &apierror.Error{Status: http.StatusBadRequest, Message: "invalid pattern: " + err.Error()}
+	}
+	rule := &model.PolicyRule{
+		Workspace:   req.Workspace,
+		Effect:      req.Effect,
+		Match:       req.Match,
+		Pattern:     pattern,
+		Description: req.Description,
+	}
+	if err := s.Store.Insert(ctx, rule); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, model.AuditPolicyCreate, "policy:"+rule.ID, nil, rule)
+	return rule, nil
+}
+
+// List returns the rules of workspace, or every rule when workspace is empty.
+func (s *PolicyServiceImpl) List(ctx *gofr.Context, workspace string) ([]model.PolicyRule, error) {
+	if workspace == "" {
+		return s.Store.Find(ctx)
+	}
+	return s.Store.Find(ctx, workspace)
+}
+
+func (s *PolicyServiceImpl) Delete(ctx *gofr.Context, id string) error {
+	rule, err := s.Store.FindByID(ctx, id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrPolicyNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.Store.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, model.AuditPolicyDelete, "policy:"+id, rule, nil)
+	return nil
+}
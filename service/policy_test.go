@@ -0,0 +1,188 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	gofrHttp "gofr.dev/pkg/gofr/http"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+// expectPolicies serves rules as the global and workspace rules of every check.
+func expectPolicies(mocks *container.Mocks, workspace string, rules ...model.PolicyRule) {
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "policies", bson.M{"workspace": bson.M{"$in": []string{"", workspace}}}, gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.PolicyRule) = rules
+			return nil
+		})
+}
+
+func TestPolicyCheckerCheck(t *testing.T) {
+	corp := []model.PolicyRule{
+		{ID: "allow-corp", Workspace: "acme", Effect: model.PolicyAllow, Match: model.PolicyMatchDomain, Pattern: "acme.example"},
+		{ID: "allow-cdn", Workspace: "acme", Effect: model.PolicyAllow, Match: model.PolicyMatchWildcard, Pattern: "cdn-*.partner.net"},
+		{ID: "deny-hr", Workspace: "acme", Effect: model.PolicyDeny, Match: model.PolicyMatchRegex, Pattern: `https://hr\.acme\.example/private/.*`},
+		{ID: "deny-bad", Effect: model.PolicyDeny, Match: model.PolicyMatchDomain, Pattern: "bad.example",
+			Description: "known spam host"},
+	}
+
+	tests := []struct {
+		name        string
+		rules       []model.PolicyRule
+		destination string
+		denied      string
+		allowMiss   bool
+	}{
+		{name: "No Rules", destination: "https://anything.example/"},
+		{name: "Global Deny", rules: corp[3:], destination: "https://www.bad.example/x", denied: "deny-bad"},
+		{name: "Allowed Domain", rules: corp, destination: "https://acme.example/"},
+		{name: "Allowed Subdomain", rules: corp, destination: "https://docs.ACME.example/guide"},
+		{name: "Lookalike Domain", rules: corp, destination: "https://notacme.example/", allowMiss: true},
+		{name: "Allowed Wildcard", rules: corp, destination: "https://cdn-eu.partner.net/file"},
+		{name: "Wildcard Mismatch", rules: corp, destination: "https://www.partner.net/", allowMiss: true},
+		{name: "Deny Wins", rules: corp, destination: "https://hr.acme.example/private/salaries", denied: "deny-hr"},
+		{name: "Regex Ignores Host Case", rules: corp, destination: "HTTPS://HR.Acme.example/private/salaries", denied: "deny-hr"},
+		{name: "Regex Keeps Path Case", rules: corp, destination: "https://hr.acme.example/Private/salaries"},
+		{name: "Regex Matches Whole URL", rules: corp, destination: "https://acme.example/?next=https://hr.acme.example/private/x"},
+		{name: "Global Allow Does Not Widen Workspace", rules: append([]model.PolicyRule{
+			{ID: "allow-any-partner", Effect: model.PolicyAllow, Match: model.PolicyMatchWildcard, Pattern: "*.partner.net"},
+		}, corp...), destination: "https://www.partner.net/", allowMiss: true},
+		{name: "Workspace Allow Does Not Widen Global", rules: append([]model.PolicyRule{
+			{ID: "allow-only-partner", Effect: model.PolicyAllow, Match: model.PolicyMatchDomain, Pattern: "partner.net"},
+		}, corp...), destination: "https://acme.example/", allowMiss: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContainer, mocks := container.NewMockContainer(t)
+			expectPolicies(mocks, "acme", tt.rules...)
+
+			err := service.NewPolicyChecker(store.NewPolicyStore()).
+				Check(&gofr.Context{Context: context.Background(), Container: mockContainer}, "acme", tt.destination)
+
+			if tt.denied == "" && !tt.allowMiss {
+				assert.NoError(t, err)
+				return
+			}
+			violation, ok := err.(*service.PolicyViolation)
+			if !assert.True(t, ok, "Expected a policy violation, got %v", err) {
+				return
+			}
+			assert.Equal(t, http.StatusBadRequest, violation.StatusCode())
+			if tt.denied != "" {
+				assert.Equal(t, tt.denied, violation.Denied.ID)
+				assert.Contains(t, violation.Error(), tt.denied)
+				return
+			}
+			if violation.Allowed[0].Workspace == "" {
+				assert.Len(t, violation.Allowed, 1)
+				return
+			}
+			assert.Len(t, violation.Allowed, 2)
+			assert.Contains(t, violation.Error(), `allow domain "acme.example", workspace acme`)
+		})
+	}
+}
+
+func TestPolicyServiceCreate(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         model.CreatePolicyRuleRequest
+		expectedErr error
+	}{
+		{name: "Invalid Effect", req: model.CreatePolicyRuleRequest{Effect: "block", Match: model.PolicyMatchDomain, Pattern: "x.example"},
+			expectedErr: gofrHttp.ErrorInvalidParam{Params: []string{"effect"}}},
+		{name: "Missing Pattern", req: model.CreatePolicyRuleRequest{Effect: model.PolicyDeny, Match: model.PolicyMatchDomain},
+			expectedErr: gofrHttp.ErrorInvalidParam{Params: []string{"pattern"}}},
+		{name: "Invalid Match", req: model.CreatePolicyRuleRequest{Effect: model.PolicyDeny, Match: "glob", Pattern: "*"},
+			expectedErr: gofrHttp.ErrorInvalidParam{Params: []string{"match"}}},
+		{name: "Created", req: model.CreatePolicyRuleRequest{Workspace: "acme", Effect: model.PolicyAllow,
+			Match: model.PolicyMatchRegex, Pattern: `^https://([a-z]+\.)?acme\.example/`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContainer, mocks := container.NewMockContainer(t)
+			if tt.expectedErr == nil {
+				mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "policies", gomock.Any()).Return("id", nil)
+			}
+
+			rule, err := service.NewPolicyService(store.NewPolicyStore(), nil).
+				Create(&gofr.Context{Context: context.Background(), Container: mockContainer}, &tt.req)
+
+			assert.Equal(t, tt.expectedErr, err)
+			if tt.expectedErr == nil {
+				assert.NotEmpty(t, rule.ID)
+				assert.Equal(t, "acme", rule.Workspace)
+			}
+		})
+	}
+
+	t.Run("Invalid Regex", func(t *testing.T) {
+		mockContainer, _ := container.NewMockContainer(t)
+		_, err := service.NewPolicyService(store.NewPolicyStore(), nil).
+			Create(&gofr.Context{Context: context.Background(), Container: mockContainer},
+				&model.CreatePolicyRuleRequest{Effect: model.PolicyDeny, Match: model.PolicyMatchRegex, Pattern: "(unclosed"})
+
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "invalid pattern")
+		}
+	})
+}
+
+func TestURLServicePolicies(t *testing.T) {
+	rules := []model.PolicyRule{
+		{ID: "allow-corp", Workspace: "alice", Effect: model.PolicyAllow, Match: model.PolicyMatchDomain, Pattern: "acme.example"},
+	}
+
+	t.Run("Create Rejected", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectPolicies(mocks, "alice", rules...)
+		svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/",
+			service.WithPolicies(service.NewPolicyChecker(store.NewPolicyStore())))
+
+		_, err := svc.Create(&gofr.Context{Context: actorContext("alice"), Container: mockContainer},
+			&model.CreateURLRequest{OriginalURL: "https://example.com/"})
+
+		assert.IsType(t, &service.PolicyViolation{}, err)
+	})
+
+	t.Run("Update Rejected", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectLink(mocks, model.URL{ShortCode: "abc123", Owner: "alice", Original: "https://acme.example/", Revision: 1})
+		expectPolicies(mocks, "alice", rules...)
+		svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/",
+			service.WithPolicies(service.NewPolicyChecker(store.NewPolicyStore())))
+
+		destination := "https://example.com/"
+		_, err := svc.Update(&gofr.Context{Context: actorContext("alice"), Container: mockContainer}, "abc123",
+			&model.UpdateURLRequest{OriginalURL: &destination})
+
+		assert.IsType(t, &service.PolicyViolation{}, err)
+	})
+
+	t.Run("Chained Link Judged By Its Target", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectLink(mocks, model.URL{ShortCode: "hop", Owner: "bob", Original: "https://example.com/"})
+		expectPolicies(mocks, "alice", rules...)
+		svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/",
+			service.WithChainGuard(service.NewChainGuard(store.NewURLStore(), []string{"http://sho.rt/"}, nil, 1, false)),
+			service.WithPolicies(service.NewPolicyChecker(store.NewPolicyStore())))
+
+		_, err := svc.Create(&gofr.Context{Context: actorContext("alice"), Container: mockContainer},
+			&model.CreateURLRequest{OriginalURL: "https://sho.rt/hop"})
+
+		assert.IsType(t, &service.PolicyViolation{}, err)
+	})
+}
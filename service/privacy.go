@@ -0,0 +1,107 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+// IP anonymization modes.
+const (
+	IPTruncate = "truncate" // keep the /24 of IPv4 and the /48 of IPv6 addresses
+	IPHash     = "hash"     // keep a salted hash; the salt rotates and old salts are forgotten
+	IPDrop     = "drop"     // keep nothing
+)
+
+// IPAnonymizer makes client IPs safe to store with clicks.
+type IPAnonymizer struct {
+	Mode     string
+	Rotation time.Duration // lifetime of a hashing salt
+
+	mu     sync.Mutex
+	salt   []byte
+	period int64
+}
+
+// NewIPAnonymizer returns an anonymizer for mode, which defaults to IPTruncate.
+func NewIPAnonymizer(mode string, rotation time.Duration) (*IPAnonymizer, error) {
+	switch mode {
+	case "":
+		mode = IPTruncate
+	case IPTruncate, IPHash, IPDrop:
+	default:
+		return nil, fmt.Errorf("unknown IP anonymization mode %q", mode)
+	}
+	if rotation <= 0 {
+		rotation = 24 * time.Hour
+	}
+	return &IPAnonymizer{Mode: mode, Rotation: rotation}, nil
+}
+
+// Anonymize returns what may be stored of ip for a click at t.
+func (a *IPAnonymizer) Anonymize(ip string, t time.Time) string {
+	switch a.Mode {
+	case IPHash:
+		if ip == "" {
+			return ""
+		}
+		sum := sha256.Sum256(append(a.saltAt(t), ip...))
+		return hex.EncodeToString(sum[:16])
+	case IPDrop:
+		return ""
+	default:
+		return truncateIP(ip)
+	}
+}
+
+// saltAt returns the salt of the rotation period holding t. Salts live in
+// memory only, so hashes from different periods or instances cannot be linked.
+func (a *IPAnonymizer) saltAt(t time.Time) []byte {
+	period := t.UnixNano() / int64(a.Rotation)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.salt == nil || period != a.period {
+		a.salt = make([]byte, 32)
+		_, _ = rand.Read(a.salt)
+		a.period = period
+	}
+	return a.salt
+}
+
+func truncateIP(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return parsed.Mask(net.CIDRMask(48, 128)).String()
+}
+
+// trackingRefused reports whether the visitor asked not to be tracked, with
+// Do Not Track or Global Privacy Control.
+func trackingRefused(meta middleware.RequestMeta) bool {
+	return meta.Header.Get("DNT") == "1" || meta.Header.Get("Sec-GPC") == "1"
+}
+
+// anonymize strips everything from click that could identify the visitor,
+// keeping only what is needed to count it.
+func anonymize(click *model.Click) {
+	*click = model.Click{
+		ShortCode:   click.ShortCode,
+		Owner:       click.Owner,
+		CampaignID:  click.CampaignID,
+		Destination: click.Destination,
+		Bot:         click.Bot,
+		Anonymous:   true,
+		Timestamp:   click.Timestamp,
+	}
+}
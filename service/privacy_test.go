@@ -0,0 +1,260 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+func TestIPAnonymizer(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	truncate, err := service.NewIPAnonymizer("", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "203.0.113.0", truncate.Anonymize("203.0.113.42", now))
+	assert.Equal(t, "2001:db8:85a3::", truncate.Anonymize("2001:db8:85a3:8d3:1319:8a2e:370:7348", now))
+	assert.Empty(t, truncate.Anonymize("not an ip", now))
+
+	drop, err := service.NewIPAnonymizer(service.IPDrop, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, drop.Anonymize("203.0.113.42", now))
+
+	hash, err := service.NewIPAnonymizer(service.IPHash, time.Hour)
+	assert.NoError(t, err)
+	first := hash.Anonymize("203.0.113.42", now)
+	assert.Len(t, first, 32)
+	assert.Equal(t, first, hash.Anonymize("203.0.113.42", now.Add(time.Minute)))
+	assert.NotEqual(t, first, hash.Anonymize("203.0.113.43", now.Add(time.Minute)))
+	// Once the salt rotates the same address can no longer be linked.
+	assert.NotEqual(t, first, hash.Anonymize("203.0.113.42", now.Add(time.Hour)))
+
+	_, err = service.NewIPAnonymizer("keep", 0)
+	assert.Error(t, err)
+}
+
+func TestURLServiceResolvePrivacy(t *testing.T) {
+	tests := []struct {
+		name       string
+		noTracking bool
+		header     http.Header
+		anonymous  bool
+	}{
+		{name: "Tracked", header: http.Header{}},
+		{name: "Do Not Track", header: http.Header{"Dnt": {"1"}}, anonymous: true},
+		{name: "Global Privacy Control", header: http.Header{"Sec-Gpc": {"1"}}, anonymous: true},
+		{name: "Link Opted Out", noTracking: true, header: http.Header{}, anonymous: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContainer, mocks := container.NewMockContainer(t)
+			mocks.Metrics.EXPECT().SetGauge(gomock.Any(), gomock.Any()).AnyTimes()
+			mocks.Metrics.EXPECT().IncrementCounter(gomock.Any(), gomock.Any()).AnyTimes()
+			expectLink(mocks, model.URL{ShortCode: "abc123", Owner: "alice", Original: "https://example.com", NoTracking: tt.noTracking})
+			var stored model.Click
+			mocks.Mongo.EXPECT().InsertMany(gomock.Any(), "clicks", gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, documents []any) ([]any, error) {
+					stored = documents[0].(model.Click)
+					return nil, nil
+				})
+			mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "urls", bson.M{"short_code": "abc123"}, gomock.Any()).Return(nil)
+
+			ingester := newIngester(1, 1)
+			anonymizer, _ := service.NewIPAnonymizer(service.IPTruncate, 0)
+			svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/",
+				service.WithClicks(ingester), service.WithPrivacy(anonymizer))
+
+			tt.header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/124.0.0.0")
+			tt.header.Set("Referer", "https://news.example.com/story")
+			meta := middleware.RequestMeta{Method: http.MethodGet, Header: tt.header, RemoteAddr: "203.0.113.42:51000"}
+			ctx := &gofr.Context{Context: middleware.WithRequestMeta(context.Background(), meta), Container: mockContainer}
+			destination, err := svc.Resolve(ctx, "abc123", "", nil)
+			ingester.Start(1)
+			ingester.Stop()
+
+			assert.NoError(t, err)
+			assert.Equal(t, "https://example.com", destination)
+			assert.Equal(t, tt.anonymous, stored.Anonymous)
+			if tt.anonymous {
+				assert.Empty(t, stored.IP)
+				assert.Empty(t, stored.UserAgent)
+				assert.Empty(t, stored.Referrer)
+				assert.Empty(t, stored.Browser)
+			} else {
+				assert.Equal(t, "203.0.113.0", stored.IP)
+				assert.Equal(t, "Chrome", stored.Browser)
+			}
+			assert.Equal(t, "abc123", stored.ShortCode)
+		})
+	}
+}
+
+func TestAnonymousClickRollups(t *testing.T) {
+	clicked := time.Date(2024, 5, 1, 12, 34, 56, 0, time.UTC)
+	documents := ingestRollups(t, []model.Click{
+		{ShortCode: "abc123", Owner: "alice", Anonymous: true, Timestamp: clicked},
+		{ShortCode: "abc123", Owner: "alice", Anonymous: true, Timestamp: clicked},
+	})
+
+	daily := documents[model.GranularityDay]
+	assert.Equal(t, int64(2), daily.Clicks)
+	assert.Empty(t, daily.Visitors)
+	assert.Empty(t, daily.Dimensions)
+}
+
+func TestErasureServiceErase(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	svc := service.NewErasureService(store.NewURLStore(), store.NewClickStore(), store.NewRollupStore(),
+		store.NewRevisionStore(), store.NewFolderStore(), store.NewWebhookStore(), store.NewReportStore(),
+		store.NewWorkspaceStore(), store.NewAbuseReportStore(), store.NewQuotaStore(), store.NewIdempotencyStore(),
+		store.NewAuditStore(), service.NewAuditor(store.NewAuditStore()))
+	ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+
+	_, err := svc.Erase(ctx, "")
+	assert.Equal(t, http.StatusBadRequest, statusOf(err))
+
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "workspace_members", bson.M{"user_id": "alice"}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.Membership) = []model.Membership{
+				{WorkspaceID: "ws1", UserID: "alice", Role: model.RoleOwner},
+				{WorkspaceID: "ws2", UserID: "alice", Role: model.RoleEditor},
+			}
+			return nil
+		})
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "workspaces", bson.M{"_id": bson.M{"$in": []string{"ws1"}}}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.Workspace) = []model.Workspace{{ID: "ws1", Owners: 2}}
+			return nil
+		})
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "urls", bson.M{"owner": "alice"}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.URL) = []model.URL{{ShortCode: "abc123"}, {ShortCode: "xyz789"}, {ShortCode: "team01", Workspace: "ws2"}}
+			return nil
+		})
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "workspace_members", bson.M{"workspace_id": "ws2"}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.Membership) = []model.Membership{
+				{WorkspaceID: "ws2", UserID: "alice", Role: model.RoleEditor},
+				{WorkspaceID: "ws2", UserID: "olivia", Role: model.RoleOwner},
+			}
+			return nil
+		})
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "folders", bson.M{"owner": "alice"}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.Folder) = []model.Folder{{ID: "f1"}}
+			return nil
+		})
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "webhooks", bson.M{"owner": "alice"}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.Webhook) = []model.Webhook{{ID: "w1"}}
+			return nil
+		})
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "reports", bson.M{"owner": "alice"}, gomock.Any()).Return(nil)
+
+	owned := bson.M{"owner": "alice"}
+	handed := bson.M{"short_code": bson.M{"$in": []string{"team01"}}}
+	toOlivia := bson.M{"$set": bson.M{"owner": "olivia"}}
+	gomock.InOrder(
+		mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "clicks", handed, toOlivia).Return(int64(40), nil),
+		mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "click_rollups", handed, toOlivia).Return(int64(8), nil),
+		mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "urls", handed, toOlivia).Return(int64(1), nil),
+		mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "workspaces",
+			bson.M{"_id": "ws1", "owners": bson.M{"$gt": 1}}, bson.M{"$inc": bson.M{"owners": -1}}).Return(int64(1), nil),
+		mocks.Mongo.EXPECT().DeleteOne(gomock.Any(), "workspace_members",
+			bson.M{"workspace_id": "ws1", "user_id": "alice"}).Return(int64(1), nil),
+		mocks.Mongo.EXPECT().DeleteMany(gomock.Any(), "clicks", owned).Return(int64(120), nil),
+		mocks.Mongo.EXPECT().DeleteMany(gomock.Any(), "click_rollups", owned).Return(int64(30), nil),
+		mocks.Mongo.EXPECT().DeleteMany(gomock.Any(), "revisions",
+			bson.M{"short_code": bson.M{"$in": []string{"abc123", "xyz789"}}}).Return(int64(3), nil),
+		mocks.Mongo.EXPECT().DeleteMany(gomock.Any(), "abuse_reports",
+			bson.M{"short_code": bson.M{"$in": []string{"abc123", "xyz789"}}}).Return(int64(1), nil),
+		mocks.Mongo.EXPECT().DeleteMany(gomock.Any(), "workspace_invitations",
+			bson.M{"$or": bson.A{bson.M{"user_id": "alice"}, bson.M{"accepted_by": "alice"}}}).Return(int64(1), nil),
+		mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "revisions", bson.M{"changed_by": "alice"},
+			bson.M{"$set": bson.M{"changed_by": model.ErasedUser}}).Return(int64(2), nil),
+		mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "workspaces", bson.M{"created_by": "alice"},
+			bson.M{"$set": bson.M{"created_by": model.ErasedUser}}).Return(int64(1), nil),
+		mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "workspace_invitations", bson.M{"invited_by": "alice"},
+			bson.M{"$set": bson.M{"invited_by": model.ErasedUser}}).Return(int64(1), nil),
+		mocks.Mongo.EXPECT().DeleteMany(gomock.Any(), "idempotency_keys", bson.M{"link.owner": "alice"}).Return(int64(4), nil),
+		mocks.Mongo.EXPECT().DeleteMany(gomock.Any(), "webhook_deliveries",
+			bson.M{"webhook_id": bson.M{"$in": []string{"w1"}}}).Return(int64(7), nil),
+		mocks.Mongo.EXPECT().DeleteMany(gomock.Any(), "webhooks", owned).Return(int64(1), nil),
+		mocks.Mongo.EXPECT().DeleteMany(gomock.Any(), "folders", owned).Return(int64(1), nil),
+		mocks.Mongo.EXPECT().DeleteMany(gomock.Any(), "reports", owned).Return(int64(0), nil),
+		mocks.Mongo.EXPECT().DeleteMany(gomock.Any(), "workspace_members", bson.M{"user_id": "alice"}).Return(int64(1), nil),
+		mocks.Mongo.EXPECT().DeleteOne(gomock.Any(), "subscriptions", bson.M{"_id": "alice"}).Return(int64(1), nil),
+		mocks.Mongo.EXPECT().DeleteMany(gomock.Any(), "usage", bson.M{"account": "alice"}).Return(int64(3), nil),
+		mocks.Mongo.EXPECT().DeleteMany(gomock.Any(), "audit_log", bson.M{"$or": bson.A{
+			bson.M{"actor": "alice"},
+			bson.M{"resource": bson.M{"$in": []string{"link:abc123", "link:xyz789", "folder:f1", "webhook:w1"}}},
+		}}).Return(int64(9), nil),
+		mocks.Mongo.EXPECT().DeleteMany(gomock.Any(), "urls",
+			bson.M{"owner": "alice", "workspace": bson.M{"$exists": false}}).Return(int64(2), nil),
+	)
+	mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "audit_log", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, document any) (any, error) {
+			entry := document.(*model.AuditEntry)
+			assert.Equal(t, model.AuditUserErase, entry.Action)
+			assert.Equal(t, "user:alice", entry.Resource)
+			return entry.ID, nil
+		})
+
+	report, err := svc.Erase(ctx, "alice")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", report.UserID)
+	assert.Equal(t, int64(2), report.Links)
+	assert.Equal(t, int64(1), report.LinksHandedOver)
+	assert.Equal(t, int64(120), report.Clicks)
+	assert.Equal(t, int64(30), report.Rollups)
+	assert.Equal(t, int64(3), report.Revisions)
+	assert.Equal(t, int64(1), report.Folders)
+	assert.Equal(t, int64(1), report.Webhooks)
+	assert.Equal(t, int64(7), report.WebhookDeliveries)
+	assert.Equal(t, int64(0), report.Reports)
+	assert.Equal(t, int64(2), report.Memberships)
+	assert.Equal(t, int64(1), report.AbuseReports)
+	assert.Equal(t, int64(1), report.Invitations)
+	assert.Equal(t, int64(4), report.Pseudonymized)
+	assert.Equal(t, int64(1), report.Subscriptions)
+	assert.Equal(t, int64(3), report.Usage)
+	assert.Equal(t, int64(4), report.IdempotencyKeys)
+	assert.Equal(t, int64(9), report.AuditEntries)
+	assert.False(t, report.ErasedAt.IsZero())
+}
+
+func TestErasureServiceKeepsLastOwner(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	svc := service.NewErasureService(store.NewURLStore(), store.NewClickStore(), store.NewRollupStore(),
+		store.NewRevisionStore(), store.NewFolderStore(), store.NewWebhookStore(), store.NewReportStore(),
+		store.NewWorkspaceStore(), store.NewAbuseReportStore(), store.NewQuotaStore(), store.NewIdempotencyStore(),
+		store.NewAuditStore(), service.NewAuditor(store.NewAuditStore()))
+	ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "workspace_members", bson.M{"user_id": "alice"}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.Membership) = []model.Membership{{WorkspaceID: "ws1", UserID: "alice", Role: model.RoleOwner}}
+			return nil
+		})
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "workspaces", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.Workspace) = []model.Workspace{{ID: "ws1", Owners: 1}}
+			return nil
+		})
+
+	_, err := svc.Erase(ctx, "alice")
+
+	assert.Equal(t, service.ErrErasureLastOwner, err)
+}
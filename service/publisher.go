@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+// Publisher is the subset of GoFr's pub/sub publisher the service uses.
+type Publisher interface {
+	Publish(ctx context.Context, topic string, message []byte) error
+}
+
+// EventPublisher publishes link events to a pub/sub topic on background
+// workers, so requests never wait on the broker. When Publisher is nil the
+// publisher configured on the GoFr container (PUBSUB_BACKEND) is used.
+// Events arriving while the queue is full are dropped and logged.
+type EventPublisher struct {
+	Publisher Publisher
+	Topic     string
+
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan publishJob
+	workers sync.WaitGroup
+}
+
+// publishJob carries the app container because events are published outside any request.
+type publishJob struct {
+	container *container.Container
+	event     model.Event
+}
+
+func NewEventPublisher(publisher Publisher, topic string, queueSize int) *EventPublisher {
+	return &EventPublisher{Publisher: publisher, Topic: topic, queue: make(chan publishJob, queueSize)}
+}
+
+// Start launches the publishing workers.
+func (p *EventPublisher) Start(workers int) {
+	for range workers {
+		p.workers.Add(1)
+		go func() {
+			defer p.workers.Done()
+			for job := range p.queue {
+				p.publish(&gofr.Context{Context: context.Background(), Container: job.container}, job.event)
+			}
+		}()
+	}
+}
+
+// Stop stops accepting events and waits until the queued ones are published.
+func (p *EventPublisher) Stop() {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+	p.workers.Wait()
+}
+
+// Notify queues event without blocking. Publishing is best effort: a broker
+// outage must not fail the link operation.
+func (p *EventPublisher) Notify(ctx *gofr.Context, event model.Event) {
+	p.mu.RLock()
+	accepted := false
+	if !p.closed {
+		select {
+		case p.queue <- publishJob{container: ctx.Container, event: event}:
+			accepted = true
+		default:
+		}
+	}
+	p.mu.RUnlock()
+	if !accepted {
+		ctx.Logger.Errorf("event queue full, dropping event %s %s", event.Type, event.ID)
+	}
+}
+
+// publish sends event wrapped in a versioned envelope.
+func (p *EventPublisher) publish(ctx *gofr.Context, event model.Event) {
+	publisher := p.Publisher
+	if publisher == nil {
+		publisher = ctx.GetPublisher()
+	}
+	if publisher == nil {
+		ctx.Logger.Errorf("no pub/sub publisher configured, dropping event %s %s", event.Type, event.ID)
+		return
+	}
+	message, err := json.Marshal(model.EventEnvelope{SchemaVersion: model.EventSchemaVersion, Event: event})
+	if err != nil {
+		ctx.Logger.Errorf("encoding event %s: %v", event.ID, err)
+		return
+	}
+	if err := publisher.Publish(ctx, p.Topic, message); err != nil {
+		ctx.Logger.Errorf("publishing event %s to %s: %v", event.ID, p.Topic, err)
+	}
+}
+
+// MemoryPublisher is an in-process stand-in for a message broker. It keeps the
+// most recent messages per topic so events can be inspected without Kafka,
+// MQTT or NATS running.
+type MemoryPublisher struct {
+	mu       sync.Mutex
+	limit    int
+	messages map[string][][]byte
+}
+
+func NewMemoryPublisher(limit int) *MemoryPublisher {
+	return &MemoryPublisher{limit: limit, messages: make(map[string][][]byte)}
+}
+
+func (m *MemoryPublisher) Publish(_ context.Context, topic string, message []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	messages := append(m.messages[topic], message)
+	if len(messages) > m.limit {
+		messages = messages[len(messages)-m.limit:]
+	}
+	m.messages[topic] = messages
+	return nil
+}
+
+// Messages returns a copy of the messages retained for topic, oldest first.
+func (m *MemoryPublisher) Messages(topic string) [][]byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([][]byte(nil), m.messages[topic]...)
+}
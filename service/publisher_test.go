@@ -0,0 +1,104 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+func TestEventPublisherNotify(t *testing.T) {
+	mockContainer, _ := container.NewMockContainer(t)
+	memory := service.NewMemoryPublisher(10)
+	publisher := service.NewEventPublisher(memory, "link-events", 10)
+	publisher.Start(1)
+	occurred := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+	publisher.Notify(ctx, model.Event{
+		ID:         "e1",
+		Type:       model.EventLinkClicked,
+		Owner:      "alice",
+		OccurredAt: occurred,
+		Data:       model.ClickData{ShortCode: "abc123", Destination: "https://example.com"},
+	})
+	publisher.Stop()
+
+	messages := memory.Messages("link-events")
+	assert.Len(t, messages, 1)
+
+	var got map[string]any
+	assert.NoError(t, json.Unmarshal(messages[0], &got))
+	assert.Equal(t, float64(model.EventSchemaVersion), got["schema_version"])
+	assert.Equal(t, "e1", got["id"])
+	assert.Equal(t, model.EventLinkClicked, got["type"])
+	assert.Equal(t, "2024-05-01T12:00:00Z", got["occurred_at"])
+	assert.Equal(t, map[string]any{"short_code": "abc123", "destination": "https://example.com"}, got["data"])
+	assert.NotContains(t, got, "owner")
+}
+
+func TestMemoryPublisherLimit(t *testing.T) {
+	memory := service.NewMemoryPublisher(2)
+	for _, message := range []string{"a", "b", "c"} {
+		assert.NoError(t, memory.Publish(context.Background(), "topic", []byte(message)))
+	}
+
+	assert.Equal(t, [][]byte{[]byte("b"), []byte("c")}, memory.Messages("topic"))
+	assert.Empty(t, memory.Messages("other"))
+}
+
+func TestURLServiceDelete(t *testing.T) {
+	tests := []struct {
+		name          string
+		actor         string
+		expectDelete  bool
+		expectedError error
+	}{
+		{name: "Success", actor: "alice", expectDelete: true},
+		{name: "Failure - Not Owner", actor: "bob", expectedError: mongo.ErrNoDocuments},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContainer, mocks := container.NewMockContainer(t)
+			memory := service.NewMemoryPublisher(10)
+			publisher := service.NewEventPublisher(memory, "link-events", 10)
+			publisher.Start(1)
+			svc := service.NewURLService(store.NewURLStore(), "http://localhost:8000/",
+				service.WithListener(publisher))
+
+			expectLink(mocks, model.URL{ShortCode: "abc123", Original: "https://example.com", Owner: "alice"})
+			if tt.expectDelete {
+				mocks.Mongo.EXPECT().DeleteOne(gomock.Any(), "urls", bson.M{"short_code": "abc123"}).
+					Return(int64(1), nil)
+			}
+
+			ctx := &gofr.Context{Context: actorContext(tt.actor), Container: mockContainer}
+			err := svc.Delete(ctx, "abc123")
+			publisher.Stop()
+
+			assert.Equal(t, tt.expectedError, err)
+			if !tt.expectDelete {
+				assert.Empty(t, memory.Messages("link-events"))
+				return
+			}
+
+			messages := memory.Messages("link-events")
+			assert.Len(t, messages, 1)
+			var envelope model.EventEnvelope
+			assert.NoError(t, json.Unmarshal(messages[0], &envelope))
+			assert.Equal(t, model.EventLinkDeleted, envelope.Type)
+		})
+	}
+}
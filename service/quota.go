@@ -0,0 +1,546 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	gofrHttp "gofr.dev/pkg/gofr/http"
+
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+// DefaultPlans are offered when PLANS is not set.
+var DefaultPlans = []model.Plan{
+	{Name: "free", Links: 100, TrackedClicks: 10_000, APICalls: 10_000},
+	{Name: "pro", Links: 5_000, TrackedClicks: 500_000, APICalls: 500_000},
+	{Name: "business", TrackedClicks: 5_000_000, APICalls: 5_000_000},
+}
+
+// unlimitedPlan applies to accounts without a subscription when there is no default plan.
+var unlimitedPlan = model.Plan{Name: "unlimited"}
+
+// AnonymousAccount is the account of the links made without signing in. It
+// can be given a plan like any other account to limit how many there are.
+const AnonymousAccount = "anonymous"
+
+// linkAccount returns the account a new link counts against.
+func linkAccount(owner, workspace string) string {
+	if account := workspaceOf(owner, workspace); account != "" {
+		return account
+	}
+	return AnonymousAccount
+}
+
+// clickAccount returns the account the clicks on link count against. The
+// clicks on each link made without signing in are metered on their own, so
+// that one busy link cannot use up the clicks of all the others.
+func clickAccount(link *model.URL) string {
+	if account := workspaceOf(link.Owner, link.Workspace); account != "" {
+		return account
+	}
+	return "link:" + link.ShortCode
+}
+
+var metricNames = map[string]string{
+	model.MetricLinks:         "links",
+	model.MetricTrackedClicks: "tracked clicks",
+	model.MetricAPICalls:      "API calls",
+}
+
+// ParsePlans parses plans written as name=metric:limit,... and separated by
+// semicolons, e.g. "free=links:100,tracked_clicks:10000,api_calls:10000".
+// Metrics left out are unlimited. An empty string returns DefaultPlans.
+func ParsePlans(s string) ([]model.Plan, error) {
+	if strings.TrimSpace(s) == "" {
+		return DefaultPlans, nil
+	}
+	var plans []model.Plan
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, limits, _ := strings.Cut(entry, "=")
+		plan := model.Plan{Name: strings.TrimSpace(name)}
+		if plan.Name == "" {
+			return nil, fmt.Errorf("plan %q: missing name", entry)
+		}
+		for _, limit := range strings.Split(limits, ",") {
+			if strings.TrimSpace(limit) == "" {
+				continue
+			}
+			metric, value, _ := strings.Cut(limit, ":")
+			n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("plan %q: bad limit %q", plan.Name, limit)
+			}
+			switch strings.TrimSpace(metric) {
+			case model.MetricLinks:
+				plan.Links = n
+			case model.MetricTrackedClicks:
+				plan.TrackedClicks = n
+			case model.MetricAPICalls:
+				plan.APICalls = n
+			default:
+				return nil, fmt.Errorf("plan %q: unknown metric %q", plan.Name, metric)
+			}
+		}
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
+
+// QuotaExceeded is returned when an account has used up a limit of its plan.
+// The link limit answers 403; monthly limits answer 429 until ResetsAt.
+type QuotaExceeded struct {
+	Account  string
+	Plan     string
+	Metric   string
+	Limit    int64
+	ResetsAt time.Time // zero for the link limit
+}
+
+func (e *QuotaExceeded) Error() string {
+	name := metricNames[e.Metric]
+	if e.ResetsAt.IsZero() && e.Account == AnonymousAccount {
+		// Links made without signing in have no owner who could delete them.
+		return fmt.Sprintf("the %s plan allows %d %s made without signing in; sign in to create more", e.Plan, e.Limit, name)
+	}
+	if e.ResetsAt.IsZero() {
+		return fmt.Sprintf("the %s plan allows %d %s; delete some with DELETE /urls/{short_code} or move to a larger plan",
+			e.Plan, e.Limit, name)
+	}
+	return fmt.Sprintf("the %s plan allows %d %s a month and they are used up; the quota resets at %s",
+		e.Plan, e.Limit, name, e.ResetsAt.Format(time.RFC3339))
+}
+
+func (e *QuotaExceeded) StatusCode() int {
+	if e.ResetsAt.IsZero() {
+		return http.StatusForbidden
+	}
+	return http.StatusTooManyRequests
+}
+
+// period names the calendar month of t, and returns when it ends.
+func period(t time.Time) (string, time.Time) {
+	t = t.UTC()
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start.Format("2006-01"), start.AddDate(0, 1, 0)
+}
+
+// Meter enforces plan limits and meters monthly usage. Like clicks, usage is
+// counted in memory and written in batches so metering never waits on the
+// database; with several instances a limit can be overshot by what the
+// others metered since their last flush. Plans are cached and reloaded in the
+// background, so an account is not limited until its plan has been loaded.
+type Meter struct {
+	Store         *store.QuotaStore
+	URLs          *store.URLStore
+	Plans         map[string]model.Plan
+	DefaultPlan   string // plan of accounts without a subscription; "" for unlimited
+	FlushInterval time.Duration
+	PlanTTL       time.Duration // how long subscriptions are cached
+
+	mu        sync.Mutex
+	container *container.Container
+	counters  map[counterKey]*usageCount
+	plans     map[string]cachedPlan
+	loading   map[string]bool // accounts whose plan is being reloaded
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+type counterKey struct {
+	account, metric, period string
+}
+
+type usageCount struct {
+	stored  int64 // as last read from or written to the store
+	pending int64 // metered here and not written yet
+}
+
+type cachedPlan struct {
+	plan    model.Plan
+	fetched time.Time
+}
+
+func NewMeter(quotas *store.QuotaStore, urls *store.URLStore, plans []model.Plan, defaultPlan string) (*Meter, error) {
+	byName := make(map[string]model.Plan, len(plans))
+	for _, plan := range plans {
+		byName[plan.Name] = plan
+	}
+	if _, ok := byName[defaultPlan]; defaultPlan != "" && !ok {
+		return nil, fmt.Errorf("default plan %q is not defined", defaultPlan)
+	}
+	return &Meter{
+		Store:         quotas,
+		URLs:          urls,
+		Plans:         byName,
+		DefaultPlan:   defaultPlan,
+		FlushInterval: 5 * time.Second,
+		PlanTTL:       time.Minute,
+		counters:      map[counterKey]*usageCount{},
+		plans:         map[string]cachedPlan{},
+		loading:       map[string]bool{},
+	}, nil
+}
+
+// Start writes metered usage every FlushInterval until Stop.
+func (m *Meter) Start() {
+	m.stop, m.done = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(m.FlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.Flush()
+			case <-m.stop:
+				m.Flush()
+				return
+			}
+		}
+	}()
+}
+
+// Stop writes what is still pending and stops the background flushes.
+func (m *Meter) Stop() {
+	if m.stop == nil {
+		return
+	}
+	close(m.stop)
+	<-m.done
+}
+
+// PlanOf returns the plan of account.
+func (m *Meter) PlanOf(ctx *gofr.Context, account string) (model.Plan, error) {
+	m.mu.Lock()
+	cached, ok := m.plans[account]
+	m.mu.Unlock()
+	if ok && time.Since(cached.fetched) < m.PlanTTL {
+		return cached.plan, nil
+	}
+	return m.fetchPlan(ctx, account)
+}
+
+// cachedPlan returns the plan of account without waiting on the store. A
+// stale plan is used while it is reloaded in the background; ok is false
+// while the plan of an account is loaded for the first time.
+func (m *Meter) cachedPlan(ctx *gofr.Context, account string) (plan model.Plan, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cached, ok := m.plans[account]
+	if (!ok || time.Since(cached.fetched) >= m.PlanTTL) && !m.loading[account] {
+		m.loading[account] = true
+		go m.reloadPlan(ctx.Container, account)
+	}
+	return cached.plan, ok
+}
+
+func (m *Meter) reloadPlan(c *container.Container, account string) {
+	ctx := &gofr.Context{Context: context.Background(), Container: c}
+	if _, err := m.fetchPlan(ctx, account); err != nil {
+		ctx.Logger.Errorf("loading the plan of %s: %v", account, err)
+	}
+	m.mu.Lock()
+	delete(m.loading, account)
+	m.mu.Unlock()
+}
+
+// fetchPlan reads the plan of account from its subscription and caches it.
+func (m *Meter) fetchPlan(ctx *gofr.Context, account string) (model.Plan, error) {
+	name := m.DefaultPlan
+	subscription, err := m.Store.FindSubscription(ctx, account)
+	switch {
+	case err == nil:
+		name = subscription.Plan
+	case !errors.Is(err, mongo.ErrNoDocuments):
+		return model.Plan{}, err
+	}
+	return m.Remember(account, name), nil
+}
+
+// Remember caches that account is on the plan called name, and returns
+// that plan. It is called when the subscription of account changes.
+func (m *Meter) Remember(account, name string) model.Plan {
+	plan, ok := m.Plans[name]
+	if !ok {
+		// No default plan, or a subscription to a plan that was removed.
+		plan = unlimitedPlan
+	}
+	m.mu.Lock()
+	m.plans[account] = cachedPlan{plan: plan, fetched: time.Now()}
+	m.mu.Unlock()
+	return plan
+}
+
+// CheckLinks returns a QuotaExceeded error when the account of a new link
+// already has as many links as its plan allows.
+func (m *Meter) CheckLinks(ctx *gofr.Context, owner, workspace string) error {
+	account := linkAccount(owner, workspace)
+	plan, err := m.PlanOf(ctx, account)
+	if err != nil || plan.Links == 0 {
+		return err
+	}
+	count, err := m.URLs.CountForAccount(ctx, owner, workspace)
+	if err != nil {
+		return err
+	}
+	if count >= plan.Links {
+		return &QuotaExceeded{Account: account, Plan: plan.Name, Metric: model.MetricLinks, Limit: plan.Links}
+	}
+	return nil
+}
+
+// Take meters one use of a monthly metric, or returns a QuotaExceeded error
+// when the account has used up its quota for the month.
+func (m *Meter) Take(ctx *gofr.Context, account, metric string) error {
+	plan, known := m.cachedPlan(ctx, account)
+	name, resets := period(time.Now())
+	key := counterKey{account: account, metric: metric, period: name}
+	count, err := m.counter(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.container == nil {
+		m.container = ctx.Container
+	}
+	limit := plan.Limit(metric)
+	if known && limit > 0 && count.stored+count.pending >= limit {
+		return &QuotaExceeded{Account: account, Plan: plan.Name, Metric: metric, Limit: limit, ResetsAt: resets}
+	}
+	count.pending++
+	return nil
+}
+
+// Used returns the monthly usage of account in the current period,
+// including what has not been written yet.
+func (m *Meter) Used(ctx *gofr.Context, account, metric string) (int64, error) {
+	name, _ := period(time.Now())
+	count, err := m.counter(ctx, counterKey{account: account, metric: metric, period: name})
+	if err != nil {
+		return 0, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return count.stored + count.pending, nil
+}
+
+// counter returns the in-memory counter for key, reading it from the store
+// the first time. The store is read without holding the lock.
+func (m *Meter) counter(ctx *gofr.Context, key counterKey) (*usageCount, error) {
+	m.mu.Lock()
+	count, ok := m.counters[key]
+	m.mu.Unlock()
+	if ok {
+		return count, nil
+	}
+	stored, err := m.load(ctx, key.account, key.period)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Keep every monthly metric of the period, not only the one asked for,
+	// so the others need no read of their own.
+	for _, metric := range []string{model.MetricTrackedClicks, model.MetricAPICalls} {
+		k := counterKey{account: key.account, metric: metric, period: key.period}
+		if _, ok := m.counters[k]; !ok {
+			m.counters[k] = &usageCount{stored: stored[metric]}
+		}
+	}
+	return m.counters[key], nil
+}
+
+func (m *Meter) load(ctx *gofr.Context, account, period string) (map[string]int64, error) {
+	counters, err := m.Store.FindUsage(ctx, account, period)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(counters))
+	for _, counter := range counters {
+		counts[counter.Metric] = counter.Count
+	}
+	return counts, nil
+}
+
+// Flush writes pending usage, then rereads the counters so usage metered by
+// other instances is taken into account. Counters of past months are dropped.
+func (m *Meter) Flush() {
+	current, _ := period(time.Now())
+	m.mu.Lock()
+	c := m.container
+	pending := make(map[counterKey]int64)
+	for key, count := range m.counters {
+		if count.pending > 0 {
+			pending[key] = count.pending
+			count.stored += count.pending
+			count.pending = 0
+		}
+		if key.period != current && count.pending == 0 {
+			delete(m.counters, key)
+		}
+	}
+	m.mu.Unlock()
+	if c == nil {
+		return
+	}
+
+	ctx := &gofr.Context{Context: context.Background(), Container: c}
+	accounts := map[counterKey]bool{}
+	for key, n := range pending {
+		if err := m.Store.AddUsage(ctx, key.account, key.metric, key.period, n); err != nil {
+			ctx.Logger.Errorf("writing %s usage of %s: %v", key.metric, key.account, err)
+			m.mu.Lock()
+			if count, ok := m.counters[key]; ok {
+				count.stored -= n
+				count.pending += n
+			}
+			m.mu.Unlock()
+			continue
+		}
+		accounts[counterKey{account: key.account, period: key.period}] = true
+	}
+	for key := range accounts {
+		stored, err := m.load(ctx, key.account, key.period)
+		if err != nil {
+			ctx.Logger.Errorf("reading usage of %s: %v", key.account, err)
+			continue
+		}
+		m.mu.Lock()
+		for metric, n := range stored {
+			if count, ok := m.counters[counterKey{account: key.account, metric: metric, period: key.period}]; ok {
+				count.stored = n
+			}
+		}
+		m.mu.Unlock()
+	}
+}
+
+type QuotaService interface {
+	Plans(ctx *gofr.Context) []model.Plan
+	Usage(ctx *gofr.Context, workspace string) (*model.Usage, error)
+	SetPlan(ctx *gofr.Context, account string, req *model.SetPlanRequest) (*model.Subscription, error)
+}
+
+type QuotaServiceImpl struct {
+	Meter  *Meter
+	Access *WorkspaceAccess
+	Audit  *Auditor
+}
+
+func NewQuotaService(meter *Meter, access *WorkspaceAccess, auditor *Auditor) QuotaService {
+	return &QuotaServiceImpl{Meter: meter, Access: access, Audit: auditor}
+}
+
+func (s *QuotaServiceImpl) Plans(*gofr.Context) []model.Plan {
+	plans := make([]model.Plan, 0, len(s.Meter.Plans))
+	for _, plan := range s.Meter.Plans {
+		plans = append(plans, plan)
+	}
+	sortPlans(plans)
+	return plans
+}
+
+// Usage reports the usage of a workspace, or of the caller's own links when
+// workspace is empty. API calls are metered per user, so they are only part
+// of the latter.
+func (s *QuotaServiceImpl) Usage(ctx *gofr.Context, workspace string) (*model.Usage, error) {
+	owner := middleware.Actor(ctx)
+	metrics := []string{model.MetricTrackedClicks}
+	if workspace != "" {
+		if _, err := s.Access.Require(ctx, workspace, model.RoleViewer); err != nil {
+			return nil, err
+		}
+	} else {
+		if owner == "" {
+			return nil, ErrActorRequired
+		}
+		metrics = append(metrics, model.MetricAPICalls)
+	}
+	account := workspaceOf(owner, workspace)
+	plan, err := s.Meter.PlanOf(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.Meter.URLs.CountForAccount(ctx, owner, workspace)
+	if err != nil {
+		return nil, err
+	}
+	name, resets := period(time.Now())
+	usage := &model.Usage{
+		Account:  account,
+		Plan:     plan.Name,
+		Period:   name,
+		ResetsAt: resets,
+		Metrics:  []model.UsageMetric{{Metric: model.MetricLinks, Used: links, Limit: plan.Links}},
+	}
+	for _, metric := range metrics {
+		used, err := s.Meter.Used(ctx, account, metric)
+		if err != nil {
+			return nil, err
+		}
+		usage.Metrics = append(usage.Metrics, model.UsageMetric{Metric: metric, Used: used, Limit: plan.Limit(metric), Monthly: true})
+	}
+	return usage, nil
+}
+
+// SetPlan puts account, a workspace ID or a user ID, on a plan. An empty
+// plan returns it to the default plan.
+func (s *QuotaServiceImpl) SetPlan(ctx *gofr.Context, account string, req *model.SetPlanRequest) (*model.Subscription, error) {
+	if _, ok := s.Meter.Plans[req.Plan]; req.Plan != "" && !ok {
+		return nil, gofrHttp.ErrorInvalidParam{Params: []string{"plan"}}
+	}
+	before, err := s.Meter.Store.FindSubscription(ctx, account)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	subscription := &model.Subscription{Account: account, Plan: req.Plan}
+	if req.Plan == "" {
+		subscription.Plan = s.Meter.DefaultPlan
+		_, err = s.Meter.Store.DeleteSubscription(ctx, account)
+	} else {
+		err = s.Meter.Store.SetSubscription(ctx, subscription)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.Meter.Remember(account, subscription.Plan)
+	s.Audit.Record(ctx, model.AuditPlanAssign, "account:"+account, before, subscription)
+	return subscription, nil
+}
+
+// sortPlans orders plans from the smallest to the largest link limit, with
+// unlimited ones last.
+func sortPlans(plans []model.Plan) {
+	rank := func(p model.Plan) int64 {
+		if p.Links == 0 {
+			return math.MaxInt64
+		}
+		return p.Links
+	}
+	sort.Slice(plans, func(i, j int) bool {
+		if rank(plans[i]) != rank(plans[j]) {
+			return rank(plans[i]) < rank(plans[j])
+		}
+		return plans[i].Name < plans[j].Name
+	})
+}
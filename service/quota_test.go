@@ -0,0 +1,270 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+var testPlans = []model.Plan{
+	{Name: "free", Links: 2, TrackedClicks: 2, APICalls: 100},
+	{Name: "pro", TrackedClicks: 1000},
+}
+
+func newMeter(t *testing.T, defaultPlan string) *service.Meter {
+	meter, err := service.NewMeter(store.NewQuotaStore(), store.NewURLStore(), testPlans, defaultPlan)
+	if err != nil {
+		t.Fatalf("creating meter: %v", err)
+	}
+	return meter
+}
+
+// expectSubscription serves the plan of account, or no subscription when plan is empty.
+func expectSubscription(mocks *container.Mocks, account, plan string) {
+	mocks.Mongo.EXPECT().FindOne(gomock.Any(), "subscriptions", bson.M{"_id": account}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, result any) error {
+			if plan == "" {
+				return mongo.ErrNoDocuments
+			}
+			*result.(*model.Subscription) = model.Subscription{Account: account, Plan: plan}
+			return nil
+		})
+}
+
+func expectUsage(mocks *container.Mocks, account string, counters ...model.UsageCounter) {
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "usage", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, filter any, results any) error {
+			if filter.(bson.M)["account"] != account {
+				return mongo.ErrNoDocuments
+			}
+			*results.(*[]model.UsageCounter) = counters
+			return nil
+		})
+}
+
+func TestParsePlans(t *testing.T) {
+	plans, err := service.ParsePlans("free=links:2,tracked_clicks:2,api_calls:100; pro=tracked_clicks:1000")
+	assert.NoError(t, err)
+	assert.Equal(t, testPlans, plans)
+
+	plans, err = service.ParsePlans("")
+	assert.NoError(t, err)
+	assert.Equal(t, service.DefaultPlans, plans)
+
+	for _, bad := range []string{"=links:1", "free=links:lots", "free=links:-1", "free=domains:1"} {
+		_, err := service.ParsePlans(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestNewMeterUnknownDefaultPlan(t *testing.T) {
+	_, err := service.NewMeter(store.NewQuotaStore(), store.NewURLStore(), testPlans, "enterprise")
+
+	assert.Error(t, err)
+}
+
+func TestMeterTake(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+	meter := newMeter(t, "free")
+	expectSubscription(mocks, "alice", "")
+	expectUsage(mocks, "alice", model.UsageCounter{Metric: model.MetricTrackedClicks, Count: 1})
+	_, err := meter.PlanOf(ctx, "alice")
+	assert.NoError(t, err)
+
+	assert.NoError(t, meter.Take(ctx, "alice", model.MetricTrackedClicks))
+	err = meter.Take(ctx, "alice", model.MetricTrackedClicks)
+
+	if assert.IsType(t, &service.QuotaExceeded{}, err) {
+		exceeded := err.(*service.QuotaExceeded)
+		assert.Equal(t, http.StatusTooManyRequests, exceeded.StatusCode())
+		assert.Equal(t, "free", exceeded.Plan)
+		assert.Equal(t, 1, exceeded.ResetsAt.Day())
+		assert.True(t, exceeded.ResetsAt.After(time.Now()))
+		assert.Contains(t, err.Error(), "2 tracked clicks a month")
+	}
+
+	t.Run("Flush", func(t *testing.T) {
+		mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "usage", gomock.Any(), bson.M{"$inc": bson.M{"count": int64(1)}}).
+			DoAndReturn(func(_ context.Context, _ string, filter any, _ any) (int64, error) {
+				assert.Regexp(t, `^alice\|tracked_clicks\|\d{4}-\d{2}$`, filter.(bson.M)["_id"])
+				return 1, nil
+			})
+		// Another instance metered more in the meantime.
+		expectUsage(mocks, "alice", model.UsageCounter{Metric: model.MetricTrackedClicks, Count: 5})
+
+		meter.Flush()
+		used, err := meter.Used(ctx, "alice", model.MetricTrackedClicks)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), used)
+	})
+}
+
+func TestMeterUnlimited(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+	meter := newMeter(t, "")
+	expectSubscription(mocks, "alice", "")
+	expectUsage(mocks, "alice", model.UsageCounter{Metric: model.MetricAPICalls, Count: 1_000_000})
+
+	plan, err := meter.PlanOf(ctx, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, "unlimited", plan.Name)
+	assert.NoError(t, meter.Take(ctx, "alice", model.MetricAPICalls))
+}
+
+func TestURLServiceCreateAnonymousLinkQuota(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+	expectSubscription(mocks, service.AnonymousAccount, "")
+	mocks.Mongo.EXPECT().CountDocuments(gomock.Any(), "urls",
+		bson.M{"owner": bson.M{"$exists": false}, "workspace": bson.M{"$exists": false}}).Return(int64(2), nil)
+	svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/", service.WithQuotas(newMeter(t, "free")))
+
+	_, err := svc.Create(ctx, &model.CreateURLRequest{OriginalURL: "https://example.com"})
+
+	if assert.IsType(t, &service.QuotaExceeded{}, err) {
+		assert.Equal(t, "the free plan allows 2 links made without signing in; sign in to create more", err.Error())
+	}
+}
+
+func TestURLServiceCreateLinkQuota(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	ctx := &gofr.Context{Context: actorContext("alice"), Container: mockContainer}
+	expectSubscription(mocks, "alice", "")
+	mocks.Mongo.EXPECT().CountDocuments(gomock.Any(), "urls",
+		bson.M{"owner": "alice", "workspace": bson.M{"$exists": false}}).Return(int64(2), nil)
+	svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/", service.WithQuotas(newMeter(t, "free")))
+
+	_, err := svc.Create(ctx, &model.CreateURLRequest{OriginalURL: "https://example.com"})
+
+	if assert.IsType(t, &service.QuotaExceeded{}, err) {
+		assert.Equal(t, http.StatusForbidden, err.(*service.QuotaExceeded).StatusCode())
+		assert.Equal(t, "the free plan allows 2 links; delete some with DELETE /urls/{short_code} or move to a larger plan", err.Error())
+	}
+}
+
+func TestURLServiceResolveClickQuota(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+	expectLink(mocks, model.URL{ShortCode: "abc123", Owner: "alice", Workspace: "ws1", Original: "https://example.com"})
+	expectSubscription(mocks, "ws1", "")
+	expectUsage(mocks, "ws1", model.UsageCounter{Metric: model.MetricTrackedClicks, Count: 2})
+	meter := newMeter(t, "free")
+	_, err := meter.PlanOf(ctx, "ws1")
+	assert.NoError(t, err)
+	svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/", service.WithQuotas(meter))
+
+	// No click is counted, yet the link still redirects.
+	destination, err := svc.Resolve(ctx, "abc123", "", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com", destination)
+}
+
+func TestURLServiceResolveAnonymousClickQuota(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+	expectLink(mocks, model.URL{ShortCode: "abc123", Original: "https://example.com"})
+	expectSubscription(mocks, "link:abc123", "")
+	expectUsage(mocks, "link:abc123")
+	meter := newMeter(t, "free")
+	_, err := meter.PlanOf(ctx, "link:abc123")
+	assert.NoError(t, err)
+	mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "urls", bson.M{"short_code": "abc123"}, gomock.Any()).Return(nil)
+	svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/", service.WithQuotas(meter))
+
+	_, err = svc.Resolve(ctx, "abc123", "", nil)
+
+	assert.NoError(t, err)
+	used, err := meter.Used(ctx, "link:abc123", model.MetricTrackedClicks)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), used)
+}
+
+func TestURLServiceResolveBotClickQuota(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+	ctx.Context = middleware.WithRequestMeta(ctx.Context, middleware.RequestMeta{
+		Method: http.MethodGet,
+		Header: http.Header{"User-Agent": {"Googlebot/2.1 (+http://www.google.com/bot.html)"}},
+	})
+	expectLink(mocks, model.URL{ShortCode: "abc123", Owner: "alice", Original: "https://example.com"})
+	mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "urls", bson.M{"short_code": "abc123"},
+		bson.M{"$inc": bson.M{"click_count": int64(0), "bot_click_count": int64(1)}}).Return(nil)
+	meter := newMeter(t, "free")
+	svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/", service.WithQuotas(meter),
+		service.WithBotFilter(service.NewBotClassifier()))
+
+	// The bot click is recorded without loading the plan or usage of alice.
+	_, err := svc.Resolve(ctx, "abc123", "", nil)
+
+	assert.NoError(t, err)
+}
+
+func TestQuotaServiceUsage(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	ctx := &gofr.Context{Context: actorContext("alice"), Container: mockContainer}
+	expectSubscription(mocks, "alice", "pro")
+	mocks.Mongo.EXPECT().CountDocuments(gomock.Any(), "urls", gomock.Any()).Return(int64(7), nil)
+	expectUsage(mocks, "alice",
+		model.UsageCounter{Metric: model.MetricTrackedClicks, Count: 40},
+		model.UsageCounter{Metric: model.MetricAPICalls, Count: 12})
+	svc := service.NewQuotaService(newMeter(t, "free"), nil, nil)
+
+	usage, err := svc.Usage(ctx, "")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "alice", usage.Account)
+		assert.Equal(t, "pro", usage.Plan)
+		assert.Equal(t, []model.UsageMetric{
+			{Metric: model.MetricLinks, Used: 7},
+			{Metric: model.MetricTrackedClicks, Used: 40, Limit: 1000, Monthly: true},
+			{Metric: model.MetricAPICalls, Used: 12, Monthly: true},
+		}, usage.Metrics)
+	}
+}
+
+func TestQuotaServiceSetPlan(t *testing.T) {
+	t.Run("Unknown Plan", func(t *testing.T) {
+		mockContainer, _ := container.NewMockContainer(t)
+		svc := service.NewQuotaService(newMeter(t, "free"), nil, nil)
+
+		_, err := svc.SetPlan(&gofr.Context{Context: context.Background(), Container: mockContainer}, "ws1",
+			&model.SetPlanRequest{Plan: "enterprise"})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Takes Effect At Once", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+		meter := newMeter(t, "free")
+		expectSubscription(mocks, "ws1", "")
+		plan, _ := meter.PlanOf(ctx, "ws1")
+		assert.Equal(t, "free", plan.Name)
+
+		mocks.Mongo.EXPECT().FindOne(gomock.Any(), "subscriptions", bson.M{"_id": "ws1"}, gomock.Any()).Return(mongo.ErrNoDocuments)
+		mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "subscriptions", bson.M{"_id": "ws1"}, gomock.Any()).Return(int64(0), nil)
+		mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "subscriptions", gomock.Any()).Return(nil, nil)
+		_, err := service.NewQuotaService(meter, nil, nil).SetPlan(ctx, "ws1", &model.SetPlanRequest{Plan: "pro"})
+		assert.NoError(t, err)
+
+		plan, _ = meter.PlanOf(ctx, "ws1")
+		assert.Equal(t, "pro", plan.Name)
+	})
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+// ReportIDHeader names the report a webhook delivery belongs to.
+const ReportIDHeader = "X-Report-ID"
+
+var (
+	ErrReportNotFound  = &apierror.Error{Status: http.StatusNotFound, Message: "report not found"}
+	ErrReportName      = &apierror.Error{Status: http.StatusBadRequest, Message: "report name is required"}
+	ErrReportFrequency = &apierror.Error{Status: http.StatusBadRequest, Message: "frequency must be daily or weekly"}
+	ErrReportFormat    = &apierror.Error{Status: http.StatusBadRequest, Message: "format must be json or csv"}
+	ErrReportDelivery  = &apierror.Error{Status: http.StatusBadRequest, Message: "delivery must be webhook or file"}
+	ErrFileDelivery    = &apierror.Error{Status: http.StatusBadRequest, Message: "file delivery is not configured"}
+)
+
+type ReportServiceImpl struct {
+	Store     *store.ReportStore
+	Analytics *AnalyticsServiceImpl
+	Client    *http.Client
+	Dir       string // where file deliveries are written; empty disables them
+	Audit     *Auditor
+}
+
+func NewReportService(reportStore *store.ReportStore, urls *store.URLStore, rollups *store.RollupStore,
+	access *WorkspaceAccess, dir string, auditor *Auditor) ReportService {
+	return &ReportServiceImpl{
+		Store:     reportStore,
+		Analytics: &AnalyticsServiceImpl{URLs: urls, Rollups: rollups, Workspace: access},
+		Client:    newOutboundClient(10 * time.Second),
+		Dir:       dir,
+		Audit:     auditor,
+	}
+}
+
+type ReportService interface {
+	Create(ctx *gofr.Context, req *model.CreateReportRequest) (*model.Report, error)
+	List(ctx *gofr.Context) ([]model.Report, error)
+	Delete(ctx *gofr.Context, id string) error
+	Run(ctx *gofr.Context, frequency string)
+}
+
+func (s *ReportServiceImpl) Create(ctx *gofr.Context, req *model.CreateReportRequest) (*model.Report, error) {
+	if req.Name == "" {
+		return nil, ErrReportName
+	}
+	if err := validateScope(req.Scope); err != nil {
+		return nil, err
+	}
+	if req.Frequency != model.ReportDaily && req.Frequency != model.ReportWeekly {
+		return nil, ErrReportFrequency
+	}
+	format := req.Format
+	if format == "" {
+		format = model.FormatJSON
+	}
+	if format != model.FormatJSON && format != model.FormatCSV {
+		return nil, ErrReportFormat
+	}
+	switch req.Delivery {
+	case model.ReportDeliveryWebhook:
+		if !checkPublicURL(req.WebhookURL) {
+			return nil, ErrWebhookURL
+		}
+	case model.ReportDeliveryFile:
+		if s.Dir == "" {
+			return nil, ErrFileDelivery
+		}
+	default:
+		return nil, ErrReportDelivery
+	}
+
+	report := &model.Report{
+		Owner:     middleware.Actor(ctx),
+		Name:      req.Name,
+		Scope:     req.Scope,
+		Frequency: req.Frequency,
+		Format:    format,
+		Delivery:  req.Delivery,
+	}
+	if req.Delivery == model.ReportDeliveryWebhook {
+		report.WebhookURL = req.WebhookURL
+	}
+	if err := s.Store.Insert(ctx, report); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, model.AuditReportCreate, "report:"+report.ID, nil, report)
+	return report, nil
+}
+
+func (s *ReportServiceImpl) List(ctx *gofr.Context) ([]model.Report, error) {
+	return s.Store.FindByOwner(ctx, middleware.Actor(ctx))
+}
+
+func (s *ReportServiceImpl) Delete(ctx *gofr.Context, id string) error {
+	owner := middleware.Actor(ctx)
+	report, err := s.Store.FindByID(ctx, owner, id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrReportNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.Store.Delete(ctx, owner, id); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, model.AuditReportDelete, "report:"+id, report, nil)
+	return nil
+}
+
+// Run renders and delivers every report of the given frequency for the
+// period that just ended: yesterday for daily reports, the last seven days
+// for weekly ones. It is run by GoFr cron jobs on every instance; each
+// report is claimed for the period first, so it is delivered only once.
+func (s *ReportServiceImpl) Run(ctx *gofr.Context, frequency string) {
+	reports, err := s.Store.FindByFrequency(ctx, frequency)
+	if err != nil {
+		ctx.Logger.Errorf("loading %s reports: %v", frequency, err)
+		return
+	}
+	to := BucketStart(model.GranularityDay, time.Now())
+	from := to.AddDate(0, 0, -1)
+	if frequency == model.ReportWeekly {
+		from = to.AddDate(0, 0, -7)
+	}
+	for i := range reports {
+		report := &reports[i]
+		claimed, err := s.Store.ClaimRun(ctx, report.ID, from)
+		if err != nil {
+			ctx.Logger.Errorf("claiming report %s: %v", report.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		runErr := ""
+		if err := s.runReport(ctx, report, from, to); err != nil {
+			ctx.Logger.Errorf("running report %s: %v", report.ID, err)
+			runErr = err.Error()
+		}
+		if err := s.Store.RecordRun(ctx, report.ID, time.Now().UTC(), runErr); err != nil {
+			ctx.Logger.Errorf("recording run of report %s: %v", report.ID, err)
+		}
+	}
+}
+
+func (s *ReportServiceImpl) runReport(ctx *gofr.Context, report *model.Report, from, to time.Time) error {
+	summary, err := s.Analytics.summarize(ctx, report.Owner, report.Scope, from, to)
+	if err != nil {
+		return err
+	}
+	summary.ReportID, summary.Name = report.ID, report.Name
+	body, contentType, err := RenderSummary(summary, report.Format)
+	if err != nil {
+		return err
+	}
+	if report.Delivery == model.ReportDeliveryFile {
+		name := fmt.Sprintf("%s-%s.%s", report.ID, from.Format("2006-01-02"), report.Format)
+		return os.WriteFile(filepath.Join(s.Dir, name), body, 0o644)
+	}
+	return s.post(ctx, report, body, contentType)
+}
+
+func (s *ReportServiceImpl) post(ctx *gofr.Context, report *model.Report, body []byte, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, report.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set(ReportIDHeader, report.ID)
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("receiver responded %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// RenderSummary encodes a report summary. The CSV form has one row per link
+// followed by a total row.
+func RenderSummary(summary *model.ReportSummary, format string) ([]byte, string, error) {
+	if format != model.FormatCSV {
+		body, err := json.Marshal(summary)
+		return body, "application/json", err
+	}
+	rows := [][]string{{"short_code", "clicks", "bot_clicks", "uniques"}}
+	for _, link := range summary.Links {
+		rows = append(rows, summaryRow(link.ShortCode, link.Clicks, link.BotClicks, link.Uniques))
+	}
+	rows = append(rows, summaryRow("total", summary.Clicks, summary.BotClicks, summary.Uniques))
+	body, err := encodeCSV(rows)
+	return body, "text/csv", err
+}
+
+// RenderExport encodes export rows as CSV.
+func RenderExport(rows []model.ExportRow) ([]byte, error) {
+	records := [][]string{{"short_code", "bucket", "clicks", "bot_clicks", "uniques"}}
+	for _, row := range rows {
+		records = append(records, []string{
+			row.ShortCode,
+			row.Bucket.Format(time.RFC3339),
+			strconv.FormatInt(row.Clicks, 10),
+			strconv.FormatInt(row.BotClicks, 10),
+			strconv.FormatInt(row.Uniques, 10),
+		})
+	}
+	return encodeCSV(records)
+}
+
+func summaryRow(name string, clicks, botClicks, uniques int64) []string {
+	return []string{
+		name,
+		strconv.FormatInt(clicks, 10),
+		strconv.FormatInt(botClicks, 10),
+		strconv.FormatInt(uniques, 10),
+	}
+}
+
+func encodeCSV(records [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,214 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+func TestReportServiceCreate(t *testing.T) {
+	valid := model.CreateReportRequest{
+		Name:       "Launch",
+		Scope:      model.ReportScope{Tag: "launch"},
+		Frequency:  model.ReportWeekly,
+		Delivery:   model.ReportDeliveryWebhook,
+		WebhookURL: "https://hooks.example.com/reports",
+	}
+
+	tests := []struct {
+		name        string
+		modify      func(req *model.CreateReportRequest)
+		dir         string
+		expectError bool
+	}{
+		{name: "Success - Webhook", modify: func(*model.CreateReportRequest) {}},
+		{name: "Success - File", dir: "/var/reports", modify: func(req *model.CreateReportRequest) {
+			req.Delivery = model.ReportDeliveryFile
+			req.Format = model.FormatCSV
+		}},
+		{name: "Failure - Missing Name", expectError: true, modify: func(req *model.CreateReportRequest) { req.Name = "" }},
+		{name: "Failure - Two Scopes", expectError: true, modify: func(req *model.CreateReportRequest) { req.Scope.ShortCode = "abc123" }},
+		{name: "Failure - No Scope", expectError: true, modify: func(req *model.CreateReportRequest) { req.Scope = model.ReportScope{} }},
+		{name: "Failure - Frequency", expectError: true, modify: func(req *model.CreateReportRequest) { req.Frequency = "hourly" }},
+		{name: "Failure - Format", expectError: true, modify: func(req *model.CreateReportRequest) { req.Format = "xml" }},
+		{name: "Failure - Webhook URL", expectError: true, modify: func(req *model.CreateReportRequest) { req.WebhookURL = "ftp://example.com" }},
+		{name: "Failure - Private Webhook URL", expectError: true, modify: func(req *model.CreateReportRequest) {
+			req.WebhookURL = "http://169.254.169.254/latest/meta-data"
+		}},
+		{name: "Failure - File Not Configured", expectError: true, modify: func(req *model.CreateReportRequest) {
+			req.Delivery = model.ReportDeliveryFile
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContainer, mocks := container.NewMockContainer(t)
+			if !tt.expectError {
+				mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "reports", gomock.Any()).Return(nil, nil)
+			}
+			svc := service.NewReportService(store.NewReportStore(), store.NewURLStore(), store.NewRollupStore(), nil, tt.dir, nil)
+			req := valid
+			tt.modify(&req)
+
+			ctx := &gofr.Context{Context: actorContext("alice"), Container: mockContainer}
+			report, err := svc.Create(ctx, &req)
+
+			if tt.expectError {
+				assert.Equal(t, http.StatusBadRequest, statusOf(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "alice", report.Owner)
+			assert.NotEmpty(t, report.ID)
+			if report.Delivery == model.ReportDeliveryFile {
+				assert.Empty(t, report.WebhookURL)
+			} else {
+				assert.Equal(t, model.FormatJSON, report.Format)
+			}
+		})
+	}
+}
+
+func TestAnalyticsServiceExport(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "urls", bson.M{"owner": "alice", "tags": bson.M{"$all": []string{"launch"}}}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.URL) = []model.URL{{ShortCode: "xyz789"}, {ShortCode: "abc123"}, {ShortCode: "team01", Workspace: "ws1"}}
+			return nil
+		})
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "workspace_members", bson.M{"user_id": "alice"}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.Membership) = []model.Membership{{WorkspaceID: "ws1", UserID: "alice", Role: model.RoleViewer}}
+			return nil
+		})
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "urls", bson.M{"workspace": "ws1", "tags": bson.M{"$all": []string{"launch"}}}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.URL) = []model.URL{{ShortCode: "team01", Workspace: "ws1"}}
+			return nil
+		})
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "click_rollups", bson.M{
+		"short_code":  bson.M{"$in": []string{"xyz789", "abc123", "team01"}},
+		"granularity": model.GranularityDay,
+		"bucket":      bson.M{"$gte": day, "$lt": day.AddDate(0, 0, 2)},
+	}, gomock.Any()).DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+		*results.(*[]model.ClickRollup) = []model.ClickRollup{
+			{ShortCode: "xyz789", Bucket: day, Clicks: 1},
+			{ShortCode: "abc123", Bucket: day.AddDate(0, 0, 1), Clicks: 3, BotClicks: 1},
+			{ShortCode: "abc123", Bucket: day, Clicks: 2, Visitors: map[string]int{"7": 1}},
+			{ShortCode: "team01", Bucket: day, Clicks: 5},
+		}
+		return nil
+	})
+
+	svc := service.NewAnalyticsService(store.NewURLStore(), store.NewRollupStore(),
+		service.NewWorkspaceAccess(store.NewWorkspaceStore()))
+	ctx := &gofr.Context{Context: actorContext("alice"), Container: mockContainer}
+	rows, err := svc.Export(ctx, model.ReportScope{Tag: "launch"}, model.AnalyticsQuery{From: day, To: day.AddDate(0, 0, 2)})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.ExportRow{
+		{ShortCode: "abc123", Bucket: day, Clicks: 2, Uniques: 1},
+		{ShortCode: "abc123", Bucket: day.AddDate(0, 0, 1), Clicks: 3, BotClicks: 1},
+		{ShortCode: "team01", Bucket: day, Clicks: 5},
+		{ShortCode: "xyz789", Bucket: day, Clicks: 1},
+	}, rows)
+
+	content, err := service.RenderExport(rows)
+	assert.NoError(t, err)
+	assert.Equal(t, "short_code,bucket,clicks,bot_clicks,uniques\n"+
+		"abc123,2024-05-01T00:00:00Z,2,0,1\n"+
+		"abc123,2024-05-02T00:00:00Z,3,1,0\n"+
+		"team01,2024-05-01T00:00:00Z,5,0,0\n"+
+		"xyz789,2024-05-01T00:00:00Z,1,0,0\n", string(content))
+}
+
+func TestReportServiceRun(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		received <- r
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+
+	mockContainer, mocks := container.NewMockContainer(t)
+	scope := model.ReportScope{ShortCode: "abc123"}
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "reports", bson.M{"frequency": model.ReportDaily}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.Report) = []model.Report{
+				{ID: "r1", Owner: "alice", Name: "Hook", Scope: scope, Format: model.FormatJSON,
+					Delivery: model.ReportDeliveryWebhook, WebhookURL: server.URL},
+				{ID: "r2", Owner: "alice", Name: "File", Scope: scope, Format: model.FormatCSV,
+					Delivery: model.ReportDeliveryFile},
+				{ID: "r3", Owner: "alice", Name: "Claimed", Scope: scope, Format: model.FormatCSV,
+					Delivery: model.ReportDeliveryFile},
+			}
+			return nil
+		})
+	// r3 was claimed for this period by another instance.
+	claimed := map[string]int64{"r1": 1, "r2": 1, "r3": 0}
+	mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "reports", gomock.Any(), gomock.Any()).Times(3).
+		DoAndReturn(func(_ context.Context, _ string, filter, _ any) (int64, error) {
+			return claimed[filter.(bson.M)["_id"].(string)], nil
+		})
+	expectLink(mocks, model.URL{ShortCode: "abc123", Owner: "alice"})
+	expectLink(mocks, model.URL{ShortCode: "abc123", Owner: "alice"})
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "click_rollups", gomock.Any(), gomock.Any()).Times(2).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.ClickRollup) = []model.ClickRollup{{
+				ShortCode:  "abc123",
+				Clicks:     10,
+				BotClicks:  2,
+				Dimensions: map[string]map[string]int64{model.DimensionReferrer: {"t.co": 10}},
+			}}
+			return nil
+		})
+	for _, id := range []string{"r1", "r2"} {
+		mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "reports", bson.M{"_id": id}, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, _ any, update any) error {
+				assert.Empty(t, update.(bson.M)["$set"].(bson.M)["last_error"])
+				return nil
+			})
+	}
+
+	svc := service.NewReportService(store.NewReportStore(), store.NewURLStore(), store.NewRollupStore(), nil, dir, nil)
+	svc.(*service.ReportServiceImpl).Client = server.Client()
+	svc.Run(&gofr.Context{Context: context.Background(), Container: mockContainer}, model.ReportDaily)
+
+	req := <-received
+	assert.Equal(t, "r1", req.Header.Get(service.ReportIDHeader))
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	var summary model.ReportSummary
+	assert.NoError(t, json.Unmarshal(body, &summary))
+	assert.Equal(t, "Hook", summary.Name)
+	assert.Equal(t, int64(10), summary.Clicks)
+	assert.Equal(t, int64(2), summary.BotClicks)
+	assert.Equal(t, summary.To.AddDate(0, 0, -1), summary.From)
+	assert.Equal(t, []model.DimensionCount{{Value: "t.co", Clicks: 10, Percentage: 100}}, summary.TopReferrers)
+
+	files, err := filepath.Glob(filepath.Join(dir, "r2-*.csv"))
+	assert.NoError(t, err)
+	if assert.Len(t, files, 1) {
+		content, err := os.ReadFile(files[0])
+		assert.NoError(t, err)
+		assert.Equal(t, "short_code,clicks,bot_clicks,uniques\nabc123,10,2,0\ntotal,10,2,0\n", string(content))
+	}
+}
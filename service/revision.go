@@ -0,0 +1,172 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+var (
+	ErrRevisionConflict = &apierror.Error{Status: http.StatusConflict, Message: "link was changed concurrently, retry the update"}
+	ErrRevisionNotFound = &apierror.Error{Status: http.StatusNotFound, Message: "revision not found"}
+	ErrHistoryDisabled  = &apierror.Error{Status: http.StatusNotImplemented, Message: "link history is not enabled"}
+)
+
+// WithRevisions keeps an immutable history of every change to a link.
+func WithRevisions(revisions *store.RevisionStore) URLOption {
+	return func(s *URLServiceImpl) {
+		s.Revisions = revisions
+	}
+}
+
+// Update changes the settings of a link the caller may modify.
+func (s *URLServiceImpl) Update(ctx *gofr.Context, code string, req *model.UpdateURLRequest) (*model.URL, error) {
+	link, err := s.findOwned(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkIfMatch(ctx, link); err != nil {
+		return nil, err
+	}
+	settings := model.SettingsOf(link)
+	if req.OriginalURL != nil {
+		settings.Original = *req.OriginalURL
+	}
+	if req.ForwardQuery != nil {
+		settings.ForwardQuery = *req.ForwardQuery
+	}
+	if req.QueryConflict != nil {
+		settings.QueryConflict = *req.QueryConflict
+	}
+	if req.Wildcard != nil {
+		settings.Wildcard = *req.Wildcard
+	}
+	if req.CampaignID != nil {
+		settings.CampaignID = *req.CampaignID
+	}
+	if req.NoTracking != nil {
+		settings.NoTracking = *req.NoTracking
+	}
+	return s.applySettings(ctx, link, settings, model.RevisionActionUpdate, 0)
+}
+
+// History returns every revision of a link, oldest first.
+func (s *URLServiceImpl) History(ctx *gofr.Context, code string) ([]model.Revision, error) {
+	if s.Revisions == nil {
+		return nil, ErrHistoryDisabled
+	}
+	if _, err := s.findOwned(ctx, code); err != nil {
+		return nil, err
+	}
+	revisions, err := s.Revisions.FindByShortCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Number < revisions[j].Number
+	})
+	return revisions, nil
+}
+
+// Revert restores the settings captured by revision number. The revert is
+// itself recorded as a new revision, so history is never rewritten.
+func (s *URLServiceImpl) Revert(ctx *gofr.Context, code string, number int) (*model.URL, error) {
+	if s.Revisions == nil {
+		return nil, ErrHistoryDisabled
+	}
+	link, err := s.findOwned(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkIfMatch(ctx, link); err != nil {
+		return nil, err
+	}
+	revision, err := s.Revisions.FindOne(ctx, code, number)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.applySettings(ctx, link, revision.Settings, model.RevisionActionRevert, number)
+}
+
+func (s *URLServiceImpl) applySettings(
+	ctx *gofr.Context, link *model.URL, settings model.LinkSettings, action string, revertedFrom int,
+) (*model.URL, error) {
+	if err := validateSettings(settings.Original, settings.QueryConflict); err != nil {
+		return nil, err
+	}
+	if settings.CampaignID != "" && settings.CampaignID != link.CampaignID {
+		if _, err := s.findCampaign(ctx, settings.CampaignID); err != nil {
+			return nil, err
+		}
+	}
+	screening := link.Screening
+	if settings.Original != link.Original {
+		var target string
+		var err error
+		if settings.Original, target, err = s.checkChain(ctx, link.ShortCode, settings.Original); err != nil {
+			return nil, err
+		}
+		if err := s.checkPolicy(ctx, workspaceOf(link.Owner, link.Workspace), target); err != nil {
+			return nil, err
+		}
+		if screening, err = s.screen(settings.Original); err != nil {
+			return nil, err
+		}
+	}
+
+	before := model.SettingsOf(link)
+	ok, err := s.Store.UpdateSettings(ctx, link, settings, screening)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, conflict(ctx)
+	}
+
+	if settings.Original != link.Original && moderationOf(link) == model.ModerationCleared {
+		link.Moderation = nil
+	}
+	link.Screening = screening
+	settings.ApplyTo(link)
+	link.Revision++
+	link.ShortURL = s.Host + link.ShortCode
+	s.recordRevision(ctx, link, action, revertedFrom)
+
+	auditAction := model.AuditLinkUpdate
+	if action == model.RevisionActionRevert {
+		auditAction = model.AuditLinkRevert
+	}
+	s.Audit.Record(ctx, auditAction, "link:"+link.ShortCode, before, settings)
+	s.emit(ctx, model.EventLinkUpdated, link.Owner, link)
+	return link, nil
+}
+
+// recordRevision appends the link's current settings to its history. The
+// link change has already been saved, so a failure here is only logged.
+func (s *URLServiceImpl) recordRevision(ctx *gofr.Context, link *model.URL, action string, revertedFrom int) {
+	if s.Revisions == nil {
+		return
+	}
+	revision := &model.Revision{
+		ShortCode:    link.ShortCode,
+		Number:       link.Revision,
+		Action:       action,
+		RevertedFrom: revertedFrom,
+		Settings:     model.SettingsOf(link),
+		ChangedBy:    middleware.Actor(ctx),
+	}
+	if err := s.Revisions.Insert(ctx, revision); err != nil {
+		ctx.Logger.Errorf("recording revision %d of %s: %v", link.Revision, link.ShortCode, err)
+	}
+}
@@ -0,0 +1,167 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+// notUpdated matches the updated_at of a link that was never changed.
+var notUpdated = bson.M{"$exists": false}
+
+func expectLink(mocks *container.Mocks, link model.URL) {
+	mocks.Mongo.EXPECT().FindOne(gomock.Any(), "urls", bson.M{"short_code": link.ShortCode}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, result any) error {
+			*result.(*model.URL) = link
+			return nil
+		})
+}
+
+func TestURLServiceUpdate(t *testing.T) {
+	newDestination := "https://example.com/new"
+
+	tests := []struct {
+		name          string
+		actor         string
+		modified      int64
+		expectedError error
+	}{
+		{name: "Success - Records Revision", actor: "alice", modified: 1},
+		{name: "Failure - Concurrent Change", actor: "alice", modified: 0, expectedError: service.ErrRevisionConflict},
+		{name: "Failure - Not Owner", actor: "bob", expectedError: mongo.ErrNoDocuments},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContainer, mocks := container.NewMockContainer(t)
+			urlService := service.NewURLService(store.NewURLStore(), "http://localhost:8000/",
+				service.WithRevisions(store.NewRevisionStore()))
+
+			expectLink(mocks, model.URL{
+				ShortCode: "abc123",
+				Original:  "https://example.com/old",
+				Owner:     "alice",
+				Revision:  2,
+			})
+			if tt.actor == "alice" {
+				mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "urls",
+					bson.M{"owner": "alice", "short_code": "abc123", "updated_at": notUpdated, "revision": 2}, gomock.Any()).
+					Return(tt.modified, nil)
+			}
+
+			var recorded *model.Revision
+			if tt.expectedError == nil {
+				mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "revisions", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, document any) (any, error) {
+						recorded = document.(*model.Revision)
+						return "id", nil
+					})
+			}
+
+			ctx := &gofr.Context{Context: actorContext(tt.actor), Container: mockContainer}
+
+			result, err := urlService.Update(ctx, "abc123", &model.UpdateURLRequest{OriginalURL: &newDestination})
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, newDestination, result.Original)
+			assert.Equal(t, 3, result.Revision)
+			assert.Equal(t, 3, recorded.Number)
+			assert.Equal(t, model.RevisionActionUpdate, recorded.Action)
+			assert.Equal(t, "alice", recorded.ChangedBy)
+			assert.Equal(t, newDestination, recorded.Settings.Original)
+		})
+	}
+}
+
+func TestURLServiceRevert(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	urlService := service.NewURLService(store.NewURLStore(), "http://localhost:8000/",
+		service.WithRevisions(store.NewRevisionStore()))
+
+	expectLink(mocks, model.URL{
+		ShortCode: "abc123",
+		Original:  "https://example.com/new",
+		Owner:     "alice",
+		Revision:  3,
+	})
+	mocks.Mongo.EXPECT().FindOne(gomock.Any(), "revisions", bson.M{"short_code": "abc123", "number": 1}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, result any) error {
+			*result.(*model.Revision) = model.Revision{
+				ShortCode: "abc123",
+				Number:    1,
+				Settings:  model.LinkSettings{Original: "https://example.com/old"},
+			}
+			return nil
+		})
+	mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "urls",
+		bson.M{"owner": "alice", "short_code": "abc123", "updated_at": notUpdated, "revision": 3}, gomock.Any()).Return(int64(1), nil)
+
+	var recorded *model.Revision
+	mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "revisions", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, document any) (any, error) {
+			recorded = document.(*model.Revision)
+			return "id", nil
+		})
+
+	ctx := &gofr.Context{Context: actorContext("alice"), Container: mockContainer}
+
+	result, err := urlService.Revert(ctx, "abc123", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/old", result.Original)
+	assert.Equal(t, 4, recorded.Number)
+	assert.Equal(t, model.RevisionActionRevert, recorded.Action)
+	assert.Equal(t, 1, recorded.RevertedFrom)
+}
+
+func TestURLServiceUpdateLegacyLink(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	urlService := service.NewURLService(store.NewURLStore(), "http://localhost:8000/")
+	expectLink(mocks, model.URL{ShortCode: "abc123", Original: "https://example.com/old", Owner: "alice"})
+	// Links stored before revisions were kept have no revision field.
+	mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "urls",
+		bson.M{"owner": "alice", "short_code": "abc123", "updated_at": notUpdated,
+			"revision": bson.M{"$exists": false}}, gomock.Any()).
+		Return(int64(1), nil)
+
+	newDestination := "https://example.com/new"
+	ctx := &gofr.Context{Context: actorContext("alice"), Container: mockContainer}
+
+	result, err := urlService.Update(ctx, "abc123", &model.UpdateURLRequest{OriginalURL: &newDestination})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Revision)
+}
+
+func TestURLServiceHistory(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		mockContainer, _ := container.NewMockContainer(t)
+		urlService := service.NewURLService(store.NewURLStore(), "http://localhost:8000/")
+
+		_, err := urlService.History(&gofr.Context{Context: actorContext("alice"), Container: mockContainer}, "abc123")
+		assert.Equal(t, http.StatusNotImplemented, statusOf(err))
+	})
+
+	t.Run("Not Owner", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		urlService := service.NewURLService(store.NewURLStore(), "http://localhost:8000/",
+			service.WithRevisions(store.NewRevisionStore()))
+		expectLink(mocks, model.URL{ShortCode: "abc123", Owner: "alice"})
+
+		_, err := urlService.History(&gofr.Context{Context: actorContext("bob"), Container: mockContainer}, "abc123")
+		assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+	})
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"strconv"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/hll"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/referrer"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+	"github.com/sksmagr23/url-shortener-gofr/useragent"
+)
+
+var granularities = []string{model.GranularityMinute, model.GranularityHour, model.GranularityDay}
+
+var granularitySizes = map[string]time.Duration{
+	model.GranularityMinute: time.Minute,
+	model.GranularityHour:   time.Hour,
+	model.GranularityDay:    24 * time.Hour,
+}
+
+// BucketStart returns the start of the bucket of the given granularity holding t.
+func BucketStart(granularity string, t time.Time) time.Time {
+	return t.UTC().Truncate(granularitySizes[granularity])
+}
+
+// rollupID identifies a bucket, so that concurrent writers agree on its document.
+func rollupID(code, granularity string, bucket time.Time) string {
+	return code + ":" + granularity + ":" + strconv.FormatInt(bucket.Unix(), 10)
+}
+
+// clickDimensions returns the dimension values a person's click is counted under.
+func clickDimensions(click *model.Click) map[string]string {
+	browserVersion := click.Browser
+	if click.BrowserVersion != "" {
+		browserVersion += " " + click.BrowserVersion
+	}
+	dimensions := map[string]string{
+		model.DimensionReferrer:       referrer.Domain(click.Referrer),
+		model.DimensionBrowser:        click.Browser,
+		model.DimensionBrowserVersion: browserVersion,
+		model.DimensionOS:             click.OS,
+		model.DimensionDevice:         click.Device,
+	}
+	// Clicks recorded without source attribution are left out of those dimensions.
+	if click.Channel != "" {
+		dimensions[model.DimensionSource] = click.Source
+		dimensions[model.DimensionChannel] = click.Channel
+	}
+	return dimensions
+}
+
+// aggregateClicks folds a batch of clicks into one delta per link and bucket.
+func aggregateClicks(clicks []model.Click) []*model.ClickRollup {
+	deltas := make(map[string]*model.ClickRollup)
+	sketches := make(map[string]hll.Sketch)
+	var order []string
+	for i := range clicks {
+		click := &clicks[i]
+		// Bots are only counted by name and device class, so they never skew
+		// visitors or the other dimensions. Anonymous clicks have none at all.
+		dimensions := map[string]string{model.DimensionBot: click.Bot, model.DimensionDevice: useragent.DeviceBot}
+		if click.Bot == "" {
+			dimensions = nil
+			if !click.Anonymous {
+				dimensions = clickDimensions(click)
+			}
+		}
+		for _, granularity := range granularities {
+			bucket := BucketStart(granularity, click.Timestamp)
+			id := rollupID(click.ShortCode, granularity, bucket)
+			delta, ok := deltas[id]
+			if !ok {
+				delta = &model.ClickRollup{
+					ID:          id,
+					ShortCode:   click.ShortCode,
+					Owner:       click.Owner,
+					CampaignID:  click.CampaignID,
+					Granularity: granularity,
+					Bucket:      bucket,
+					Dimensions:  make(map[string]map[string]int64),
+				}
+				deltas[id] = delta
+				sketches[id] = hll.New()
+				order = append(order, id)
+			}
+			if click.Bot != "" {
+				delta.BotClicks++
+			} else {
+				delta.Clicks++
+				if click.Visitor != "" {
+					sketches[id].Add(click.Visitor)
+				}
+			}
+			for dimension, value := range dimensions {
+				if delta.Dimensions[dimension] == nil {
+					delta.Dimensions[dimension] = make(map[string]int64)
+				}
+				delta.Dimensions[dimension][value]++
+			}
+		}
+	}
+	result := make([]*model.ClickRollup, len(order))
+	for i, id := range order {
+		deltas[id].Visitors = registersOf(sketches[id])
+		result[i] = deltas[id]
+	}
+	return result
+}
+
+// sketchOf loads the HyperLogLog registers persisted with a rollup.
+func sketchOf(registers map[string]int) hll.Sketch {
+	sketch := hll.New()
+	for key, rank := range registers {
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= hll.Registers {
+			continue
+		}
+		sketch[index] = uint8(rank)
+	}
+	return sketch
+}
+
+// registersOf converts a sketch to the form persisted with a rollup.
+func registersOf(sketch hll.Sketch) map[string]int {
+	registers := make(map[string]int, len(sketch))
+	for index, rank := range sketch {
+		registers[strconv.Itoa(index)] = int(rank)
+	}
+	return registers
+}
+
+// ClickRetention expires raw clicks and rollups once they are older than their
+// retention. A zero retention keeps data forever. When IPs is set, it also
+// removes the IPs of clicks stored under another anonymization mode.
+type ClickRetention struct {
+	Clicks    *store.ClickStore
+	Rollups   *store.RollupStore
+	IPs       *IPAnonymizer
+	RawClicks time.Duration
+	Rollup    map[string]time.Duration
+}
+
+// Expire deletes expired data. It is run as a GoFr cron job.
+func (r *ClickRetention) Expire(ctx *gofr.Context) {
+	now := time.Now().UTC()
+	if r.RawClicks > 0 {
+		n, err := r.Clicks.DeleteBefore(ctx, now.Add(-r.RawClicks))
+		if err != nil {
+			ctx.Logger.Errorf("expiring raw clicks: %v", err)
+		} else {
+			ctx.Logger.Infof("expired %d raw clicks", n)
+		}
+	}
+	for _, granularity := range granularities {
+		retention := r.Rollup[granularity]
+		if retention <= 0 {
+			continue
+		}
+		n, err := r.Rollups.DeleteBefore(ctx, granularity, now.Add(-retention))
+		if err != nil {
+			ctx.Logger.Errorf("expiring %s rollups: %v", granularity, err)
+		} else {
+			ctx.Logger.Infof("expired %d %s rollups", n, granularity)
+		}
+	}
+	if r.IPs != nil {
+		n, err := r.Clicks.ForgetIPs(ctx, r.IPs.Mode)
+		if err != nil {
+			ctx.Logger.Errorf("removing IPs stored under another anonymization mode: %v", err)
+		} else if n > 0 {
+			ctx.Logger.Infof("removed the IPs of %d clicks stored under another anonymization mode", n)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/referrer"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+	"github.com/sksmagr23/url-shortener-gofr/useragent"
+)
+
+func TestBucketStart(t *testing.T) {
+	clicked := time.Date(2024, 5, 1, 12, 34, 56, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2024, 5, 1, 12, 34, 0, 0, time.UTC), service.BucketStart(model.GranularityMinute, clicked))
+	assert.Equal(t, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), service.BucketStart(model.GranularityHour, clicked))
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), service.BucketStart(model.GranularityDay, clicked))
+}
+
+// ingestRollups runs clicks through the ingester and returns the rollup
+// documents it creates, keyed by granularity.
+func ingestRollups(t *testing.T, clicks []model.Click) map[string]model.ClickRollup {
+	mockContainer, mocks := container.NewMockContainer(t)
+	mocks.Metrics.EXPECT().SetGauge(gomock.Any(), gomock.Any()).AnyTimes()
+	mocks.Metrics.EXPECT().IncrementCounter(gomock.Any(), gomock.Any()).AnyTimes()
+	mocks.Mongo.EXPECT().InsertMany(gomock.Any(), "clicks", gomock.Any()).Return(nil, nil)
+	mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "urls", gomock.Any(), gomock.Any()).Return(nil)
+	mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "click_rollups", gomock.Any(), gomock.Any()).
+		Times(3).Return(int64(0), nil)
+
+	documents := make(map[string]model.ClickRollup)
+	mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "click_rollups", gomock.Any()).Times(3).
+		DoAndReturn(func(_ context.Context, _ string, document any) (any, error) {
+			rollup := document.(model.ClickRollup)
+			documents[rollup.Granularity] = rollup
+			return rollup.ID, nil
+		})
+
+	ingester := service.NewClickIngester(store.NewClickStore(), store.NewURLStore(), store.NewRollupStore(), len(clicks))
+	ingester.BatchSize = len(clicks)
+	ingester.FlushInterval = time.Hour
+	ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+	for _, click := range clicks {
+		ingester.Record(ctx, click)
+	}
+	ingester.Start(1)
+	ingester.Stop()
+	return documents
+}
+
+func TestClickRollupsAndAnalytics(t *testing.T) {
+	clicked := time.Date(2024, 5, 1, 12, 34, 56, 0, time.UTC)
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	// 300 clicks from 200 visitors, a third of them referred by t.co, and 30
+	// Slack unfurls which must not count as visitors.
+	var clicks []model.Click
+	for i := range 300 {
+		click := model.Click{
+			ShortCode: "abc123",
+			Owner:     "alice",
+			Visitor:   fmt.Sprintf("10.0.%d.%d|Mozilla/5.0", i%200/100, i%200%100),
+			Device:    useragent.DeviceDesktop,
+			Source:    referrer.Direct,
+			Channel:   referrer.ChannelDirect,
+			Timestamp: clicked,
+		}
+		if i%2 == 0 {
+			click.Device = useragent.DeviceMobile
+		}
+		if i%3 == 0 {
+			click.Referrer = "https://t.co/xyz"
+			click.Source, click.Channel = "twitter", referrer.ChannelSocial
+		}
+		clicks = append(clicks, click)
+	}
+	for range 30 {
+		clicks = append(clicks, model.Click{ShortCode: "abc123", Owner: "alice", Bot: "Slackbot", Timestamp: clicked})
+	}
+	documents := ingestRollups(t, clicks)
+
+	assert.Len(t, documents, 3)
+	daily := documents[model.GranularityDay]
+	assert.Equal(t, "abc123:day:"+strconv.FormatInt(day.Unix(), 10), daily.ID)
+	assert.Equal(t, day, daily.Bucket)
+	assert.Equal(t, int64(300), daily.Clicks)
+	assert.Equal(t, int64(30), daily.BotClicks)
+	assert.Equal(t, map[string]int64{"Slackbot": 30}, daily.Dimensions[model.DimensionBot])
+	assert.NotEmpty(t, daily.Visitors)
+	// Dots are escaped in stored dimension keys.
+	assert.Equal(t, map[string]int64{"t%2Eco": 100, "direct": 200}, daily.Dimensions[model.DimensionReferrer])
+	assert.Equal(t, map[string]int64{"twitter": 100, "direct": 200}, daily.Dimensions[model.DimensionSource])
+
+	tests := []struct {
+		name          string
+		actor         string
+		expectedError error
+	}{
+		{name: "Success", actor: "alice"},
+		{name: "Failure - Not Owner", actor: "bob", expectedError: mongo.ErrNoDocuments},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContainer, mocks := container.NewMockContainer(t)
+			expectLink(mocks, model.URL{ShortCode: "abc123", Owner: "alice"})
+			if tt.expectedError == nil {
+				mocks.Mongo.EXPECT().Find(gomock.Any(), "click_rollups", bson.M{
+					"short_code":  "abc123",
+					"granularity": model.GranularityDay,
+					"bucket":      bson.M{"$gte": day, "$lt": clicked.Add(time.Hour)},
+				}, gomock.Any()).DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+					*results.(*[]model.ClickRollup) = []model.ClickRollup{daily}
+					return nil
+				})
+			}
+
+			svc := service.NewAnalyticsService(store.NewURLStore(), store.NewRollupStore(), nil)
+			ctx := &gofr.Context{Context: actorContext(tt.actor), Container: mockContainer}
+			result, err := svc.Link(ctx, "abc123", model.AnalyticsQuery{
+				From:    clicked.Add(-time.Hour),
+				To:      clicked.Add(time.Hour),
+				GroupBy: model.DimensionDevice,
+			})
+
+			assert.Equal(t, tt.expectedError, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, model.GranularityDay, result.Granularity)
+			assert.Equal(t, day, result.From)
+			assert.Equal(t, int64(300), result.Clicks)
+			assert.Equal(t, int64(30), result.BotClicks)
+			assert.InDelta(t, 200, result.Uniques, 10)
+			assert.Len(t, result.Series, 1)
+			assert.Equal(t, result.Uniques, result.Series[0].Uniques)
+			assert.Equal(t, []model.DimensionCount{
+				{Value: "direct", Clicks: 200, Percentage: 66.67},
+				{Value: "t.co", Clicks: 100, Percentage: 33.33},
+			}, result.Top[model.DimensionReferrer])
+			assert.Equal(t, []model.DimensionCount{
+				{Value: referrer.ChannelDirect, Clicks: 200, Percentage: 66.67},
+				{Value: referrer.ChannelSocial, Clicks: 100, Percentage: 33.33},
+			}, result.Channels)
+			// Device classes include bot traffic.
+			assert.Equal(t, []model.DimensionCount{
+				{Value: useragent.DeviceDesktop, Clicks: 150, Percentage: 45.45},
+				{Value: useragent.DeviceMobile, Clicks: 150, Percentage: 45.45},
+				{Value: useragent.DeviceBot, Clicks: 30, Percentage: 9.09},
+			}, result.Groups)
+		})
+	}
+}
+
+func TestClickRetentionExpire(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	mocks.Mongo.EXPECT().DeleteMany(gomock.Any(), "clicks", gomock.Any()).Return(int64(5), nil)
+	mocks.Mongo.EXPECT().DeleteMany(gomock.Any(), "click_rollups", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, filter any) (int64, error) {
+			assert.Equal(t, model.GranularityMinute, filter.(bson.M)["granularity"])
+			return 2, nil
+		})
+
+	mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "clicks",
+		bson.M{"ip": bson.M{"$exists": true}, "ip_mode": bson.M{"$ne": service.IPHash}},
+		bson.M{"$unset": bson.M{"ip": "", "ip_mode": ""}}).Return(int64(3), nil)
+
+	anonymizer, err := service.NewIPAnonymizer(service.IPHash, time.Hour)
+	assert.NoError(t, err)
+	retention := &service.ClickRetention{
+		Clicks:    store.NewClickStore(),
+		Rollups:   store.NewRollupStore(),
+		IPs:       anonymizer,
+		RawClicks: 90 * 24 * time.Hour,
+		Rollup:    map[string]time.Duration{model.GranularityMinute: 48 * time.Hour},
+	}
+	retention.Expire(&gofr.Context{Context: context.Background(), Container: mockContainer})
+}
+
+func TestRollupStoreAddReportsFailedInsert(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	insertErr := fmt.Errorf("connection reset")
+	mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "click_rollups", bson.M{"_id": "abc123|hour|1"}, gomock.Any()).
+		Return(int64(0), nil).Times(2)
+	mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "click_rollups", gomock.Any()).Return(nil, insertErr)
+
+	err := store.NewRollupStore().Add(&gofr.Context{Context: context.Background(), Container: mockContainer},
+		&model.ClickRollup{ID: "abc123|hour|1", ShortCode: "abc123", Clicks: 1})
+
+	assert.Equal(t, insertErr, err)
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+// ErrMaliciousDestination rejects links to blocklisted destinations.
+var ErrMaliciousDestination = &apierror.Error{Status: http.StatusBadRequest, Message: "destination is on a blocklist"}
+
+// ErrDestinationBlocked is returned instead of redirecting to a destination
+// that was found to be malicious after the link was created.
+var ErrDestinationBlocked = &apierror.Error{Status: http.StatusForbidden, Message: "this link has been blocked"}
+
+// DestinationWarning is returned by Resolve for suspicious destinations, which
+// are shown behind a warning page instead of redirected to directly. Proceed
+// is the short link address that continues to Destination; only following it
+// counts as a click.
+type DestinationWarning struct {
+	Destination string
+	Reasons     []string
+	Proceed     string
+}
+
+// ProceedParam marks a request for a suspicious link as coming from its
+// warning page. It is removed before the query is forwarded.
+const ProceedParam = "_proceed"
+
+// proceedURL returns the path of the short link with query and ProceedParam.
+func proceedURL(code, suffix string, query url.Values) string {
+	proceed := url.Values{}
+	for key, values := range query {
+		proceed[key] = values
+	}
+	proceed.Set(ProceedParam, "1")
+	path := "/" + code
+	if suffix != "" {
+		path += "/" + suffix
+	}
+	return (&url.URL{Path: path, RawQuery: proceed.Encode()}).String()
+}
+
+func (w *DestinationWarning) Error() string {
+	return "destination flagged as suspicious: " + strings.Join(w.Reasons, ", ")
+}
+
+// Reasons a destination is flagged.
+const (
+	ReasonBlocklistedDomain = "blocklisted domain"
+	ReasonBlocklistedURL    = "blocklisted URL"
+	ReasonIPHost            = "IP address host"
+	ReasonSubdomains        = "excessive subdomains"
+	ReasonPunycode          = "punycode host"
+	ReasonCredentials       = "credentials in URL"
+)
+
+// maxSubdomains is how many labels a host may have in front of its domain
+// before it looks like an attempt to hide the real domain.
+const maxSubdomains = 4
+
+// Screener checks destinations against domain and URL blocklists loaded from
+// local files and a few heuristics.
+type Screener struct {
+	Paths []string
+
+	mu      sync.RWMutex
+	domains map[string]bool
+	urls    map[string]bool
+}
+
+// NewScreener loads the blocklists in paths.
+func NewScreener(paths ...string) (*Screener, error) {
+	s := &Screener{}
+	for _, path := range paths {
+		if path = strings.TrimSpace(path); path != "" {
+			s.Paths = append(s.Paths, path)
+		}
+	}
+	return s, s.Reload()
+}
+
+// Reload reads the blocklist files again. Each line holds a domain, which
+// also covers its subdomains, or a full http(s) URL. Blank lines, "#"
+// comments and the addresses of hosts-file entries are ignored.
+func (s *Screener) Reload() error {
+	domains := make(map[string]bool)
+	urls := make(map[string]bool)
+	for _, path := range s.Paths {
+		if err := readBlocklist(path, domains, urls); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	s.domains, s.urls = domains, urls
+	s.mu.Unlock()
+	return nil
+}
+
+func readBlocklist(path string, domains, urls map[string]bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("reading blocklist: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		entry := fields[len(fields)-1]
+		if strings.HasPrefix(entry, "http://") || strings.HasPrefix(entry, "https://") {
+			if normalized, ok := normalizeBlockedURL(entry); ok {
+				urls[normalized] = true
+			}
+			continue
+		}
+		domains[strings.TrimSuffix(strings.ToLower(entry), ".")] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading blocklist %s: %w", path, err)
+	}
+	return nil
+}
+
+// normalizeBlockedURL reduces a URL to the form blocklist entries are compared in.
+func normalizeBlockedURL(raw string) (string, bool) {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Hostname() == "" {
+		return "", false
+	}
+	path := strings.TrimSuffix(parsed.EscapedPath(), "/")
+	normalized := strings.ToLower(parsed.Hostname()) + path
+	if parsed.RawQuery != "" {
+		normalized += "?" + parsed.RawQuery
+	}
+	return normalized, true
+}
+
+// Screen returns the verdict on destination.
+func (s *Screener) Screen(destination string) *model.Screening {
+	screening := &model.Screening{Verdict: model.VerdictClean, CheckedAt: time.Now().UTC()}
+	parsed, err := url.Parse(destination)
+	if err != nil {
+		return screening
+	}
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+
+	s.mu.RLock()
+	if s.blockedDomain(host) {
+		screening.Reasons = append(screening.Reasons, ReasonBlocklistedDomain)
+	}
+	if normalized, ok := normalizeBlockedURL(destination); ok {
+		withoutQuery, _, _ := strings.Cut(normalized, "?")
+		if s.urls[normalized] || s.urls[withoutQuery] {
+			screening.Reasons = append(screening.Reasons, ReasonBlocklistedURL)
+		}
+	}
+	s.mu.RUnlock()
+	if len(screening.Reasons) > 0 {
+		screening.Verdict = model.VerdictMalicious
+		return screening
+	}
+
+	if isIPHost(host) {
+		screening.Reasons = append(screening.Reasons, ReasonIPHost)
+	}
+	if strings.Count(host, ".") > maxSubdomains+1 {
+		screening.Reasons = append(screening.Reasons, ReasonSubdomains)
+	}
+	// Lookalike hosts, e.g. "apple.com" spelled with a Cyrillic "а", can only
+	// be registered as internationalized, punycode encoded labels.
+	if strings.HasPrefix(host, "xn--") || strings.Contains(host, ".xn--") {
+		screening.Reasons = append(screening.Reasons, ReasonPunycode)
+	}
+	if parsed.User != nil {
+		screening.Reasons = append(screening.Reasons, ReasonCredentials)
+	}
+	if len(screening.Reasons) > 0 {
+		screening.Verdict = model.VerdictSuspicious
+	}
+	return screening
+}
+
+// blockedDomain reports whether host or one of its parent domains is blocklisted.
+func (s *Screener) blockedDomain(host string) bool {
+	for host != "" {
+		if s.domains[host] {
+			return true
+		}
+		_, parent, ok := strings.Cut(host, ".")
+		if !ok {
+			return false
+		}
+		host = parent
+	}
+	return false
+}
+
+// isIPHost reports whether host is an IP address, including the decimal and
+// hexadecimal forms browsers accept for IPv4 (http://3232235777/).
+func isIPHost(host string) bool {
+	if net.ParseIP(host) != nil {
+		return true
+	}
+	if host == "" {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		digits := strings.TrimPrefix(label, "0x")
+		if digits == "" || strings.Trim(digits, "0123456789abcdef") != "" {
+			return false
+		}
+		if digits == label && strings.Trim(label, "0123456789") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// LinkScanner periodically screens existing links again, so destinations
+// that turn malicious after a link was created get blocked.
+type LinkScanner struct {
+	URLs     *store.URLStore
+	Screener *Screener
+	Audit    *Auditor
+}
+
+func NewLinkScanner(urls *store.URLStore, screener *Screener, auditor *Auditor) *LinkScanner {
+	return &LinkScanner{URLs: urls, Screener: screener, Audit: auditor}
+}
+
+// scanPrefixes split a rescan by the first character of the short code, so
+// only a fraction of the links is held in memory at a time.
+const scanPrefixes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-"
+
+// Rescan reloads the blocklists and screens every link. It is run as a cron job.
+func (s *LinkScanner) Rescan(ctx *gofr.Context) {
+	if err := s.Screener.Reload(); err != nil {
+		ctx.Logger.Errorf("reloading blocklists: %v", err)
+	}
+	started := time.Now().UTC()
+	screened, flagged := 0, 0
+	for _, prefix := range scanPrefixes {
+		links, err := s.URLs.FindScreenedBefore(ctx, string(prefix), started)
+		if err != nil {
+			ctx.Logger.Errorf("loading links starting with %c to screen: %v", prefix, err)
+			continue
+		}
+		screened += len(links)
+		flagged += s.rescanLinks(ctx, links)
+	}
+	ctx.Logger.Infof("screened %d links, %d flagged", screened, flagged)
+}
+
+// rescanLinks screens links again and returns how many were flagged.
+func (s *LinkScanner) rescanLinks(ctx *gofr.Context, links []model.URL) int {
+	flagged := 0
+	for i := range links {
+		link := &links[i]
+		screening := s.Screener.Screen(link.Original)
+		if err := s.URLs.SetScreening(ctx, link.ShortCode, screening); err != nil {
+			ctx.Logger.Errorf("storing screening of %s: %v", link.ShortCode, err)
+			continue
+		}
+		if verdictOf(link.Screening) != screening.Verdict {
+			s.Audit.Record(ctx, model.AuditLinkScreen, "link:"+link.ShortCode, link.Screening, screening)
+			// A new verdict puts a cleared link back in the moderation queue.
+			if moderationOf(link) == model.ModerationCleared && screening.Verdict != model.VerdictClean {
+				if err := s.URLs.SetModeration(ctx, link.ShortCode, nil); err != nil {
+					ctx.Logger.Errorf("reopening moderation of %s: %v", link.ShortCode, err)
+				}
+			}
+		}
+		if screening.Verdict != model.VerdictClean {
+			flagged++
+		}
+	}
+	return flagged
+}
+
+// verdictOf treats links that were never screened as clean.
+func verdictOf(screening *model.Screening) string {
+	if screening == nil {
+		return model.VerdictClean
+	}
+	return screening.Verdict
+}
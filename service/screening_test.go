@@ -0,0 +1,222 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+// newScreener returns a screener over a blocklist file holding lines.
+func newScreener(t *testing.T, lines string) (*service.Screener, string) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	assert.NoError(t, os.WriteFile(path, []byte(lines), 0o600))
+	screener, err := service.NewScreener(path)
+	assert.NoError(t, err)
+	return screener, path
+}
+
+func TestScreenerScreen(t *testing.T) {
+	screener, _ := newScreener(t, `# local blocklist
+evil.example
+0.0.0.0 tracker.bad   # hosts file entry
+https://phish.example.net/login/
+`)
+
+	tests := []struct {
+		destination string
+		verdict     string
+		reasons     []string
+	}{
+		{destination: "https://example.com/page", verdict: model.VerdictClean},
+		{destination: "https://evil.example/", verdict: model.VerdictMalicious, reasons: []string{service.ReasonBlocklistedDomain}},
+		{destination: "https://login.EVIL.example/x", verdict: model.VerdictMalicious, reasons: []string{service.ReasonBlocklistedDomain}},
+		{destination: "http://tracker.bad/pixel", verdict: model.VerdictMalicious, reasons: []string{service.ReasonBlocklistedDomain}},
+		{destination: "https://phish.example.net/login?user=1", verdict: model.VerdictMalicious, reasons: []string{service.ReasonBlocklistedURL}},
+		{destination: "https://phish.example.net/about", verdict: model.VerdictClean},
+		{destination: "http://192.168.0.1/admin", verdict: model.VerdictSuspicious, reasons: []string{service.ReasonIPHost}},
+		{destination: "http://[2001:db8::1]/", verdict: model.VerdictSuspicious, reasons: []string{service.ReasonIPHost}},
+		{destination: "http://3232235777/", verdict: model.VerdictSuspicious, reasons: []string{service.ReasonIPHost}},
+		{destination: "http://0xc0.0xa8.0.1/", verdict: model.VerdictSuspicious, reasons: []string{service.ReasonIPHost}},
+		{destination: "https://0xford.com/", verdict: model.VerdictClean},
+		{destination: "https://a.b.c.d.example.com/", verdict: model.VerdictClean},
+		{destination: "https://secure.login.a.b.c.example.com/", verdict: model.VerdictSuspicious, reasons: []string{service.ReasonSubdomains}},
+		{destination: "https://xn--pple-43d.com/", verdict: model.VerdictSuspicious, reasons: []string{service.ReasonPunycode}},
+		{destination: "https://example.com@203.0.113.9/", verdict: model.VerdictSuspicious,
+			reasons: []string{service.ReasonIPHost, service.ReasonCredentials}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.destination, func(t *testing.T) {
+			screening := screener.Screen(tt.destination)
+			assert.Equal(t, tt.verdict, screening.Verdict)
+			assert.Equal(t, tt.reasons, screening.Reasons)
+			assert.False(t, screening.CheckedAt.IsZero())
+		})
+	}
+}
+
+func TestNewScreenerMissingFile(t *testing.T) {
+	_, err := service.NewScreener(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
+
+func TestURLServiceScreening(t *testing.T) {
+	screener, _ := newScreener(t, "evil.example\n")
+
+	t.Run("Create Rejects Malicious", func(t *testing.T) {
+		mockContainer, _ := container.NewMockContainer(t)
+		svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/", service.WithScreening(screener))
+
+		_, err := svc.Create(&gofr.Context{Context: actorContext("alice"), Container: mockContainer},
+			&model.CreateURLRequest{OriginalURL: "https://evil.example/login"})
+
+		assert.Equal(t, service.ErrMaliciousDestination, err)
+	})
+
+	t.Run("Create Stores Verdict", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "urls", gomock.Any()).Return("id", nil)
+		svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/", service.WithScreening(screener))
+
+		link, err := svc.Create(&gofr.Context{Context: actorContext("alice"), Container: mockContainer},
+			&model.CreateURLRequest{OriginalURL: "http://192.168.0.1/"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.VerdictSuspicious, link.Screening.Verdict)
+	})
+
+	t.Run("Update Stores Verdict With Settings", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectLink(mocks, model.URL{ShortCode: "abc123", Original: "https://example.com/", Owner: "alice", Revision: 1,
+			Screening: &model.Screening{Verdict: model.VerdictClean}})
+		mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "urls", gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, _ any, update any) (int64, error) {
+				screening := update.(bson.M)["$set"].(bson.M)["screening"].(*model.Screening)
+				assert.Equal(t, model.VerdictSuspicious, screening.Verdict)
+				return 1, nil
+			})
+		svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/", service.WithScreening(screener))
+		destination := "http://192.168.0.1/"
+
+		link, err := svc.Update(&gofr.Context{Context: actorContext("alice"), Container: mockContainer},
+			"abc123", &model.UpdateURLRequest{OriginalURL: &destination})
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.VerdictSuspicious, link.Screening.Verdict)
+	})
+
+	t.Run("Update Drops Clearing", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectLink(mocks, model.URL{ShortCode: "abc123", Original: "http://192.168.0.1/", Owner: "alice", Revision: 1,
+			Screening:  &model.Screening{Verdict: model.VerdictSuspicious},
+			Moderation: &model.Moderation{Status: model.ModerationCleared}})
+		mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "urls", gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, _ any, update any) (int64, error) {
+				assert.Equal(t, bson.M{"moderation": ""}, update.(bson.M)["$unset"])
+				return 1, nil
+			})
+		svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/", service.WithScreening(screener))
+		destination := "http://192.168.0.2/"
+
+		link, err := svc.Update(&gofr.Context{Context: actorContext("alice"), Container: mockContainer},
+			"abc123", &model.UpdateURLRequest{OriginalURL: &destination})
+
+		assert.NoError(t, err)
+		assert.Nil(t, link.Moderation)
+	})
+
+	t.Run("Redirect Blocked", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectLink(mocks, model.URL{ShortCode: "abc123", Original: "https://evil.example/",
+			Screening: &model.Screening{Verdict: model.VerdictMalicious}})
+		svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/")
+
+		_, err := svc.Resolve(&gofr.Context{Context: context.Background(), Container: mockContainer}, "abc123", "", nil)
+
+		assert.Equal(t, service.ErrDestinationBlocked, err)
+	})
+
+	t.Run("Redirect Warns", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectLink(mocks, model.URL{ShortCode: "abc123", Original: "http://192.168.0.1/",
+			Screening: &model.Screening{Verdict: model.VerdictSuspicious, Reasons: []string{service.ReasonIPHost}}})
+		// No click is counted for the warning page itself.
+		svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/")
+
+		_, err := svc.Resolve(&gofr.Context{Context: context.Background(), Container: mockContainer}, "abc123", "", nil)
+
+		var warning *service.DestinationWarning
+		if assert.True(t, errors.As(err, &warning)) {
+			assert.Equal(t, "http://192.168.0.1/", warning.Destination)
+			assert.Equal(t, []string{service.ReasonIPHost}, warning.Reasons)
+			assert.Equal(t, "/abc123?_proceed=1", warning.Proceed)
+		}
+	})
+
+	t.Run("Redirect Proceeds Past Warning", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectLink(mocks, model.URL{ShortCode: "abc123", Original: "http://192.168.0.1/", ForwardQuery: true,
+			Screening: &model.Screening{Verdict: model.VerdictSuspicious, Reasons: []string{service.ReasonIPHost}}})
+		mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "urls", bson.M{"short_code": "abc123"}, gomock.Any()).Return(nil)
+		svc := service.NewURLService(store.NewURLStore(), "http://sho.rt/")
+
+		destination, err := svc.Resolve(&gofr.Context{Context: context.Background(), Container: mockContainer},
+			"abc123", "", url.Values{"ref": {"mail"}, service.ProceedParam: {"1"}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "http://192.168.0.1/?ref=mail", destination)
+	})
+}
+
+func TestLinkScannerRescan(t *testing.T) {
+	screener, path := newScreener(t, "")
+	// The blocklist is updated after startup; the rescan picks it up.
+	assert.NoError(t, os.WriteFile(path, []byte("evil.example\n"), 0o600))
+
+	mockContainer, mocks := container.NewMockContainer(t)
+	// Links are loaded one short code prefix at a time.
+	links := map[string][]model.URL{
+		"^a": {{ShortCode: "abc123", Original: "https://evil.example/",
+			Screening: &model.Screening{Verdict: model.VerdictClean}}},
+		"^x": {{ShortCode: "xyz789", Original: "https://example.com/"}},
+	}
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "urls", gomock.Any(), gomock.Any()).Times(64).
+		DoAndReturn(func(_ context.Context, _ string, filter any, results any) error {
+			prefix := filter.(bson.M)["short_code"].(bson.M)["$regex"].(string)
+			*results.(*[]model.URL) = links[prefix]
+			return nil
+		})
+	verdicts := make(map[string]string)
+	mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "urls", gomock.Any(), gomock.Any()).Times(2).
+		DoAndReturn(func(_ context.Context, _ string, filter any, update any) error {
+			screening := update.(bson.M)["$set"].(bson.M)["screening"].(*model.Screening)
+			verdicts[filter.(bson.M)["short_code"].(string)] = screening.Verdict
+			return nil
+		})
+	// Only the link whose verdict changed is audited.
+	mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "audit_log", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, document any) (any, error) {
+			entry := document.(*model.AuditEntry)
+			assert.Equal(t, model.AuditLinkScreen, entry.Action)
+			assert.Equal(t, "link:abc123", entry.Resource)
+			return entry.ID, nil
+		})
+
+	scanner := service.NewLinkScanner(store.NewURLStore(), screener, service.NewAuditor(store.NewAuditStore()))
+	scanner.Rescan(&gofr.Context{Context: context.Background(), Container: mockContainer})
+
+	assert.Equal(t, map[string]string{"abc123": model.VerdictMalicious, "xyz789": model.VerdictClean}, verdicts)
+}
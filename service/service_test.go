@@ -104,7 +104,7 @@ func TestURLServiceCreate(t *testing.T) {
 				Container: mockContainer,
 			}
 
-			result, err := urlService.Create(ctx, tt.originalURL)
+			result, err := urlService.Create(ctx, &model.CreateURLRequest{OriginalURL: tt.originalURL})
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -185,16 +185,11 @@ func TestURLServiceGetByShortCode(t *testing.T) {
 					gomock.Any(),
 				).Return(tt.mockError)
 			} else {
-				mocks.Mongo.EXPECT().FindOne(
-					gomock.Any(),
-					"urls",
-					bson.M{"short_code": tt.shortCode},
-					gomock.Any(),
-				).Return(nil)
+				expectLink(mocks, model.URL{ShortCode: tt.shortCode, Owner: "alice"})
 			}
 
 			ctx := &gofr.Context{
-				Context:   context.Background(),
+				Context:   actorContext("alice"),
 				Container: mockContainer,
 			}
 
@@ -235,7 +230,7 @@ func TestURLServiceCreateWithDatabaseError(t *testing.T) {
 		Container: mockContainer,
 	}
 
-	result, err := urlService.Create(ctx, "https://example.com/test")
+	result, err := urlService.Create(ctx, &model.CreateURLRequest{OriginalURL: "https://example.com/test"})
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "database connection failed")
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+	"golang.org/x/oauth2"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/oidc"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+var (
+	ErrLoginInvalid = &apierror.Error{Status: http.StatusBadRequest, Message: "sign-in expired or was already completed, start again"}
+	ErrLoginFailed  = &apierror.Error{Status: http.StatusUnauthorized, Message: "identity provider did not confirm the sign-in"}
+	ErrTokenInvalid = &apierror.Error{Status: http.StatusUnauthorized, Message: "invalid or expired token"}
+)
+
+// loginTTL is how long a user has to complete a sign-in at the provider.
+const loginTTL = 10 * time.Minute
+
+// RoleGrant gives the members of an identity provider group a role in a workspace.
+type RoleGrant struct {
+	Group     string
+	Workspace string
+	Role      string
+}
+
+// ParseRoleGrants parses a comma-separated list of group=workspace:role
+// entries, such as "marketing=ws1:editor,marketing-leads=ws1:admin".
+func ParseRoleGrants(s string) ([]RoleGrant, error) {
+	var grants []RoleGrant
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		group, target, ok := strings.Cut(entry, "=")
+		workspace, role, ok2 := strings.Cut(target, ":")
+		if !ok || !ok2 || group == "" || workspace == "" || roleRank[role] == 0 {
+			return nil, fmt.Errorf("role mapping %q: want group=workspace:role", entry)
+		}
+		grants = append(grants, RoleGrant{Group: group, Workspace: workspace, Role: role})
+	}
+	return grants, nil
+}
+
+// SSOConfig maps ID token claims to users and workspace roles.
+type SSOConfig struct {
+	UserClaim  string // claim holding the user ID, "sub" when empty
+	GroupClaim string // claim listing the user's groups, "groups" when empty
+	Grants     []RoleGrant
+}
+
+type SSOService interface {
+	Login(ctx *gofr.Context, loginHint string) (string, error)
+	Callback(ctx *gofr.Context, code, state string) (*model.Session, error)
+	Authenticate(ctx context.Context, token string) (string, error)
+}
+
+type SSOServiceImpl struct {
+	Provider   *oidc.Provider
+	Logins     *store.SSOLoginStore
+	Workspaces *store.WorkspaceStore
+	Config     SSOConfig
+	Audit      *Auditor
+}
+
+func NewSSOService(provider *oidc.Provider, logins *store.SSOLoginStore, workspaces *store.WorkspaceStore,
+	config SSOConfig, auditor *Auditor) SSOService {
+	if config.UserClaim == "" {
+		config.UserClaim = "sub"
+	}
+	if config.GroupClaim == "" {
+		config.GroupClaim = "groups"
+	}
+	return &SSOServiceImpl{Provider: provider, Logins: logins, Workspaces: workspaces, Config: config, Audit: auditor}
+}
+
+// Login starts a sign-in and returns the provider URL to send the user to.
+func (s *SSOServiceImpl) Login(ctx *gofr.Context, loginHint string) (string, error) {
+	if _, err := s.Logins.DeleteExpired(ctx, time.Now().UTC()); err != nil {
+		ctx.Logger.Errorf("deleting expired sign-ins: %v", err)
+	}
+	state := randomHex(32)
+	login := &model.SSOLogin{
+		ID:        hashToken(state),
+		Nonce:     randomHex(16),
+		Verifier:  oauth2.GenerateVerifier(),
+		ExpiresAt: time.Now().UTC().Add(loginTTL),
+	}
+	if err := s.Logins.Insert(ctx, login); err != nil {
+		return "", err
+	}
+	return s.Provider.AuthCodeURL(state, login.Nonce, login.Verifier, loginHint), nil
+}
+
+// Callback completes the sign-in the provider sent the user back from. It
+// brings the user's workspace roles in line with their groups and returns
+// the ID token to use as a bearer token.
+func (s *SSOServiceImpl) Callback(ctx *gofr.Context, code, state string) (*model.Session, error) {
+	login, err := s.Logins.Take(ctx, hashToken(state))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrLoginInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(login.ExpiresAt) {
+		return nil, ErrLoginInvalid
+	}
+
+	token, err := s.Provider.Exchange(ctx, code, login.Verifier)
+	if err != nil {
+		ctx.Logger.Errorf("completing sign-in: %v", err)
+		return nil, ErrLoginFailed
+	}
+	if token.Nonce != login.Nonce {
+		return nil, ErrLoginFailed
+	}
+	user := s.userOf(token)
+	if user == "" {
+		ctx.Logger.Errorf("completing sign-in: ID token has no usable %q claim", s.Config.UserClaim)
+		return nil, ErrLoginFailed
+	}
+
+	memberships, err := s.syncMemberships(ctx, user, claimStrings(token.Claims[s.Config.GroupClaim]))
+	if err != nil {
+		return nil, err
+	}
+	session := &model.Session{
+		UserID:      user,
+		Token:       token.Raw,
+		TokenType:   "Bearer",
+		ExpiresAt:   token.Expiry,
+		Memberships: memberships,
+	}
+	s.Audit.Record(ctx, model.AuditUserLogin, "user:"+user, nil, map[string]any{"user_id": user})
+	return session, nil
+}
+
+// Authenticate returns the user a bearer token was issued to.
+func (s *SSOServiceImpl) Authenticate(ctx context.Context, raw string) (string, error) {
+	token, err := s.Provider.Verify(ctx, raw)
+	if err != nil {
+		return "", ErrTokenInvalid
+	}
+	user := s.userOf(token)
+	if user == "" {
+		return "", ErrTokenInvalid
+	}
+	return user, nil
+}
+
+// userOf returns the user ID the token names, or "" when it names none. An
+// email address only counts once the provider has verified it; anyone could
+// otherwise sign up at the provider with a teammate's address and act as them.
+func (s *SSOServiceImpl) userOf(token *oidc.IDToken) string {
+	if s.Config.UserClaim == "sub" {
+		return token.Subject
+	}
+	if s.Config.UserClaim == "email" && !emailVerified(token.Claims["email_verified"]) {
+		return ""
+	}
+	user, _ := token.Claims[s.Config.UserClaim].(string)
+	return user
+}
+
+// emailVerified reads the email_verified claim, which some providers send as
+// the string "true" rather than a boolean.
+func emailVerified(claim any) bool {
+	switch v := claim.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// syncMemberships grants user the roles its groups map to and withdraws
+// sign-in granted roles its groups no longer map to. When several groups
+// map to one workspace the highest role wins. A workspace never loses its
+// last owner this way.
+func (s *SSOServiceImpl) syncMemberships(ctx *gofr.Context, user string, groups []string) ([]model.Membership, error) {
+	inGroup := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		inGroup[group] = true
+	}
+	wanted := map[string]string{}
+	for _, grant := range s.Config.Grants {
+		if inGroup[grant.Group] && roleRank[grant.Role] > roleRank[wanted[grant.Workspace]] {
+			wanted[grant.Workspace] = grant.Role
+		}
+	}
+
+	existing, err := s.Workspaces.FindMemberships(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	for i := range existing {
+		member := &existing[i]
+		role, mapped := wanted[member.WorkspaceID]
+		delete(wanted, member.WorkspaceID)
+		if member.Source != model.MembershipSSO || role == member.Role {
+			continue
+		}
+		if member.Role == model.RoleOwner {
+			err := releaseOwner(ctx, s.Workspaces, member.WorkspaceID)
+			if errors.Is(err, ErrLastOwner) {
+				ctx.Logger.Infof("keeping %s as last owner of workspace %s", user, member.WorkspaceID)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !mapped {
+			if err := s.Workspaces.RemoveMember(ctx, member.WorkspaceID, user); err != nil {
+				return nil, err
+			}
+			s.Audit.Record(ctx, model.AuditWorkspaceRemove, "workspace:"+member.WorkspaceID, member, nil)
+			continue
+		}
+		if err := s.Workspaces.SetRole(ctx, member.WorkspaceID, user, role); err != nil {
+			return nil, err
+		}
+		if role == model.RoleOwner {
+			if err := s.Workspaces.AddOwner(ctx, member.WorkspaceID); err != nil {
+				return nil, err
+			}
+		}
+		before := *member
+		member.Role = role
+		s.Audit.Record(ctx, model.AuditWorkspaceRole, "workspace:"+member.WorkspaceID, before, member)
+	}
+
+	for workspaceID, role := range wanted {
+		if _, err := s.Workspaces.FindByID(ctx, workspaceID); errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.Logger.Errorf("role mapping names unknown workspace %s", workspaceID)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		member := &model.Membership{WorkspaceID: workspaceID, UserID: user, Role: role, Source: model.MembershipSSO}
+		if err := s.Workspaces.AddMember(ctx, member); err != nil {
+			return nil, err
+		}
+		if role == model.RoleOwner {
+			if err := s.Workspaces.AddOwner(ctx, workspaceID); err != nil {
+				return nil, err
+			}
+		}
+		s.Audit.Record(ctx, model.AuditWorkspaceJoin, "workspace:"+workspaceID, nil, member)
+	}
+	return s.Workspaces.FindMemberships(ctx, user)
+}
+
+// claimStrings reads a claim that may hold one string or a list of them.
+func claimStrings(claim any) []string {
+	switch value := claim.(type) {
+	case string:
+		return []string{value}
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/oidc"
+	"github.com/sksmagr23/url-shortener-gofr/oidc/oidctest"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+var testGrants = []service.RoleGrant{
+	{Group: "marketing", Workspace: "ws1", Role: model.RoleEditor},
+	{Group: "marketing-leads", Workspace: "ws1", Role: model.RoleAdmin},
+}
+
+func newSSOService(t *testing.T, config service.SSOConfig) (*oidctest.Server, service.SSOService) {
+	t.Helper()
+	idp := oidctest.NewServer("shortener")
+	t.Cleanup(idp.Close)
+	provider, err := oidc.Discover(context.Background(), oidc.Config{
+		Issuer:      idp.URL,
+		ClientID:    "shortener",
+		RedirectURL: "http://sho.rt/auth/callback",
+	}, nil)
+	if err != nil {
+		t.Fatalf("discovering mock provider: %v", err)
+	}
+	return idp, service.NewSSOService(provider, store.NewSSOLoginStore(), store.NewWorkspaceStore(), config, nil)
+}
+
+// signIn starts a sign-in as subject, completes it at the mock provider and
+// returns the code and state the provider sends back to the callback.
+func signIn(t *testing.T, sso service.SSOService, mocks *container.Mocks, ctx *gofr.Context, subject string) (string, string) {
+	t.Helper()
+	var login *model.SSOLogin
+	mocks.Mongo.EXPECT().DeleteMany(gomock.Any(), "sso_logins", gomock.Any()).Return(int64(0), nil)
+	mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "sso_logins", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, document any) (any, error) {
+			login = document.(*model.SSOLogin)
+			return nil, nil
+		})
+
+	authURL, err := sso.Login(ctx, subject)
+	if err != nil {
+		t.Fatalf("starting sign-in: %v", err)
+	}
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("signing in: %v", err)
+	}
+	resp.Body.Close()
+	callback, _ := url.Parse(resp.Header.Get("Location"))
+	state := callback.Query().Get("state")
+
+	assert.NotEqual(t, state, login.ID, "only a hash of the state is stored")
+	mocks.Mongo.EXPECT().FindOne(gomock.Any(), "sso_logins", bson.M{"_id": login.ID}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, result any) error {
+			*result.(*model.SSOLogin) = *login
+			return nil
+		})
+	mocks.Mongo.EXPECT().DeleteOne(gomock.Any(), "sso_logins", bson.M{"_id": login.ID}).Return(int64(1), nil)
+	return callback.Query().Get("code"), state
+}
+
+func expectMemberships(mocks *container.Mocks, user string, before, after []model.Membership) {
+	for _, memberships := range [][]model.Membership{before, after} {
+		mocks.Mongo.EXPECT().Find(gomock.Any(), "workspace_members", bson.M{"user_id": user}, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+				*results.(*[]model.Membership) = memberships
+				return nil
+			})
+	}
+}
+
+func TestSSOServiceCallback(t *testing.T) {
+	idp, sso := newSSOService(t, service.SSOConfig{Grants: testGrants})
+	idp.Claims["groups"] = []string{"marketing", "sales"}
+	mockContainer, mocks := container.NewMockContainer(t)
+	ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+
+	code, state := signIn(t, sso, mocks, ctx, "bob")
+	joined := model.Membership{WorkspaceID: "ws1", UserID: "bob", Role: model.RoleEditor, Source: model.MembershipSSO}
+	expectMemberships(mocks, "bob", nil, []model.Membership{joined})
+	mocks.Mongo.EXPECT().FindOne(gomock.Any(), "workspaces", bson.M{"_id": "ws1"}, gomock.Any()).Return(nil)
+	mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "workspace_members", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, document any) (any, error) {
+			member := document.(*model.Membership)
+			assert.Equal(t, joined, model.Membership{WorkspaceID: member.WorkspaceID, UserID: member.UserID,
+				Role: member.Role, Source: member.Source})
+			return nil, nil
+		})
+
+	session, err := sso.Callback(ctx, code, state)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "bob", session.UserID)
+		assert.Equal(t, "Bearer", session.TokenType)
+		assert.Equal(t, []model.Membership{joined}, session.Memberships)
+		user, err := sso.Authenticate(context.Background(), session.Token)
+		assert.NoError(t, err)
+		assert.Equal(t, "bob", user)
+	}
+
+	t.Run("Replayed", func(t *testing.T) {
+		mocks.Mongo.EXPECT().FindOne(gomock.Any(), "sso_logins", gomock.Any(), gomock.Any()).Return(mongo.ErrNoDocuments)
+
+		_, err := sso.Callback(ctx, code, state)
+
+		assert.Equal(t, service.ErrLoginInvalid, err)
+	})
+}
+
+func TestSSOServiceRoleSync(t *testing.T) {
+	tests := []struct {
+		name     string
+		groups   []string
+		existing []model.Membership
+		expect   func(mocks *container.Mocks)
+	}{
+		{
+			name:   "Highest Role Wins",
+			groups: []string{"marketing", "marketing-leads"},
+			expect: func(mocks *container.Mocks) {
+				mocks.Mongo.EXPECT().FindOne(gomock.Any(), "workspaces", bson.M{"_id": "ws1"}, gomock.Any()).Return(nil)
+				mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "workspace_members", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, document any) (any, error) {
+						assert.Equal(t, model.RoleAdmin, document.(*model.Membership).Role)
+						return nil, nil
+					})
+			},
+		},
+		{
+			name:     "Updates Granted Role",
+			groups:   []string{"marketing-leads"},
+			existing: []model.Membership{{WorkspaceID: "ws1", UserID: "bob", Role: model.RoleEditor, Source: model.MembershipSSO}},
+			expect: func(mocks *container.Mocks) {
+				mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "workspace_members",
+					bson.M{"workspace_id": "ws1", "user_id": "bob"}, bson.M{"$set": bson.M{"role": model.RoleAdmin}}).Return(nil)
+			},
+		},
+		{
+			name:     "Withdraws Unmapped Role",
+			groups:   []string{"sales"},
+			existing: []model.Membership{{WorkspaceID: "ws1", UserID: "bob", Role: model.RoleEditor, Source: model.MembershipSSO}},
+			expect: func(mocks *container.Mocks) {
+				mocks.Mongo.EXPECT().DeleteOne(gomock.Any(), "workspace_members",
+					bson.M{"workspace_id": "ws1", "user_id": "bob"}).Return(int64(1), nil)
+			},
+		},
+		{
+			name:     "Keeps Invited Membership",
+			groups:   []string{"marketing-leads"},
+			existing: []model.Membership{{WorkspaceID: "ws1", UserID: "bob", Role: model.RoleViewer}},
+			expect:   func(*container.Mocks) {},
+		},
+		{
+			name:     "Keeps Last Owner",
+			existing: []model.Membership{{WorkspaceID: "ws2", UserID: "bob", Role: model.RoleOwner, Source: model.MembershipSSO}},
+			expect: func(mocks *container.Mocks) {
+				mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "workspaces",
+					bson.M{"_id": "ws2", "owners": bson.M{"$gt": 1}}, gomock.Any()).Return(int64(0), nil)
+			},
+		},
+		{
+			name:   "Skips Unknown Workspace",
+			groups: []string{"marketing"},
+			expect: func(mocks *container.Mocks) {
+				mocks.Mongo.EXPECT().FindOne(gomock.Any(), "workspaces", bson.M{"_id": "ws1"}, gomock.Any()).
+					Return(mongo.ErrNoDocuments)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp, sso := newSSOService(t, service.SSOConfig{Grants: testGrants})
+			idp.Claims["groups"] = tt.groups
+			mockContainer, mocks := container.NewMockContainer(t)
+			ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+			code, state := signIn(t, sso, mocks, ctx, "bob")
+			expectMemberships(mocks, "bob", tt.existing, nil)
+			tt.expect(mocks)
+
+			_, err := sso.Callback(ctx, code, state)
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSSOServiceAuthenticate(t *testing.T) {
+	idp, sso := newSSOService(t, service.SSOConfig{UserClaim: "email"})
+
+	t.Run("User Claim", func(t *testing.T) {
+		user, err := sso.Authenticate(context.Background(), idp.Sign("00u1", map[string]any{"email": "bob@acme.example", "email_verified": true}))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "bob@acme.example", user)
+	})
+
+	t.Run("Unverified Email", func(t *testing.T) {
+		for _, verified := range []any{nil, false, "false"} {
+			claims := map[string]any{"email": "bob@acme.example"}
+			if verified != nil {
+				claims["email_verified"] = verified
+			}
+
+			_, err := sso.Authenticate(context.Background(), idp.Sign("00u1", claims))
+
+			assert.Equal(t, service.ErrTokenInvalid, err, "email_verified %v", verified)
+		}
+	})
+
+	t.Run("Missing User Claim", func(t *testing.T) {
+		_, err := sso.Authenticate(context.Background(), idp.Sign("00u1", nil))
+
+		assert.Equal(t, service.ErrTokenInvalid, err)
+	})
+
+	t.Run("Forged", func(t *testing.T) {
+		other := oidctest.NewServer("shortener")
+		defer other.Close()
+
+		_, err := sso.Authenticate(context.Background(), other.Sign("bob", map[string]any{"iss": idp.URL, "email": "bob@acme.example", "email_verified": true}))
+
+		assert.Equal(t, service.ErrTokenInvalid, err)
+	})
+}
+
+func TestParseRoleGrants(t *testing.T) {
+	grants, err := service.ParseRoleGrants(" marketing=ws1:editor, marketing-leads=ws1:admin ,")
+	assert.NoError(t, err)
+	assert.Equal(t, testGrants, grants)
+
+	for _, bad := range []string{"marketing", "marketing=ws1", "marketing=ws1:boss", "=ws1:editor", "marketing=:editor"} {
+		_, err := service.ParseRoleGrants(bad)
+		assert.Error(t, err, bad)
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+// ClickStream fans link.clicked events out to live viewers of each link. It
+// only sees the redirects served by this instance.
+type ClickStream struct {
+	mu          sync.Mutex
+	subscribers map[string]map[*Subscription]struct{}
+}
+
+// Subscription receives the click events of one link until it is closed.
+type Subscription struct {
+	C <-chan model.Event
+
+	events  chan model.Event
+	code    string
+	stream  *ClickStream
+	dropped atomic.Int64
+}
+
+func NewClickStream() *ClickStream {
+	return &ClickStream{subscribers: make(map[string]map[*Subscription]struct{})}
+}
+
+// Notify forwards click events to the link's subscribers. A subscriber whose
+// buffer is full misses the event rather than slowing down the redirect.
+func (s *ClickStream) Notify(_ *gofr.Context, event model.Event) {
+	if event.Type != model.EventLinkClicked {
+		return
+	}
+	data, ok := event.Data.(model.ClickData)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subscribers[data.ShortCode] {
+		select {
+		case sub.events <- event:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// Subscribe starts receiving the clicks of code, buffering up to buffer events.
+func (s *ClickStream) Subscribe(code string, buffer int) *Subscription {
+	events := make(chan model.Event, buffer)
+	sub := &Subscription{C: events, events: events, code: code, stream: s}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subscribers[code] == nil {
+		s.subscribers[code] = make(map[*Subscription]struct{})
+	}
+	s.subscribers[code][sub] = struct{}{}
+	return sub
+}
+
+// Close stops the subscription and closes its channel.
+func (sub *Subscription) Close() {
+	s := sub.stream
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscribers[sub.code][sub]; !ok {
+		return
+	}
+	delete(s.subscribers[sub.code], sub)
+	if len(s.subscribers[sub.code]) == 0 {
+		delete(s.subscribers, sub.code)
+	}
+	close(sub.events)
+}
+
+// Dropped returns how many events the subscriber missed because it fell behind.
+func (sub *Subscription) Dropped() int64 {
+	return sub.dropped.Load()
+}
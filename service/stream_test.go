@@ -0,0 +1,34 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+)
+
+func TestClickStream(t *testing.T) {
+	stream := service.NewClickStream()
+	first := stream.Subscribe("abc123", 1)
+	other := stream.Subscribe("xyz789", 1)
+
+	click := model.Event{ID: "e1", Type: model.EventLinkClicked, Data: model.ClickData{ShortCode: "abc123"}}
+	stream.Notify(nil, model.Event{ID: "e0", Type: model.EventLinkUpdated, Data: &model.URL{ShortCode: "abc123"}})
+	stream.Notify(nil, click)
+	// The buffer holds one event, so the second click is dropped.
+	stream.Notify(nil, click)
+
+	assert.Equal(t, click, <-first.C)
+	assert.Equal(t, int64(1), first.Dropped())
+	assert.Empty(t, other.C)
+
+	first.Close()
+	first.Close()
+	_, open := <-first.C
+	assert.False(t, open)
+	// Closed subscribers no longer receive events.
+	stream.Notify(nil, click)
+	assert.Equal(t, int64(1), first.Dropped())
+}
@@ -3,21 +3,141 @@ package service
 import (
 	"errors"
 	"math/rand"
+	"net/url"
 	"strings"
+	"time"
 
+	"go.mongodb.org/mongo-driver/mongo"
 	"gofr.dev/pkg/gofr"
 
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
 	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/referrer"
 	"github.com/sksmagr23/url-shortener-gofr/store"
+	"github.com/sksmagr23/url-shortener-gofr/useragent"
 )
 
 type URLServiceImpl struct {
-	Store *store.URLStore
-	Host  string
+	Store       *store.URLStore
+	Campaigns   *CampaignCache
+	Folders     *store.FolderStore
+	Revisions   *store.RevisionStore
+	Audit       *Auditor
+	Clicks      *ClickIngester
+	Bots        *BotClassifier
+	Sources     *referrer.Classifier
+	IPs         *IPAnonymizer
+	Screener    *Screener
+	Chains      *ChainGuard
+	Policies    *PolicyChecker
+	Workspace   *WorkspaceAccess
+	Quotas      *Meter
+	Idempotency *Idempotency
+	Listeners   []EventListener
+	Host        string
 }
 
-func NewURLService(store *store.URLStore, host string) URLService {
-	return &URLServiceImpl{Store: store, Host: host}
+// URLOption configures optional collaborators of the URL service.
+type URLOption func(*URLServiceImpl)
+
+// WithAudit records every change to links in the audit log.
+func WithAudit(auditor *Auditor) URLOption {
+	return func(s *URLServiceImpl) {
+		s.Audit = auditor
+	}
+}
+
+// WithClicks records every redirect through the asynchronous click pipeline
+// instead of counting it synchronously.
+func WithClicks(ingester *ClickIngester) URLOption {
+	return func(s *URLServiceImpl) {
+		s.Clicks = ingester
+	}
+}
+
+// WithBotFilter classifies every click as a person or a bot so that bots
+// are counted separately.
+func WithBotFilter(classifier *BotClassifier) URLOption {
+	return func(s *URLServiceImpl) {
+		s.Bots = classifier
+	}
+}
+
+// WithSources attributes every click to a source and channel from its
+// referrer and utm_source.
+func WithSources(classifier *referrer.Classifier) URLOption {
+	return func(s *URLServiceImpl) {
+		s.Sources = classifier
+	}
+}
+
+// WithPrivacy anonymizes the client IP before a click is stored.
+func WithPrivacy(anonymizer *IPAnonymizer) URLOption {
+	return func(s *URLServiceImpl) {
+		s.IPs = anonymizer
+	}
+}
+
+// WithScreening rejects links to blocklisted destinations and stores the
+// screening verdict on every link.
+func WithScreening(screener *Screener) URLOption {
+	return func(s *URLServiceImpl) {
+		s.Screener = screener
+	}
+}
+
+// WithChainGuard rejects destinations that loop back to this shortener or
+// chain through too many shorteners.
+func WithChainGuard(guard *ChainGuard) URLOption {
+	return func(s *URLServiceImpl) {
+		s.Chains = guard
+	}
+}
+
+// WithPolicies rejects destinations that break the allow and deny rules of
+// the link's workspace.
+func WithPolicies(checker *PolicyChecker) URLOption {
+	return func(s *URLServiceImpl) {
+		s.Policies = checker
+	}
+}
+
+// WithWorkspaces lets links be created in a workspace and shared with its
+// members according to their roles.
+func WithWorkspaces(access *WorkspaceAccess) URLOption {
+	return func(s *URLServiceImpl) {
+		s.Workspace = access
+	}
+}
+
+// WithQuotas enforces the link and tracked click limits of each account's plan.
+func WithQuotas(meter *Meter) URLOption {
+	return func(s *URLServiceImpl) {
+		s.Quotas = meter
+	}
+}
+
+// WithIdempotency replays the first response to retries of link creation
+// that carry the same Idempotency-Key.
+func WithIdempotency(idempotency *Idempotency) URLOption {
+	return func(s *URLServiceImpl) {
+		s.Idempotency = idempotency
+	}
+}
+
+// WithCampaigns enables campaign_id on links and UTM tagging of their destinations.
+func WithCampaigns(campaigns *store.CampaignStore) URLOption {
+	return func(s *URLServiceImpl) {
+		s.Campaigns = NewCampaignCache(campaigns)
+	}
+}
+
+func NewURLService(store *store.URLStore, host string, opts ...URLOption) URLService {
+	s := &URLServiceImpl{Store: store, Host: host}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func GenerateShortCode(length int) string {
@@ -30,32 +150,380 @@ func GenerateShortCode(length int) string {
 }
 
 type URLService interface {
-	Create(ctx *gofr.Context, original string) (*model.URL, error)
+	Create(ctx *gofr.Context, req *model.CreateURLRequest) (*model.URL, error)
 	GetByShortCode(ctx *gofr.Context, code string) (*model.URL, error)
+	Resolve(ctx *gofr.Context, code, suffix string, query url.Values) (string, error)
+	List(ctx *gofr.Context, filter model.URLFilter) ([]model.URL, error)
+	ListTags(ctx *gofr.Context) ([]model.TagCount, error)
+	SetTags(ctx *gofr.Context, code string, req *model.SetTagsRequest) (*model.URL, error)
+	Move(ctx *gofr.Context, code string, req *model.MoveRequest) (*model.URL, error)
+	BulkTag(ctx *gofr.Context, req *model.BulkTagRequest) (*model.BulkTagResult, error)
+	Update(ctx *gofr.Context, code string, req *model.UpdateURLRequest) (*model.URL, error)
+	History(ctx *gofr.Context, code string) ([]model.Revision, error)
+	Revert(ctx *gofr.Context, code string, number int) (*model.URL, error)
+	Delete(ctx *gofr.Context, code string) error
 }
 
-func (s *URLServiceImpl) Create(ctx *gofr.Context, original string) (*model.URL, error) {
-	if !strings.HasPrefix(original, "http://") && !strings.HasPrefix(original, "https://") {
-		return nil, errors.New("invalid URL")
+func (s *URLServiceImpl) Create(ctx *gofr.Context, req *model.CreateURLRequest) (*model.URL, error) {
+	key := middleware.GetRequestMeta(ctx).Header.Get(model.IdempotencyHeader)
+	if s.Idempotency == nil || key == "" {
+		return s.create(ctx, req)
+	}
+	url, err := s.Idempotency.Create(ctx, key, req, func() (*model.URL, error) {
+		return s.create(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	url.ShortURL = s.Host + url.ShortCode
+	return url, nil
+}
+
+func (s *URLServiceImpl) create(ctx *gofr.Context, req *model.CreateURLRequest) (*model.URL, error) {
+	original := req.OriginalURL
+	if err := validateSettings(original, req.QueryConflict); err != nil {
+		return nil, err
+	}
+	original, target, err := s.checkChain(ctx, "", original)
+	if err != nil {
+		return nil, err
+	}
+	chained := target != req.OriginalURL
+	if req.CampaignID != "" {
+		campaign, err := s.findCampaign(ctx, req.CampaignID)
+		if err != nil {
+			return nil, err
+		}
+		if campaign.ApplyAt == model.CampaignApplyAtCreate {
+			original, err = ApplyUTM(original, campaign.UTM)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	owner := middleware.Actor(ctx)
+	if req.Workspace != "" {
+		if _, err := s.Workspace.Require(ctx, req.Workspace, model.RoleEditor); err != nil {
+			return nil, err
+		}
+	}
+	if !chained {
+		// The policies see the campaign's UTM parameters too.
+		target = original
+	}
+	// A link to another short link is judged by where it finally leads, so
+	// chaining cannot get past a deny rule.
+	if err := s.checkPolicy(ctx, workspaceOf(owner, req.Workspace), target); err != nil {
+		return nil, err
+	}
+	if s.Quotas != nil {
+		if err := s.Quotas.CheckLinks(ctx, owner, req.Workspace); err != nil {
+			return nil, err
+		}
+	}
+	screening, err := s.screen(original)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := NormalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	folder, err := s.checkFolder(ctx, owner, req.Folder)
+	if err != nil {
+		return nil, err
 	}
 	code := GenerateShortCode(6)
 	url := &model.URL{
-		Original:  original,
-		ShortCode: code,
+		Original:      original,
+		ShortCode:     code,
+		ForwardQuery:  req.ForwardQuery,
+		QueryConflict: req.QueryConflict,
+		Wildcard:      req.Wildcard,
+		CampaignID:    req.CampaignID,
+		NoTracking:    req.NoTracking,
+		Owner:         owner,
+		Workspace:     req.Workspace,
+		Tags:          tags,
+		Folder:        folder,
+		Screening:     screening,
 	}
 	url.ShortURL = s.Host + code
-	err := s.Store.Insert(ctx, url)
+	err = s.Store.Insert(ctx, url)
 	if err != nil {
 		return nil, err
 	}
+	s.recordRevision(ctx, url, model.RevisionActionCreate, 0)
+	s.Audit.Record(ctx, model.AuditLinkCreate, "link:"+code, nil, url)
+	s.emit(ctx, model.EventLinkCreated, owner, url)
 	return url, nil
 }
 
+// GetByShortCode returns a link the caller may view.
 func (s *URLServiceImpl) GetByShortCode(ctx *gofr.Context, code string) (*model.URL, error) {
-	url, err := s.Store.FindByShortCode(ctx, code)
+	url, err := s.Workspace.FindVisible(ctx, s.Store, code)
 	if err != nil {
 		return nil, err
 	}
 	url.ShortURL = s.Host + url.ShortCode
 	return url, nil
 }
+
+// Resolve returns the address GET /{short_code} should redirect to.
+func (s *URLServiceImpl) Resolve(ctx *gofr.Context, code, suffix string, query url.Values) (string, error) {
+	link, err := s.Store.FindByShortCode(ctx, code)
+	if err != nil {
+		return "", err
+	}
+	moderation := moderationOf(link)
+	if moderation == model.ModerationDisabled {
+		return "", ErrLinkDisabled
+	}
+	// Links an admin cleared are no longer held back by their screening verdict.
+	verdict := verdictOf(link.Screening)
+	if moderation == model.ModerationCleared {
+		verdict = model.VerdictClean
+	}
+	if verdict == model.VerdictMalicious {
+		return "", ErrDestinationBlocked
+	}
+	proceeded := query.Has(ProceedParam)
+	if proceeded {
+		query = withoutParam(query, ProceedParam)
+	}
+	destination, err := BuildDestination(link, suffix, query)
+	if err != nil {
+		return "", err
+	}
+	if link.CampaignID != "" && s.Campaigns != nil {
+		campaign, err := s.Campaigns.FindByID(ctx, link.CampaignID)
+		if err != nil {
+			// A missing campaign must not break the redirect itself.
+			ctx.Logger.Errorf("loading campaign %s for %s: %v", link.CampaignID, code, err)
+		} else if campaign.ApplyAt == model.CampaignApplyAtRedirect {
+			destination, err = ApplyUTM(destination, campaign.UTM)
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+	if verdict == model.VerdictSuspicious && !proceeded {
+		// The click is counted once the visitor continues past the warning.
+		return destination, &DestinationWarning{
+			Destination: destination,
+			Reasons:     link.Screening.Reasons,
+			Proceed:     proceedURL(code, suffix, query),
+		}
+	}
+	click := s.newClick(ctx, link, destination, query)
+	// Bot clicks are recorded but do not use up the quota.
+	if click.Bot != "" || s.withinClickQuota(ctx, link) {
+		s.recordClick(ctx, click)
+	}
+	s.emit(ctx, model.EventLinkClicked, link.Owner, model.ClickData{
+		ShortCode:   code,
+		Destination: destination,
+		Bot:         click.Bot,
+	})
+	return destination, nil
+}
+
+// withoutParam returns a copy of query without key.
+func withoutParam(query url.Values, key string) url.Values {
+	result := make(url.Values, len(query))
+	for k, values := range query {
+		if k != key {
+			result[k] = values
+		}
+	}
+	return result
+}
+
+// newClick describes a redirect; query is the query string of the inbound request.
+func (s *URLServiceImpl) newClick(ctx *gofr.Context, link *model.URL, destination string, query url.Values) model.Click {
+	meta := middleware.GetRequestMeta(ctx)
+	click := model.Click{
+		ShortCode:   link.ShortCode,
+		Owner:       link.Owner,
+		CampaignID:  link.CampaignID,
+		Destination: destination,
+		Referrer:    meta.Header.Get("Referer"),
+		UserAgent:   meta.Header.Get("User-Agent"),
+		IP:          middleware.ClientIP(ctx),
+		Timestamp:   time.Now().UTC(),
+	}
+	ua := useragent.Parse(click.UserAgent)
+	click.Browser, click.BrowserVersion, click.OS, click.Device = ua.Browser, ua.BrowserVersion, ua.OS, ua.Device
+	if s.Sources != nil {
+		attribution := s.Sources.Classify(click.Referrer, query)
+		click.Source, click.Channel = attribution.Source, attribution.Channel
+	}
+	if s.Bots != nil {
+		click.Bot = s.Bots.Classify(meta)
+	}
+	if click.Bot != "" {
+		click.Device = useragent.DeviceBot
+	}
+	// Visitors who refuse tracking, and every visitor of an opted-out link,
+	// are only counted.
+	if link.NoTracking || trackingRefused(meta) {
+		anonymize(&click)
+		return click
+	}
+	click.Visitor = click.IP + "|" + click.UserAgent
+	if s.IPs != nil {
+		click.IP, click.IPMode = s.IPs.Anonymize(click.IP, click.Timestamp), s.IPs.Mode
+	}
+	return click
+}
+
+// withinClickQuota meters a tracked click against the account of link. Once
+// the monthly quota is used up links keep redirecting, but their clicks are
+// no longer recorded.
+func (s *URLServiceImpl) withinClickQuota(ctx *gofr.Context, link *model.URL) bool {
+	if s.Quotas == nil {
+		return true
+	}
+	err := s.Quotas.Take(ctx, clickAccount(link), model.MetricTrackedClicks)
+	var exceeded *QuotaExceeded
+	if errors.As(err, &exceeded) {
+		return false
+	}
+	if err != nil {
+		// Failing to meter must not lose the click.
+		ctx.Logger.Errorf("metering click for %s: %v", link.ShortCode, err)
+	}
+	return true
+}
+
+func (s *URLServiceImpl) recordClick(ctx *gofr.Context, click model.Click) {
+	if s.Clicks != nil {
+		s.Clicks.Record(ctx, click)
+		return
+	}
+	people, bots := int64(1), int64(0)
+	if click.Bot != "" {
+		people, bots = 0, 1
+	}
+	if err := s.Store.IncrementClicks(ctx, click.ShortCode, people, bots); err != nil {
+		ctx.Logger.Errorf("counting click for %s: %v", click.ShortCode, err)
+	}
+}
+
+// Delete removes a link the caller may modify. Its revision history is kept.
+func (s *URLServiceImpl) Delete(ctx *gofr.Context, code string) error {
+	link, err := s.findOwned(ctx, code)
+	if err != nil {
+		return err
+	}
+	if err := checkIfMatch(ctx, link); err != nil {
+		return err
+	}
+	deleted, err := s.Store.DeleteByShortCode(ctx, code)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return mongo.ErrNoDocuments
+	}
+	link.ShortURL = s.Host + link.ShortCode
+	s.Audit.Record(ctx, model.AuditLinkDelete, "link:"+code, link, nil)
+	s.emit(ctx, model.EventLinkDeleted, link.Owner, link)
+	return nil
+}
+
+// findOwned loads a link the caller may modify: one of their own links, or a
+// link of a workspace where they are at least an editor. Links of other
+// owners and workspaces are reported as missing rather than forbidden so
+// their codes are not revealed.
+func (s *URLServiceImpl) findOwned(ctx *gofr.Context, code string) (*model.URL, error) {
+	link, err := s.Store.FindByShortCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if link.Workspace != "" {
+		_, err := s.Workspace.Require(ctx, link.Workspace, model.RoleEditor)
+		if errors.Is(err, ErrWorkspaceNotFound) {
+			return nil, mongo.ErrNoDocuments
+		}
+		if err != nil {
+			return nil, err
+		}
+		return link, nil
+	}
+	if actor := middleware.Actor(ctx); actor == "" || link.Owner != actor {
+		return nil, mongo.ErrNoDocuments
+	}
+	return link, nil
+}
+
+// workspaceOf returns the workspace whose policies apply to a link. Links
+// outside any workspace fall under a workspace named after their owner.
+func workspaceOf(owner, workspace string) string {
+	if workspace != "" {
+		return workspace
+	}
+	return owner
+}
+
+// checkChain returns the destination to store for the link code and the
+// target it finally leads to, see ChainGuard.Check.
+func (s *URLServiceImpl) checkChain(ctx *gofr.Context, code, destination string) (stored, target string, err error) {
+	if s.Chains == nil {
+		return destination, destination, nil
+	}
+	return s.Chains.Check(ctx, code, destination)
+}
+
+// checkPolicy applies the destination policies of workspace, see workspaceOf.
+func (s *URLServiceImpl) checkPolicy(ctx *gofr.Context, workspace, destination string) error {
+	if s.Policies == nil {
+		return nil
+	}
+	return s.Policies.Check(ctx, workspace, destination)
+}
+
+// screen returns the verdict on destination, rejecting malicious ones. It
+// returns nil when screening is disabled.
+func (s *URLServiceImpl) screen(destination string) (*model.Screening, error) {
+	if s.Screener == nil {
+		return nil, nil
+	}
+	screening := s.Screener.Screen(destination)
+	if screening.Verdict == model.VerdictMalicious {
+		return nil, ErrMaliciousDestination
+	}
+	return screening, nil
+}
+
+func validateSettings(original, queryConflict string) error {
+	if !strings.HasPrefix(original, "http://") && !strings.HasPrefix(original, "https://") {
+		return errors.New("invalid URL")
+	}
+	if !validQueryConflict(queryConflict) {
+		return errors.New("invalid query_conflict")
+	}
+	return nil
+}
+
+func (s *URLServiceImpl) findCampaign(ctx *gofr.Context, id string) (*model.Campaign, error) {
+	if s.Campaigns == nil {
+		return nil, errors.New("campaigns are not enabled")
+	}
+	campaign, err := s.Campaigns.FindByID(ctx, id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrCampaignNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Links may only join campaigns their creator can see.
+	visible, err := campaignVisible(ctx, s.Workspace, campaign)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, ErrCampaignNotFound
+	}
+	return campaign, nil
+}
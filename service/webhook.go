@@ -0,0 +1,162 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+var (
+	ErrWebhookNotFound  = &apierror.Error{Status: http.StatusNotFound, Message: "webhook not found"}
+	ErrDeliveryNotFound = &apierror.Error{Status: http.StatusNotFound, Message: "delivery not found"}
+	ErrWebhookURL       = &apierror.Error{Status: http.StatusBadRequest,
+		Message: "invalid webhook URL, it must be a public http or https address"}
+	ErrWebhookEvents = &apierror.Error{Status: http.StatusBadRequest, Message: "events is required"}
+)
+
+var webhookEvents = []string{
+	model.EventLinkCreated,
+	model.EventLinkUpdated,
+	model.EventLinkDeleted,
+	model.EventLinkClicked,
+}
+
+type WebhookServiceImpl struct {
+	Store      *store.WebhookStore
+	Dispatcher *WebhookDispatcher
+	Audit      *Auditor
+}
+
+func NewWebhookService(webhookStore *store.WebhookStore, dispatcher *WebhookDispatcher, auditor *Auditor) WebhookService {
+	return &WebhookServiceImpl{Store: webhookStore, Dispatcher: dispatcher, Audit: auditor}
+}
+
+type WebhookService interface {
+	Create(ctx *gofr.Context, req *model.CreateWebhookRequest) (*model.Webhook, error)
+	List(ctx *gofr.Context) ([]model.Webhook, error)
+	Delete(ctx *gofr.Context, id string) error
+	Deliveries(ctx *gofr.Context, id string) ([]model.WebhookDelivery, error)
+	Redeliver(ctx *gofr.Context, id, deliveryID string) (*model.WebhookDelivery, error)
+}
+
+// Create subscribes a webhook of the caller. Webhooks need a signed-in owner:
+// everyone calling without X-User-ID would otherwise share them, and with
+// them the events of every link made without signing in.
+func (s *WebhookServiceImpl) Create(ctx *gofr.Context, req *model.CreateWebhookRequest) (*model.Webhook, error) {
+	actor := middleware.Actor(ctx)
+	if actor == "" {
+		return nil, ErrActorRequired
+	}
+	if !checkPublicURL(req.URL) {
+		return nil, ErrWebhookURL
+	}
+	if len(req.Events) == 0 {
+		return nil, ErrWebhookEvents
+	}
+	for _, event := range req.Events {
+		if !slices.Contains(webhookEvents, event) {
+			return nil, &apierror.Error{Status: http.StatusBadRequest, Message: "unknown event type " + event}
+		}
+	}
+	secret := req.Secret
+	if secret == "" {
+		secret = randomHex(32)
+	}
+	webhook := &model.Webhook{
+		Owner:  actor,
+		URL:    req.URL,
+		Events: req.Events,
+		Secret: secret,
+		Active: true,
+	}
+	if err := s.Store.Insert(ctx, webhook); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, model.AuditWebhookCreate, "webhook:"+webhook.ID, nil, redactSecret(*webhook))
+	return webhook, nil
+}
+
+// List returns the caller's webhooks without their secrets.
+func (s *WebhookServiceImpl) List(ctx *gofr.Context) ([]model.Webhook, error) {
+	actor := middleware.Actor(ctx)
+	if actor == "" {
+		return nil, ErrActorRequired
+	}
+	webhooks, err := s.Store.FindByOwner(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+	for i := range webhooks {
+		webhooks[i] = redactSecret(webhooks[i])
+	}
+	return webhooks, nil
+}
+
+func redactSecret(webhook model.Webhook) model.Webhook {
+	webhook.Secret = ""
+	return webhook
+}
+
+func (s *WebhookServiceImpl) Delete(ctx *gofr.Context, id string) error {
+	webhook, err := s.find(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.Store.Delete(ctx, middleware.Actor(ctx), id); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, model.AuditWebhookDelete, "webhook:"+id, redactSecret(*webhook), nil)
+	return nil
+}
+
+func (s *WebhookServiceImpl) Deliveries(ctx *gofr.Context, id string) ([]model.WebhookDelivery, error) {
+	if _, err := s.find(ctx, id); err != nil {
+		return nil, err
+	}
+	deliveries, err := s.Store.FindDeliveries(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(deliveries, func(a, b model.WebhookDelivery) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return deliveries, nil
+}
+
+func (s *WebhookServiceImpl) Redeliver(ctx *gofr.Context, id, deliveryID string) (*model.WebhookDelivery, error) {
+	webhook, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	delivery, err := s.Store.FindDelivery(ctx, id, deliveryID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Dispatcher.Redeliver(ctx, webhook, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func (s *WebhookServiceImpl) find(ctx *gofr.Context, id string) (*model.Webhook, error) {
+	actor := middleware.Actor(ctx)
+	if actor == "" {
+		return nil, ErrActorRequired
+	}
+	webhook, err := s.Store.FindByID(ctx, actor, id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrWebhookNotFound
+	}
+	return webhook, err
+}
@@ -0,0 +1,118 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+func TestWebhookServiceCreate(t *testing.T) {
+	tests := []struct {
+		name        string
+		request     *model.CreateWebhookRequest
+		expectError bool
+	}{
+		{
+			name:    "Success - Generated Secret",
+			request: &model.CreateWebhookRequest{URL: "https://hooks.example.com/in", Events: []string{model.EventLinkClicked}},
+		},
+		{
+			name:        "Failure - Invalid URL",
+			request:     &model.CreateWebhookRequest{URL: "ftp://hooks.example.com", Events: []string{model.EventLinkClicked}},
+			expectError: true,
+		},
+		{
+			name:        "Failure - Loopback Address",
+			request:     &model.CreateWebhookRequest{URL: "http://127.0.0.1:8080/in", Events: []string{model.EventLinkClicked}},
+			expectError: true,
+		},
+		{
+			name:        "Failure - Metadata Service",
+			request:     &model.CreateWebhookRequest{URL: "http://169.254.169.254/latest", Events: []string{model.EventLinkClicked}},
+			expectError: true,
+		},
+		{
+			name:        "Failure - Private Host Name",
+			request:     &model.CreateWebhookRequest{URL: "http://localhost/in", Events: []string{model.EventLinkClicked}},
+			expectError: true,
+		},
+		{
+			name:        "Failure - No Events",
+			request:     &model.CreateWebhookRequest{URL: "https://hooks.example.com/in"},
+			expectError: true,
+		},
+		{
+			name:        "Failure - Unknown Event",
+			request:     &model.CreateWebhookRequest{URL: "https://hooks.example.com/in", Events: []string{"link.exploded"}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContainer, mocks := container.NewMockContainer(t)
+			webhookStore := store.NewWebhookStore()
+			webhookService := service.NewWebhookService(webhookStore, service.NewWebhookDispatcher(webhookStore, 1), nil)
+
+			if !tt.expectError {
+				mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "webhooks", gomock.Any()).Return("id", nil)
+			}
+
+			ctx := &gofr.Context{Context: actorContext("alice"), Container: mockContainer}
+
+			webhook, err := webhookService.Create(ctx, tt.request)
+			if tt.expectError {
+				assert.Equal(t, http.StatusBadRequest, statusOf(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "alice", webhook.Owner)
+			assert.True(t, webhook.Active)
+			assert.Len(t, webhook.Secret, 64)
+		})
+	}
+}
+
+func TestWebhookServiceListHidesSecrets(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	webhookStore := store.NewWebhookStore()
+	webhookService := service.NewWebhookService(webhookStore, service.NewWebhookDispatcher(webhookStore, 1), nil)
+
+	mocks.Mongo.EXPECT().Find(gomock.Any(), "webhooks", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+			*results.(*[]model.Webhook) = []model.Webhook{{ID: "w1", Secret: "secret"}}
+			return nil
+		})
+
+	ctx := &gofr.Context{Context: actorContext("alice"), Container: mockContainer}
+
+	webhooks, err := webhookService.List(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, webhooks[0].Secret)
+}
+
+func TestWebhookServiceRequiresUser(t *testing.T) {
+	mockContainer, _ := container.NewMockContainer(t)
+	webhookStore := store.NewWebhookStore()
+	webhookService := service.NewWebhookService(webhookStore, service.NewWebhookDispatcher(webhookStore, 1), nil)
+	ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+
+	_, err := webhookService.Create(ctx, &model.CreateWebhookRequest{
+		URL: "https://hooks.example.com/in", Events: []string{model.EventLinkClicked},
+	})
+	assert.Equal(t, service.ErrActorRequired, err)
+	_, err = webhookService.List(ctx)
+	assert.Equal(t, service.ErrActorRequired, err)
+	_, err = webhookService.Deliveries(ctx, "w1")
+	assert.Equal(t, service.ErrActorRequired, err)
+	assert.Equal(t, service.ErrActorRequired, webhookService.Delete(ctx, "w1"))
+}
@@ -0,0 +1,418 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+
+	gofrHttp "gofr.dev/pkg/gofr/http"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/middleware"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+var (
+	ErrActorRequired     = &apierror.Error{Status: http.StatusUnauthorized, Message: "a signed-in user is required"}
+	ErrWorkspaceNotFound = &apierror.Error{Status: http.StatusNotFound, Message: "workspace not found"}
+	ErrMemberNotFound    = &apierror.Error{Status: http.StatusNotFound, Message: "member not found"}
+	ErrAlreadyMember     = &apierror.Error{Status: http.StatusConflict, Message: "user is already a member of this workspace"}
+	ErrLastOwner         = &apierror.Error{Status: http.StatusConflict, Message: "a workspace must keep at least one owner"}
+	ErrInvitationInvalid = &apierror.Error{Status: http.StatusNotFound, Message: "invitation is invalid, expired or already used"}
+)
+
+// invitationTTL is how long an invitation can be accepted.
+const invitationTTL = 7 * 24 * time.Hour
+
+// roleRank orders workspace roles by privilege; unknown roles rank 0.
+var roleRank = map[string]int{
+	model.RoleViewer: 1,
+	model.RoleEditor: 2,
+	model.RoleAdmin:  3,
+	model.RoleOwner:  4,
+}
+
+func roleAtLeast(role, required string) bool {
+	return roleRank[role] > 0 && roleRank[role] >= roleRank[required]
+}
+
+func errRoleRequired(role string) error {
+	return apierror.Forbidden("requires the " + role + " role in this workspace")
+}
+
+// WorkspaceAccess checks the caller's role in a workspace.
+type WorkspaceAccess struct {
+	Store *store.WorkspaceStore
+}
+
+func NewWorkspaceAccess(workspaces *store.WorkspaceStore) *WorkspaceAccess {
+	return &WorkspaceAccess{Store: workspaces}
+}
+
+// Require returns the caller's membership of workspaceID if it holds at least
+// role. Non-members get ErrWorkspaceNotFound so workspace IDs are not revealed.
+func (a *WorkspaceAccess) Require(ctx *gofr.Context, workspaceID, role string) (*model.Membership, error) {
+	actor := middleware.Actor(ctx)
+	if a == nil || actor == "" {
+		return nil, ErrWorkspaceNotFound
+	}
+	member, err := a.Store.FindMember(ctx, workspaceID, actor)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrWorkspaceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !roleAtLeast(member.Role, role) {
+		return nil, errRoleRequired(role)
+	}
+	return member, nil
+}
+
+// Workspaces returns the set of workspaces where userID has at least role.
+func (a *WorkspaceAccess) Workspaces(ctx *gofr.Context, userID, role string) (map[string]bool, error) {
+	workspaces := map[string]bool{}
+	if a == nil {
+		return workspaces, nil
+	}
+	memberships, err := a.Store.FindMemberships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range memberships {
+		if roleAtLeast(memberships[i].Role, role) {
+			workspaces[memberships[i].WorkspaceID] = true
+		}
+	}
+	return workspaces, nil
+}
+
+// CanView reports whether the caller may read link and its analytics: its
+// owner, or for a workspace link any member of the workspace. Links made
+// without signing in have no owner, so nobody can read them this way.
+func (a *WorkspaceAccess) CanView(ctx *gofr.Context, link *model.URL) (bool, error) {
+	if link.Workspace == "" {
+		actor := middleware.Actor(ctx)
+		return actor != "" && link.Owner == actor, nil
+	}
+	_, err := a.Require(ctx, link.Workspace, model.RoleViewer)
+	if errors.Is(err, ErrWorkspaceNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// FindVisible loads the link code when the caller may view it. Links the
+// caller may not view are reported as missing so their codes are not revealed.
+func (a *WorkspaceAccess) FindVisible(ctx *gofr.Context, urls *store.URLStore, code string) (*model.URL, error) {
+	link, err := urls.FindByShortCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	visible, err := a.CanView(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, mongo.ErrNoDocuments
+	}
+	return link, nil
+}
+
+type WorkspaceService interface {
+	Create(ctx *gofr.Context, req *model.CreateWorkspaceRequest) (*model.Workspace, error)
+	List(ctx *gofr.Context) ([]model.Workspace, error)
+	Get(ctx *gofr.Context, id string) (*model.Workspace, error)
+	Members(ctx *gofr.Context, id string) ([]model.Membership, error)
+	SetRole(ctx *gofr.Context, id, userID string, req *model.SetRoleRequest) (*model.Membership, error)
+	RemoveMember(ctx *gofr.Context, id, userID string) error
+	Invite(ctx *gofr.Context, id string, req *model.InviteRequest) (*model.Invitation, error)
+	Invitations(ctx *gofr.Context, id string) ([]model.Invitation, error)
+	Accept(ctx *gofr.Context, req *model.AcceptInvitationRequest) (*model.Membership, error)
+}
+
+type WorkspaceServiceImpl struct {
+	Store  *store.WorkspaceStore
+	Access *WorkspaceAccess
+	Audit  *Auditor
+}
+
+func NewWorkspaceService(workspaces *store.WorkspaceStore, auditor *Auditor) WorkspaceService {
+	return &WorkspaceServiceImpl{Store: workspaces, Access: NewWorkspaceAccess(workspaces), Audit: auditor}
+}
+
+// Create makes a workspace owned by the caller.
+func (s *WorkspaceServiceImpl) Create(ctx *gofr.Context, req *model.CreateWorkspaceRequest) (*model.Workspace, error) {
+	actor := middleware.Actor(ctx)
+	if actor == "" {
+		return nil, ErrActorRequired
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, gofrHttp.ErrorInvalidParam{Params: []string{"name"}}
+	}
+	workspace := &model.Workspace{Name: name, CreatedBy: actor, Owners: 1}
+	if err := s.Store.Insert(ctx, workspace); err != nil {
+		return nil, err
+	}
+	if err := s.Store.AddMember(ctx, &model.Membership{WorkspaceID: workspace.ID, UserID: actor, Role: model.RoleOwner}); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, model.AuditWorkspaceCreate, "workspace:"+workspace.ID, nil, workspace)
+	workspace.Role = model.RoleOwner
+	return workspace, nil
+}
+
+// List returns the workspaces the caller belongs to with their role in each.
+func (s *WorkspaceServiceImpl) List(ctx *gofr.Context) ([]model.Workspace, error) {
+	memberships, err := s.Store.FindMemberships(ctx, middleware.Actor(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if len(memberships) == 0 {
+		return []model.Workspace{}, nil
+	}
+	roles := make(map[string]string, len(memberships))
+	ids := make([]string, len(memberships))
+	for i := range memberships {
+		ids[i] = memberships[i].WorkspaceID
+		roles[ids[i]] = memberships[i].Role
+	}
+	workspaces, err := s.Store.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range workspaces {
+		workspaces[i].Role = roles[workspaces[i].ID]
+	}
+	return workspaces, nil
+}
+
+func (s *WorkspaceServiceImpl) Get(ctx *gofr.Context, id string) (*model.Workspace, error) {
+	member, err := s.Access.Require(ctx, id, model.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	workspace, err := s.Store.FindByID(ctx, id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrWorkspaceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	workspace.Role = member.Role
+	return workspace, nil
+}
+
+func (s *WorkspaceServiceImpl) Members(ctx *gofr.Context, id string) ([]model.Membership, error) {
+	if _, err := s.Access.Require(ctx, id, model.RoleViewer); err != nil {
+		return nil, err
+	}
+	return s.Store.FindMembers(ctx, id)
+}
+
+// SetRole changes a member's role. Admins manage roles up to admin; only
+// owners can make or unmake owners.
+func (s *WorkspaceServiceImpl) SetRole(
+	ctx *gofr.Context, id, userID string, req *model.SetRoleRequest,
+) (*model.Membership, error) {
+	caller, err := s.Access.Require(ctx, id, model.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if roleRank[req.Role] == 0 {
+		return nil, gofrHttp.ErrorInvalidParam{Params: []string{"role"}}
+	}
+	target, err := s.findMember(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkManage(caller, target.Role, req.Role); err != nil {
+		return nil, err
+	}
+	if target.Role == model.RoleOwner && req.Role != model.RoleOwner {
+		if err := releaseOwner(ctx, s.Store, id); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.Store.SetRole(ctx, id, userID, req.Role); err != nil {
+		return nil, err
+	}
+	if req.Role == model.RoleOwner && target.Role != model.RoleOwner {
+		if err := s.Store.AddOwner(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	before := *target
+	target.Role = req.Role
+	s.Audit.Record(ctx, model.AuditWorkspaceRole, "workspace:"+id, &before, target)
+	return target, nil
+}
+
+// RemoveMember removes userID from the workspace. Members may always leave;
+// removing someone else takes the same rights as changing their role.
+func (s *WorkspaceServiceImpl) RemoveMember(ctx *gofr.Context, id, userID string) error {
+	required := model.RoleAdmin
+	if userID == middleware.Actor(ctx) {
+		required = model.RoleViewer
+	}
+	caller, err := s.Access.Require(ctx, id, required)
+	if err != nil {
+		return err
+	}
+	target, err := s.findMember(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if target.UserID != caller.UserID {
+		if err := checkManage(caller, target.Role, target.Role); err != nil {
+			return err
+		}
+	}
+	if target.Role == model.RoleOwner {
+		if err := releaseOwner(ctx, s.Store, id); err != nil {
+			return err
+		}
+	}
+	if err := s.Store.RemoveMember(ctx, id, userID); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, model.AuditWorkspaceRemove, "workspace:"+id, target, nil)
+	return nil
+}
+
+// Invite creates a single-use invitation. Its token is only returned here.
+func (s *WorkspaceServiceImpl) Invite(ctx *gofr.Context, id string, req *model.InviteRequest) (*model.Invitation, error) {
+	caller, err := s.Access.Require(ctx, id, model.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if roleRank[req.Role] == 0 {
+		return nil, gofrHttp.ErrorInvalidParam{Params: []string{"role"}}
+	}
+	if err := checkManage(caller, "", req.Role); err != nil {
+		return nil, err
+	}
+	if req.UserID != "" {
+		if _, err := s.Store.FindMember(ctx, id, req.UserID); err == nil {
+			return nil, ErrAlreadyMember
+		} else if !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, err
+		}
+	}
+	token := randomHex(32)
+	invitation := &model.Invitation{
+		WorkspaceID: id,
+		Role:        req.Role,
+		UserID:      req.UserID,
+		TokenHash:   hashToken(token),
+		InvitedBy:   caller.UserID,
+		ExpiresAt:   time.Now().UTC().Add(invitationTTL),
+	}
+	if err := s.Store.InsertInvitation(ctx, invitation); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, model.AuditWorkspaceInvite, "workspace:"+id, nil, invitation)
+	invitation.Token = token
+	return invitation, nil
+}
+
+func (s *WorkspaceServiceImpl) Invitations(ctx *gofr.Context, id string) ([]model.Invitation, error) {
+	if _, err := s.Access.Require(ctx, id, model.RoleAdmin); err != nil {
+		return nil, err
+	}
+	return s.Store.FindInvitations(ctx, id)
+}
+
+// Accept joins the caller to the workspace of the invitation holding token.
+func (s *WorkspaceServiceImpl) Accept(ctx *gofr.Context, req *model.AcceptInvitationRequest) (*model.Membership, error) {
+	actor := middleware.Actor(ctx)
+	if actor == "" {
+		return nil, ErrActorRequired
+	}
+	if req.Token == "" {
+		return nil, gofrHttp.ErrorInvalidParam{Params: []string{"token"}}
+	}
+	invitation, err := s.Store.FindInvitation(ctx, hashToken(req.Token))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrInvitationInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if invitation.AcceptedBy != "" || now.After(invitation.ExpiresAt) ||
+		invitation.UserID != "" && invitation.UserID != actor {
+		return nil, ErrInvitationInvalid
+	}
+	if _, err := s.Store.FindMember(ctx, invitation.WorkspaceID, actor); err == nil {
+		return nil, ErrAlreadyMember
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	ok, err := s.Store.AcceptInvitation(ctx, invitation.ID, actor, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvitationInvalid
+	}
+	member := &model.Membership{WorkspaceID: invitation.WorkspaceID, UserID: actor, Role: invitation.Role}
+	if err := s.Store.AddMember(ctx, member); err != nil {
+		return nil, err
+	}
+	if member.Role == model.RoleOwner {
+		if err := s.Store.AddOwner(ctx, member.WorkspaceID); err != nil {
+			return nil, err
+		}
+	}
+	s.Audit.Record(ctx, model.AuditWorkspaceJoin, "workspace:"+invitation.WorkspaceID, nil, member)
+	return member, nil
+}
+
+func (s *WorkspaceServiceImpl) findMember(ctx *gofr.Context, id, userID string) (*model.Membership, error) {
+	member, err := s.Store.FindMember(ctx, id, userID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrMemberNotFound
+	}
+	return member, err
+}
+
+// releaseOwner lets an owner of workspace id step down, failing when they
+// are its last owner.
+func releaseOwner(ctx *gofr.Context, workspaces *store.WorkspaceStore, id string) error {
+	released, err := workspaces.ReleaseOwner(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// checkManage reports whether caller may move a member from role current
+// ("" for someone new) to role next: never above the caller's own role, and
+// owners only by owners.
+func checkManage(caller *model.Membership, current, next string) error {
+	if caller.Role == model.RoleOwner {
+		return nil
+	}
+	if current == model.RoleOwner || roleRank[next] > roleRank[caller.Role] {
+		return errRoleRequired(model.RoleOwner)
+	}
+	return nil
+}
+
+// hashToken returns the form invitation tokens are stored and looked up in.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
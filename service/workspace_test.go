@@ -0,0 +1,389 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"github.com/sksmagr23/url-shortener-gofr/apierror"
+	"github.com/sksmagr23/url-shortener-gofr/model"
+	"github.com/sksmagr23/url-shortener-gofr/service"
+	"github.com/sksmagr23/url-shortener-gofr/store"
+)
+
+// expectMembers serves members for every membership lookup.
+func expectMembers(mocks *container.Mocks, members ...model.Membership) {
+	mocks.Mongo.EXPECT().FindOne(gomock.Any(), "workspace_members", gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(_ context.Context, _ string, filter any, result any) error {
+			query := filter.(bson.M)
+			for _, member := range members {
+				if member.WorkspaceID == query["workspace_id"] && member.UserID == query["user_id"] {
+					*result.(*model.Membership) = member
+					return nil
+				}
+			}
+			return mongo.ErrNoDocuments
+		})
+}
+
+func newWorkspaceService() service.WorkspaceService {
+	return service.NewWorkspaceService(store.NewWorkspaceStore(), nil)
+}
+
+func statusOf(err error) int {
+	if apiErr, ok := err.(*apierror.Error); ok {
+		return apiErr.StatusCode()
+	}
+	return 0
+}
+
+func TestWorkspaceServiceCreate(t *testing.T) {
+	t.Run("Requires User", func(t *testing.T) {
+		mockContainer, _ := container.NewMockContainer(t)
+		_, err := newWorkspaceService().Create(&gofr.Context{Context: context.Background(), Container: mockContainer},
+			&model.CreateWorkspaceRequest{Name: "Marketing"})
+
+		assert.Equal(t, service.ErrActorRequired, err)
+	})
+
+	t.Run("Creator Becomes Owner", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "workspaces", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, document any) (any, error) {
+				assert.Equal(t, int64(1), document.(*model.Workspace).Owners)
+				return "id", nil
+			})
+		var owner *model.Membership
+		mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "workspace_members", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, document any) (any, error) {
+				owner = document.(*model.Membership)
+				return nil, nil
+			})
+
+		workspace, err := newWorkspaceService().Create(&gofr.Context{Context: actorContext("alice"), Container: mockContainer},
+			&model.CreateWorkspaceRequest{Name: " Marketing "})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Marketing", workspace.Name)
+		assert.Equal(t, model.RoleOwner, workspace.Role)
+		if assert.NotNil(t, owner) {
+			assert.Equal(t, model.Membership{WorkspaceID: workspace.ID, UserID: "alice", Role: model.RoleOwner,
+				JoinedAt: owner.JoinedAt}, *owner)
+		}
+	})
+}
+
+func TestWorkspaceServiceInvite(t *testing.T) {
+	members := []model.Membership{
+		{WorkspaceID: "ws1", UserID: "olivia", Role: model.RoleOwner},
+		{WorkspaceID: "ws1", UserID: "adam", Role: model.RoleAdmin},
+		{WorkspaceID: "ws1", UserID: "eve", Role: model.RoleEditor},
+	}
+
+	tests := []struct {
+		name   string
+		actor  string
+		req    model.InviteRequest
+		status int
+	}{
+		{name: "Editor Cannot Invite", actor: "eve", req: model.InviteRequest{Role: model.RoleViewer}, status: http.StatusForbidden},
+		{name: "Admin Cannot Invite Owner", actor: "adam", req: model.InviteRequest{Role: model.RoleOwner}, status: http.StatusForbidden},
+		{name: "Non Member", actor: "mallory", req: model.InviteRequest{Role: model.RoleViewer}, status: http.StatusNotFound},
+		{name: "Already Member", actor: "adam", req: model.InviteRequest{Role: model.RoleViewer, UserID: "eve"}, status: http.StatusConflict},
+		{name: "Admin Invites Editor", actor: "adam", req: model.InviteRequest{Role: model.RoleEditor, UserID: "bob"}},
+		{name: "Owner Invites Owner", actor: "olivia", req: model.InviteRequest{Role: model.RoleOwner}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContainer, mocks := container.NewMockContainer(t)
+			expectMembers(mocks, members...)
+			var stored *model.Invitation
+			if tt.status == 0 {
+				mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "workspace_invitations", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, document any) (any, error) {
+						stored = document.(*model.Invitation)
+						return nil, nil
+					})
+			}
+
+			invitation, err := newWorkspaceService().Invite(&gofr.Context{Context: actorContext(tt.actor), Container: mockContainer},
+				"ws1", &tt.req)
+
+			if tt.status != 0 {
+				assert.Equal(t, tt.status, statusOf(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, invitation.Token, 64)
+			assert.Equal(t, tt.req.Role, invitation.Role)
+			assert.Equal(t, tt.actor, invitation.InvitedBy)
+			if assert.NotNil(t, stored) {
+				assert.NotEmpty(t, stored.TokenHash)
+				assert.NotEqual(t, invitation.Token, stored.TokenHash)
+			}
+		})
+	}
+}
+
+func TestWorkspaceServiceAccept(t *testing.T) {
+	tests := []struct {
+		name        string
+		actor       string
+		invitation  model.Invitation
+		expectedErr error
+	}{
+		{name: "Joins", actor: "bob", invitation: model.Invitation{UserID: "bob", ExpiresAt: time.Now().Add(time.Hour)}},
+		{name: "Open Invitation", actor: "carol", invitation: model.Invitation{ExpiresAt: time.Now().Add(time.Hour)}},
+		{name: "Other User", actor: "carol", invitation: model.Invitation{UserID: "bob", ExpiresAt: time.Now().Add(time.Hour)},
+			expectedErr: service.ErrInvitationInvalid},
+		{name: "Expired", actor: "bob", invitation: model.Invitation{ExpiresAt: time.Now().Add(-time.Hour)},
+			expectedErr: service.ErrInvitationInvalid},
+		{name: "Used", actor: "bob", invitation: model.Invitation{AcceptedBy: "dave", ExpiresAt: time.Now().Add(time.Hour)},
+			expectedErr: service.ErrInvitationInvalid},
+		{name: "Already Member", actor: "eve", invitation: model.Invitation{ExpiresAt: time.Now().Add(time.Hour)},
+			expectedErr: service.ErrAlreadyMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContainer, mocks := container.NewMockContainer(t)
+			expectMembers(mocks, model.Membership{WorkspaceID: "ws1", UserID: "eve", Role: model.RoleEditor})
+			invitation := tt.invitation
+			invitation.ID, invitation.WorkspaceID, invitation.Role = "inv1", "ws1", model.RoleEditor
+			mocks.Mongo.EXPECT().FindOne(gomock.Any(), "workspace_invitations", gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, filter any, result any) error {
+					assert.NotEqual(t, "secret-token", filter.(bson.M)["token_hash"])
+					*result.(*model.Invitation) = invitation
+					return nil
+				})
+			if tt.expectedErr == nil {
+				mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "workspace_invitations",
+					bson.M{"_id": "inv1", "accepted_by": bson.M{"$exists": false}}, gomock.Any()).Return(int64(1), nil)
+				mocks.Mongo.EXPECT().InsertOne(gomock.Any(), "workspace_members", gomock.Any()).Return(nil, nil)
+			}
+
+			member, err := newWorkspaceService().Accept(&gofr.Context{Context: actorContext(tt.actor), Container: mockContainer},
+				&model.AcceptInvitationRequest{Token: "secret-token"})
+
+			assert.Equal(t, tt.expectedErr, err)
+			if tt.expectedErr == nil {
+				assert.Equal(t, "ws1", member.WorkspaceID)
+				assert.Equal(t, tt.actor, member.UserID)
+				assert.Equal(t, model.RoleEditor, member.Role)
+			}
+		})
+	}
+}
+
+func TestWorkspaceServiceSetRole(t *testing.T) {
+	members := []model.Membership{
+		{WorkspaceID: "ws1", UserID: "olivia", Role: model.RoleOwner},
+		{WorkspaceID: "ws1", UserID: "adam", Role: model.RoleAdmin},
+		{WorkspaceID: "ws1", UserID: "eve", Role: model.RoleEditor},
+	}
+
+	t.Run("Last Owner", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectMembers(mocks, members...)
+		mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "workspaces",
+			bson.M{"_id": "ws1", "owners": bson.M{"$gt": 1}}, bson.M{"$inc": bson.M{"owners": -1}}).Return(int64(0), nil)
+
+		_, err := newWorkspaceService().SetRole(&gofr.Context{Context: actorContext("olivia"), Container: mockContainer},
+			"ws1", "olivia", &model.SetRoleRequest{Role: model.RoleAdmin})
+
+		assert.Equal(t, service.ErrLastOwner, err)
+	})
+
+	t.Run("Admin Cannot Demote Owner", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectMembers(mocks, members...)
+
+		_, err := newWorkspaceService().SetRole(&gofr.Context{Context: actorContext("adam"), Container: mockContainer},
+			"ws1", "olivia", &model.SetRoleRequest{Role: model.RoleViewer})
+
+		assert.Equal(t, http.StatusForbidden, statusOf(err))
+	})
+
+	t.Run("Admin Promotes Editor", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectMembers(mocks, members...)
+		mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "workspace_members",
+			bson.M{"workspace_id": "ws1", "user_id": "eve"}, bson.M{"$set": bson.M{"role": model.RoleAdmin}}).Return(nil)
+
+		member, err := newWorkspaceService().SetRole(&gofr.Context{Context: actorContext("adam"), Container: mockContainer},
+			"ws1", "eve", &model.SetRoleRequest{Role: model.RoleAdmin})
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.RoleAdmin, member.Role)
+	})
+
+	t.Run("Owner Promotes Owner", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectMembers(mocks, members...)
+		gomock.InOrder(
+			mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "workspace_members",
+				bson.M{"workspace_id": "ws1", "user_id": "adam"}, bson.M{"$set": bson.M{"role": model.RoleOwner}}).Return(nil),
+			mocks.Mongo.EXPECT().UpdateOne(gomock.Any(), "workspaces",
+				bson.M{"_id": "ws1"}, bson.M{"$inc": bson.M{"owners": 1}}).Return(nil),
+		)
+
+		member, err := newWorkspaceService().SetRole(&gofr.Context{Context: actorContext("olivia"), Container: mockContainer},
+			"ws1", "adam", &model.SetRoleRequest{Role: model.RoleOwner})
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.RoleOwner, member.Role)
+	})
+}
+
+func TestURLServiceWorkspaceLinks(t *testing.T) {
+	members := []model.Membership{
+		{WorkspaceID: "ws1", UserID: "eve", Role: model.RoleEditor},
+		{WorkspaceID: "ws1", UserID: "victor", Role: model.RoleViewer},
+	}
+	link := model.URL{ShortCode: "abc123", Owner: "alice", Workspace: "ws1", Original: "https://example.com", Revision: 1}
+	newService := func() service.URLService {
+		return service.NewURLService(store.NewURLStore(), "http://sho.rt/",
+			service.WithWorkspaces(service.NewWorkspaceAccess(store.NewWorkspaceStore())))
+	}
+
+	t.Run("Editor Updates Teammate Link", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectMembers(mocks, members...)
+		expectLink(mocks, link)
+		mocks.Mongo.EXPECT().UpdateMany(gomock.Any(), "urls",
+			bson.M{"owner": "alice", "short_code": "abc123", "updated_at": notUpdated, "revision": 1}, gomock.Any()).Return(int64(1), nil)
+
+		destination := "https://example.com/new"
+		updated, err := newService().Update(&gofr.Context{Context: actorContext("eve"), Container: mockContainer}, "abc123",
+			&model.UpdateURLRequest{OriginalURL: &destination})
+
+		assert.NoError(t, err)
+		assert.Equal(t, destination, updated.Original)
+	})
+
+	t.Run("Viewer Cannot Delete", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectMembers(mocks, members...)
+		expectLink(mocks, link)
+
+		err := newService().Delete(&gofr.Context{Context: actorContext("victor"), Container: mockContainer}, "abc123")
+
+		assert.Equal(t, http.StatusForbidden, statusOf(err))
+	})
+
+	t.Run("Viewer Reads", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectMembers(mocks, members...)
+		expectLink(mocks, link)
+
+		got, err := newService().GetByShortCode(&gofr.Context{Context: actorContext("victor"), Container: mockContainer}, "abc123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, link.Original, got.Original)
+	})
+
+	t.Run("Non Member Cannot Read", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectMembers(mocks, members...)
+		expectLink(mocks, link)
+
+		_, err := newService().GetByShortCode(&gofr.Context{Context: actorContext("alice"), Container: mockContainer}, "abc123")
+
+		assert.Equal(t, mongo.ErrNoDocuments, err)
+	})
+
+	t.Run("Non Member Sees Nothing", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectMembers(mocks, members...)
+		expectLink(mocks, link)
+
+		err := newService().Delete(&gofr.Context{Context: actorContext("alice"), Container: mockContainer}, "abc123")
+
+		assert.Equal(t, mongo.ErrNoDocuments, err)
+	})
+
+	t.Run("Viewer Lists", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectMembers(mocks, members...)
+		mocks.Mongo.EXPECT().Find(gomock.Any(), "urls", bson.M{"workspace": "ws1"}, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, _ any, results any) error {
+				*results.(*[]model.URL) = []model.URL{link}
+				return nil
+			})
+
+		links, err := newService().List(&gofr.Context{Context: actorContext("victor"), Container: mockContainer},
+			model.URLFilter{Workspace: "ws1"})
+
+		assert.NoError(t, err)
+		assert.Len(t, links, 1)
+	})
+
+	t.Run("Viewer Cannot Create", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectMembers(mocks, members...)
+
+		_, err := newService().Create(&gofr.Context{Context: actorContext("victor"), Container: mockContainer},
+			&model.CreateURLRequest{OriginalURL: "https://example.com", Workspace: "ws1"})
+
+		assert.Equal(t, http.StatusForbidden, statusOf(err))
+	})
+}
+
+func TestAnonymousCallerCannotReachAnonymousLinks(t *testing.T) {
+	// Links made without signing in have no owner, and neither has the caller.
+	link := model.URL{ShortCode: "anon01", Original: "https://example.com", Revision: 1}
+	anonymous := func(mockContainer *container.Container) *gofr.Context {
+		return &gofr.Context{Context: context.Background(), Container: mockContainer}
+	}
+	newService := func() service.URLService {
+		return service.NewURLService(store.NewURLStore(), "http://sho.rt/",
+			service.WithRevisions(store.NewRevisionStore()))
+	}
+
+	t.Run("Update", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectLink(mocks, link)
+
+		destination := "https://evil.example/"
+		_, err := newService().Update(anonymous(mockContainer), "anon01", &model.UpdateURLRequest{OriginalURL: &destination})
+
+		assert.Equal(t, mongo.ErrNoDocuments, err)
+	})
+
+	t.Run("History", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectLink(mocks, link)
+
+		_, err := newService().History(anonymous(mockContainer), "anon01")
+
+		assert.Equal(t, mongo.ErrNoDocuments, err)
+	})
+
+	t.Run("Analytics", func(t *testing.T) {
+		mockContainer, mocks := container.NewMockContainer(t)
+		expectLink(mocks, link)
+		svc := service.NewAnalyticsService(store.NewURLStore(), store.NewRollupStore(), nil)
+
+		_, err := svc.Link(anonymous(mockContainer), "anon01", model.AnalyticsQuery{})
+
+		assert.Equal(t, mongo.ErrNoDocuments, err)
+	})
+
+	t.Run("Export", func(t *testing.T) {
+		mockContainer, _ := container.NewMockContainer(t)
+		svc := service.NewAnalyticsService(store.NewURLStore(), store.NewRollupStore(), nil)
+
+		_, err := svc.Export(anonymous(mockContainer), model.ReportScope{ShortCode: "anon01"}, model.AnalyticsQuery{})
+
+		assert.Equal(t, service.ErrActorRequired, err)
+	})
+}
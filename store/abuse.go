@@ -0,0 +1,56 @@
+package store
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+type AbuseReportStore struct{}
+
+func NewAbuseReportStore() *AbuseReportStore {
+	return &AbuseReportStore{}
+}
+
+func (s *AbuseReportStore) Insert(ctx *gofr.Context, report *model.AbuseReport) error {
+	report.ID = primitive.NewObjectID().Hex()
+	report.CreatedAt = time.Now().UTC()
+	_, err := ctx.Mongo.InsertOne(ctx, "abuse_reports", report)
+	return err
+}
+
+// CountOpenByReporter counts the open reports reporter filed against a link,
+// or against any link when code is empty.
+func (s *AbuseReportStore) CountOpenByReporter(ctx *gofr.Context, code, reporter string) (int64, error) {
+	filter := bson.M{"reporter": reporter, "resolution": bson.M{"$exists": false}}
+	if code != "" {
+		filter["short_code"] = code
+	}
+	return ctx.Mongo.CountDocuments(ctx, "abuse_reports", filter)
+}
+
+// FindOpen returns every open report.
+func (s *AbuseReportStore) FindOpen(ctx *gofr.Context) ([]model.AbuseReport, error) {
+	var results []model.AbuseReport
+	err := ctx.Mongo.Find(ctx, "abuse_reports", bson.M{"resolution": bson.M{"$exists": false}}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// DeleteByShortCodes removes every report filed against the given links.
+func (s *AbuseReportStore) DeleteByShortCodes(ctx *gofr.Context, codes []string) (int64, error) {
+	return ctx.Mongo.DeleteMany(ctx, "abuse_reports", bson.M{"short_code": bson.M{"$in": codes}})
+}
+
+// Resolve closes the open reports of a link with the action taken on it.
+func (s *AbuseReportStore) Resolve(ctx *gofr.Context, code, resolution string) (int64, error) {
+	return ctx.Mongo.UpdateMany(ctx, "abuse_reports",
+		bson.M{"short_code": code, "resolution": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"resolution": resolution, "resolved_at": time.Now().UTC()}})
+}
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+// AuditStore is append-only: entries are never updated, and only deleted to
+// honour an erasure request.
+type AuditStore struct{}
+
+func NewAuditStore() *AuditStore {
+	return &AuditStore{}
+}
+
+func (s *AuditStore) Insert(ctx *gofr.Context, entry *model.AuditEntry) error {
+	entry.ID = primitive.NewObjectID().Hex()
+	entry.Timestamp = time.Now().UTC()
+	_, err := ctx.Mongo.InsertOne(ctx, "audit_log", entry)
+	return err
+}
+
+func (s *AuditStore) Find(ctx *gofr.Context, filter model.AuditFilter) ([]model.AuditEntry, error) {
+	query := bson.M{}
+	if filter.Actor != "" {
+		query["actor"] = filter.Actor
+	}
+	if filter.Action != "" {
+		query["action"] = filter.Action
+	}
+	if filter.Resource != "" {
+		query["resource"] = filter.Resource
+	}
+	timestamp := bson.M{}
+	if !filter.From.IsZero() {
+		timestamp["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		timestamp["$lte"] = filter.To
+	}
+	if len(timestamp) > 0 {
+		query["timestamp"] = timestamp
+	}
+	var results []model.AuditEntry
+	err := ctx.Mongo.Find(ctx, "audit_log", query, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// DeleteForUser removes the entries recorded by actor or about resources.
+func (s *AuditStore) DeleteForUser(ctx *gofr.Context, actor string, resources []string) (int64, error) {
+	return ctx.Mongo.DeleteMany(ctx, "audit_log", bson.M{"$or": bson.A{
+		bson.M{"actor": actor},
+		bson.M{"resource": bson.M{"$in": resources}},
+	}})
+}
@@ -0,0 +1,48 @@
+package store
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+type CampaignStore struct{}
+
+func NewCampaignStore() *CampaignStore {
+	return &CampaignStore{}
+}
+
+func (s *CampaignStore) Insert(ctx *gofr.Context, campaign *model.Campaign) error {
+	campaign.ID = primitive.NewObjectID().Hex()
+	campaign.CreatedAt = time.Now().UTC()
+	_, err := ctx.Mongo.InsertOne(ctx, "campaigns", campaign)
+	return err
+}
+
+func (s *CampaignStore) FindByID(ctx *gofr.Context, id string) (*model.Campaign, error) {
+	var result model.Campaign
+	err := ctx.Mongo.FindOne(ctx, "campaigns", bson.M{"_id": id}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Find returns the campaigns of workspace, or the campaigns owner made
+// outside any workspace when workspace is empty.
+func (s *CampaignStore) Find(ctx *gofr.Context, owner, workspace string) ([]model.Campaign, error) {
+	filter := bson.M{"owner": owner, "workspace": bson.M{"$exists": false}}
+	if workspace != "" {
+		filter = bson.M{"workspace": workspace}
+	}
+	results := []model.Campaign{}
+	err := ctx.Mongo.Find(ctx, "campaigns", filter, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
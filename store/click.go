@@ -0,0 +1,52 @@
+package store
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+type ClickStore struct{}
+
+func NewClickStore() *ClickStore {
+	return &ClickStore{}
+}
+
+// InsertMany writes a batch of clicks in a single round trip.
+func (s *ClickStore) InsertMany(ctx *gofr.Context, clicks []model.Click) error {
+	documents := make([]any, len(clicks))
+	for i := range clicks {
+		clicks[i].ID = primitive.NewObjectID().Hex()
+		documents[i] = clicks[i]
+	}
+	_, err := ctx.Mongo.InsertMany(ctx, "clicks", documents)
+	return err
+}
+
+// DeleteBefore removes the clicks recorded before cutoff.
+func (s *ClickStore) DeleteBefore(ctx *gofr.Context, cutoff time.Time) (int64, error) {
+	return ctx.Mongo.DeleteMany(ctx, "clicks", bson.M{"timestamp": bson.M{"$lt": cutoff}})
+}
+
+// ForgetIPs removes the IPs of clicks that were not anonymized with mode, so
+// that switching to a stricter mode also covers the clicks already stored.
+func (s *ClickStore) ForgetIPs(ctx *gofr.Context, mode string) (int64, error) {
+	return ctx.Mongo.UpdateMany(ctx, "clicks",
+		bson.M{"ip": bson.M{"$exists": true}, "ip_mode": bson.M{"$ne": mode}},
+		bson.M{"$unset": bson.M{"ip": "", "ip_mode": ""}})
+}
+
+// SetOwner hands the clicks on the links in codes to owner.
+func (s *ClickStore) SetOwner(ctx *gofr.Context, codes []string, owner string) (int64, error) {
+	return ctx.Mongo.UpdateMany(ctx, "clicks", bson.M{"short_code": bson.M{"$in": codes}},
+		bson.M{"$set": bson.M{"owner": owner}})
+}
+
+// DeleteByOwner removes the clicks on owner's links.
+func (s *ClickStore) DeleteByOwner(ctx *gofr.Context, owner string) (int64, error) {
+	return ctx.Mongo.DeleteMany(ctx, "clicks", bson.M{"owner": owner})
+}
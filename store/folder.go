@@ -0,0 +1,64 @@
+package store
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+type FolderStore struct{}
+
+func NewFolderStore() *FolderStore {
+	return &FolderStore{}
+}
+
+func (s *FolderStore) Insert(ctx *gofr.Context, folder *model.Folder) error {
+	folder.ID = primitive.NewObjectID().Hex()
+	folder.CreatedAt = time.Now().UTC()
+	_, err := ctx.Mongo.InsertOne(ctx, "folders", folder)
+	return err
+}
+
+func (s *FolderStore) FindByPath(ctx *gofr.Context, owner, path string) (*model.Folder, error) {
+	var result model.Folder
+	err := ctx.Mongo.FindOne(ctx, "folders", bson.M{"owner": owner, "path": path}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *FolderStore) FindByID(ctx *gofr.Context, owner, id string) (*model.Folder, error) {
+	var result model.Folder
+	err := ctx.Mongo.FindOne(ctx, "folders", bson.M{"owner": owner, "_id": id}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *FolderStore) FindByOwner(ctx *gofr.Context, owner string) ([]model.Folder, error) {
+	var results []model.Folder
+	err := ctx.Mongo.Find(ctx, "folders", bson.M{"owner": owner}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (s *FolderStore) CountChildren(ctx *gofr.Context, owner, path string) (int64, error) {
+	return ctx.Mongo.CountDocuments(ctx, "folders", bson.M{"owner": owner, "parent": path})
+}
+
+func (s *FolderStore) Delete(ctx *gofr.Context, owner, id string) error {
+	_, err := ctx.Mongo.DeleteOne(ctx, "folders", bson.M{"owner": owner, "_id": id})
+	return err
+}
+
+func (s *FolderStore) DeleteByOwner(ctx *gofr.Context, owner string) (int64, error) {
+	return ctx.Mongo.DeleteMany(ctx, "folders", bson.M{"owner": owner})
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+// IdempotencyStore keeps the first response to each idempotency key.
+type IdempotencyStore struct{}
+
+func NewIdempotencyStore() *IdempotencyStore {
+	return &IdempotencyStore{}
+}
+
+// Reserve stores record unless its key is taken. It fails with a duplicate
+// key error when another request already used the key.
+func (s *IdempotencyStore) Reserve(ctx *gofr.Context, record *model.IdempotencyRecord) error {
+	record.CreatedAt = time.Now().UTC()
+	_, err := ctx.Mongo.InsertOne(ctx, "idempotency_keys", record)
+	return err
+}
+
+func (s *IdempotencyStore) Find(ctx *gofr.Context, id string) (*model.IdempotencyRecord, error) {
+	var result model.IdempotencyRecord
+	if err := ctx.Mongo.FindOne(ctx, "idempotency_keys", bson.M{"_id": id}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Complete stores the link created by the request that reserved id.
+func (s *IdempotencyStore) Complete(ctx *gofr.Context, id string, link *model.URL) error {
+	return ctx.Mongo.UpdateOne(ctx, "idempotency_keys", bson.M{"_id": id}, bson.M{"$set": bson.M{"link": link}})
+}
+
+// TakeOver reserves the key of record again for a new request when the key
+// expired, or when the request that reserved it for the same body stopped
+// before completing and its lease ran out. It reports false when the key is
+// still held, so only one retry can take it over.
+func (s *IdempotencyStore) TakeOver(ctx *gofr.Context, record *model.IdempotencyRecord, now time.Time) (bool, error) {
+	record.CreatedAt = now
+	n, err := ctx.Mongo.UpdateMany(ctx, "idempotency_keys", bson.M{
+		"_id": record.ID,
+		"$or": bson.A{
+			bson.M{"expires_at": bson.M{"$lt": now}},
+			bson.M{"fingerprint": record.Fingerprint, "link": bson.M{"$exists": false}, "lease_until": bson.M{"$lt": now}},
+		},
+	}, bson.M{
+		"$set": bson.M{
+			"fingerprint": record.Fingerprint,
+			"created_at":  record.CreatedAt,
+			"lease_until": record.LeaseUntil,
+			"expires_at":  record.ExpiresAt,
+		},
+		"$unset": bson.M{"link": ""},
+	})
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Release frees id so the request can be retried.
+func (s *IdempotencyStore) Release(ctx *gofr.Context, id string) error {
+	_, err := ctx.Mongo.DeleteOne(ctx, "idempotency_keys", bson.M{"_id": id})
+	return err
+}
+
+// DeleteByOwner removes the records whose stored link belongs to owner.
+func (s *IdempotencyStore) DeleteByOwner(ctx *gofr.Context, owner string) (int64, error) {
+	return ctx.Mongo.DeleteMany(ctx, "idempotency_keys", bson.M{"link.owner": owner})
+}
+
+// DeleteExpired removes records whose keys may be reused.
+func (s *IdempotencyStore) DeleteExpired(ctx *gofr.Context, now time.Time) (int64, error) {
+	return ctx.Mongo.DeleteMany(ctx, "idempotency_keys", bson.M{"expires_at": bson.M{"$lt": now}})
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+type PolicyStore struct{}
+
+func NewPolicyStore() *PolicyStore {
+	return &PolicyStore{}
+}
+
+func (s *PolicyStore) Insert(ctx *gofr.Context, rule *model.PolicyRule) error {
+	rule.ID = primitive.NewObjectID().Hex()
+	rule.CreatedAt = time.Now().UTC()
+	_, err := ctx.Mongo.InsertOne(ctx, "policies", rule)
+	return err
+}
+
+// Find returns the rules of workspaces, or every rule when none are given.
+func (s *PolicyStore) Find(ctx *gofr.Context, workspaces ...string) ([]model.PolicyRule, error) {
+	filter := bson.M{}
+	if len(workspaces) > 0 {
+		filter["workspace"] = bson.M{"$in": workspaces}
+	}
+	var results []model.PolicyRule
+	err := ctx.Mongo.Find(ctx, "policies", filter, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (s *PolicyStore) FindByID(ctx *gofr.Context, id string) (*model.PolicyRule, error) {
+	var result model.PolicyRule
+	err := ctx.Mongo.FindOne(ctx, "policies", bson.M{"_id": id}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *PolicyStore) Delete(ctx *gofr.Context, id string) error {
+	_, err := ctx.Mongo.DeleteOne(ctx, "policies", bson.M{"_id": id})
+	return err
+}
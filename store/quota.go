@@ -0,0 +1,79 @@
+package store
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+// QuotaStore keeps plan subscriptions and monthly usage counters.
+type QuotaStore struct{}
+
+func NewQuotaStore() *QuotaStore {
+	return &QuotaStore{}
+}
+
+func (s *QuotaStore) FindSubscription(ctx *gofr.Context, account string) (*model.Subscription, error) {
+	var result model.Subscription
+	err := ctx.Mongo.FindOne(ctx, "subscriptions", bson.M{"_id": account}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SetSubscription puts account on plan, replacing any earlier subscription.
+func (s *QuotaStore) SetSubscription(ctx *gofr.Context, subscription *model.Subscription) error {
+	subscription.UpdatedAt = time.Now().UTC()
+	n, err := ctx.Mongo.UpdateMany(ctx, "subscriptions", bson.M{"_id": subscription.Account},
+		bson.M{"$set": bson.M{"plan": subscription.Plan, "updated_at": subscription.UpdatedAt}})
+	if err != nil || n > 0 {
+		return err
+	}
+	_, err = ctx.Mongo.InsertOne(ctx, "subscriptions", subscription)
+	return err
+}
+
+func (s *QuotaStore) DeleteSubscription(ctx *gofr.Context, account string) (int64, error) {
+	return ctx.Mongo.DeleteOne(ctx, "subscriptions", bson.M{"_id": account})
+}
+
+// DeleteUsage removes every usage counter of account.
+func (s *QuotaStore) DeleteUsage(ctx *gofr.Context, account string) (int64, error) {
+	return ctx.Mongo.DeleteMany(ctx, "usage", bson.M{"account": account})
+}
+
+// AddUsage adds n to a monthly counter, creating it on first use.
+func (s *QuotaStore) AddUsage(ctx *gofr.Context, account, metric, period string, n int64) error {
+	id := account + "|" + metric + "|" + period
+	updated, err := ctx.Mongo.UpdateMany(ctx, "usage", bson.M{"_id": id}, bson.M{"$inc": bson.M{"count": n}})
+	if err != nil || updated > 0 {
+		return err
+	}
+	counter := model.UsageCounter{ID: id, Account: account, Metric: metric, Period: period, Count: n}
+	if _, insertErr := ctx.Mongo.InsertOne(ctx, "usage", counter); insertErr != nil {
+		// Another instance created the counter first; add to it instead. When
+		// there is still no counter the insert failed for another reason.
+		updated, err := ctx.Mongo.UpdateMany(ctx, "usage", bson.M{"_id": id}, bson.M{"$inc": bson.M{"count": n}})
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return insertErr
+		}
+	}
+	return nil
+}
+
+// FindUsage returns the counters of account in period.
+func (s *QuotaStore) FindUsage(ctx *gofr.Context, account, period string) ([]model.UsageCounter, error) {
+	var results []model.UsageCounter
+	err := ctx.Mongo.Find(ctx, "usage", bson.M{"account": account, "period": period}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
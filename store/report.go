@@ -0,0 +1,78 @@
+package store
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+type ReportStore struct{}
+
+func NewReportStore() *ReportStore {
+	return &ReportStore{}
+}
+
+func (s *ReportStore) Insert(ctx *gofr.Context, report *model.Report) error {
+	report.ID = primitive.NewObjectID().Hex()
+	report.CreatedAt = time.Now().UTC()
+	_, err := ctx.Mongo.InsertOne(ctx, "reports", report)
+	return err
+}
+
+func (s *ReportStore) FindByOwner(ctx *gofr.Context, owner string) ([]model.Report, error) {
+	results := []model.Report{}
+	err := ctx.Mongo.Find(ctx, "reports", bson.M{"owner": owner}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (s *ReportStore) FindByFrequency(ctx *gofr.Context, frequency string) ([]model.Report, error) {
+	var results []model.Report
+	err := ctx.Mongo.Find(ctx, "reports", bson.M{"frequency": frequency}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (s *ReportStore) FindByID(ctx *gofr.Context, owner, id string) (*model.Report, error) {
+	var result model.Report
+	err := ctx.Mongo.FindOne(ctx, "reports", bson.M{"owner": owner, "_id": id}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *ReportStore) Delete(ctx *gofr.Context, owner, id string) error {
+	_, err := ctx.Mongo.DeleteOne(ctx, "reports", bson.M{"owner": owner, "_id": id})
+	return err
+}
+
+func (s *ReportStore) DeleteByOwner(ctx *gofr.Context, owner string) (int64, error) {
+	return ctx.Mongo.DeleteMany(ctx, "reports", bson.M{"owner": owner})
+}
+
+// ClaimRun marks the report as run for the period starting at period. It
+// reports false when another instance claimed that period first.
+func (s *ReportStore) ClaimRun(ctx *gofr.Context, id string, period time.Time) (bool, error) {
+	n, err := ctx.Mongo.UpdateMany(ctx, "reports",
+		bson.M{"_id": id, "last_period": bson.M{"$ne": period}},
+		bson.M{"$set": bson.M{"last_period": period}})
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// RecordRun stores the outcome of the report's latest run.
+func (s *ReportStore) RecordRun(ctx *gofr.Context, id string, ranAt time.Time, runErr string) error {
+	return ctx.Mongo.UpdateOne(ctx, "reports", bson.M{"_id": id},
+		bson.M{"$set": bson.M{"last_run_at": ranAt, "last_error": runErr}})
+}
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+type RevisionStore struct{}
+
+func NewRevisionStore() *RevisionStore {
+	return &RevisionStore{}
+}
+
+func (s *RevisionStore) Insert(ctx *gofr.Context, revision *model.Revision) error {
+	revision.ID = primitive.NewObjectID().Hex()
+	revision.ChangedAt = time.Now().UTC()
+	_, err := ctx.Mongo.InsertOne(ctx, "revisions", revision)
+	return err
+}
+
+func (s *RevisionStore) FindByShortCode(ctx *gofr.Context, code string) ([]model.Revision, error) {
+	var results []model.Revision
+	err := ctx.Mongo.Find(ctx, "revisions", bson.M{"short_code": code}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (s *RevisionStore) FindOne(ctx *gofr.Context, code string, number int) (*model.Revision, error) {
+	var result model.Revision
+	err := ctx.Mongo.FindOne(ctx, "revisions", bson.M{"short_code": code, "number": number}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SetChangedBy credits the revisions userID made to replacement instead.
+func (s *RevisionStore) SetChangedBy(ctx *gofr.Context, userID, replacement string) (int64, error) {
+	return ctx.Mongo.UpdateMany(ctx, "revisions", bson.M{"changed_by": userID},
+		bson.M{"$set": bson.M{"changed_by": replacement}})
+}
+
+// DeleteByShortCodes removes the revision history of the links in codes.
+func (s *RevisionStore) DeleteByShortCodes(ctx *gofr.Context, codes []string) (int64, error) {
+	return ctx.Mongo.DeleteMany(ctx, "revisions", bson.M{"short_code": bson.M{"$in": codes}})
+}
@@ -0,0 +1,125 @@
+package store
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+// Dimension values become document keys, which may not contain "." or start with "$".
+var (
+	keyEscaper   = strings.NewReplacer("%", "%25", ".", "%2E", "$", "%24")
+	keyUnescaper = strings.NewReplacer("%2E", ".", "%24", "$", "%25", "%")
+)
+
+type RollupStore struct{}
+
+func NewRollupStore() *RollupStore {
+	return &RollupStore{}
+}
+
+// Add merges delta into its bucket, creating the bucket on first use. delta.ID
+// must identify the bucket so concurrent writers cannot create it twice.
+func (s *RollupStore) Add(ctx *gofr.Context, delta *model.ClickRollup) error {
+	inc := bson.M{"clicks": delta.Clicks, "bot_clicks": delta.BotClicks}
+	for dimension, values := range delta.Dimensions {
+		for value, count := range values {
+			inc["dimensions."+dimension+"."+keyEscaper.Replace(value)] = count
+		}
+	}
+	// Registers merge by keeping the highest rank, which $max does atomically.
+	registers := bson.M{}
+	for index, rank := range delta.Visitors {
+		registers["hll."+index] = rank
+	}
+	update := bson.M{"$inc": inc}
+	if len(registers) > 0 {
+		update["$max"] = registers
+	}
+
+	n, err := ctx.Mongo.UpdateMany(ctx, "click_rollups", bson.M{"_id": delta.ID}, update)
+	if err != nil || n > 0 {
+		return err
+	}
+
+	document := *delta
+	document.Dimensions = make(map[string]map[string]int64, len(delta.Dimensions))
+	for dimension, values := range delta.Dimensions {
+		escaped := make(map[string]int64, len(values))
+		for value, count := range values {
+			escaped[keyEscaper.Replace(value)] = count
+		}
+		document.Dimensions[dimension] = escaped
+	}
+	if _, insertErr := ctx.Mongo.InsertOne(ctx, "click_rollups", document); insertErr != nil {
+		// Another worker created the bucket first; add to it instead. When
+		// there is still no bucket the insert failed for another reason.
+		n, err := ctx.Mongo.UpdateMany(ctx, "click_rollups", bson.M{"_id": delta.ID}, update)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return insertErr
+		}
+	}
+	return nil
+}
+
+// Find returns the buckets of a link at one granularity that start in [from, to).
+func (s *RollupStore) Find(ctx *gofr.Context, code, granularity string, from, to time.Time) ([]model.ClickRollup, error) {
+	return s.find(ctx, bson.M{
+		"short_code":  code,
+		"granularity": granularity,
+		"bucket":      bson.M{"$gte": from, "$lt": to},
+	})
+}
+
+// FindMany is Find for several links at once.
+func (s *RollupStore) FindMany(ctx *gofr.Context, codes []string, granularity string, from, to time.Time) ([]model.ClickRollup, error) {
+	return s.find(ctx, bson.M{
+		"short_code":  bson.M{"$in": codes},
+		"granularity": granularity,
+		"bucket":      bson.M{"$gte": from, "$lt": to},
+	})
+}
+
+func (s *RollupStore) find(ctx *gofr.Context, filter bson.M) ([]model.ClickRollup, error) {
+	var results []model.ClickRollup
+	err := ctx.Mongo.Find(ctx, "click_rollups", filter, &results)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		for dimension, values := range results[i].Dimensions {
+			unescaped := make(map[string]int64, len(values))
+			for value, count := range values {
+				unescaped[keyUnescaper.Replace(value)] = count
+			}
+			results[i].Dimensions[dimension] = unescaped
+		}
+	}
+	return results, nil
+}
+
+// DeleteBefore removes the buckets of a granularity that start before cutoff.
+func (s *RollupStore) DeleteBefore(ctx *gofr.Context, granularity string, cutoff time.Time) (int64, error) {
+	return ctx.Mongo.DeleteMany(ctx, "click_rollups", bson.M{
+		"granularity": granularity,
+		"bucket":      bson.M{"$lt": cutoff},
+	})
+}
+
+// SetOwner hands the rollups of the links in codes to owner.
+func (s *RollupStore) SetOwner(ctx *gofr.Context, codes []string, owner string) (int64, error) {
+	return ctx.Mongo.UpdateMany(ctx, "click_rollups", bson.M{"short_code": bson.M{"$in": codes}},
+		bson.M{"$set": bson.M{"owner": owner}})
+}
+
+// DeleteByOwner removes the rollups of owner's links.
+func (s *RollupStore) DeleteByOwner(ctx *gofr.Context, owner string) (int64, error) {
+	return ctx.Mongo.DeleteMany(ctx, "click_rollups", bson.M{"owner": owner})
+}
@@ -0,0 +1,46 @@
+package store
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+// SSOLoginStore keeps sign-ins that are waiting for the identity provider.
+type SSOLoginStore struct{}
+
+func NewSSOLoginStore() *SSOLoginStore {
+	return &SSOLoginStore{}
+}
+
+func (s *SSOLoginStore) Insert(ctx *gofr.Context, login *model.SSOLogin) error {
+	login.CreatedAt = time.Now().UTC()
+	_, err := ctx.Mongo.InsertOne(ctx, "sso_logins", login)
+	return err
+}
+
+// Take removes and returns the login with the given ID. Only one of several
+// concurrent callers gets it; the others see mongo.ErrNoDocuments.
+func (s *SSOLoginStore) Take(ctx *gofr.Context, id string) (*model.SSOLogin, error) {
+	var result model.SSOLogin
+	if err := ctx.Mongo.FindOne(ctx, "sso_logins", bson.M{"_id": id}, &result); err != nil {
+		return nil, err
+	}
+	deleted, err := ctx.Mongo.DeleteOne(ctx, "sso_logins", bson.M{"_id": id})
+	if err != nil {
+		return nil, err
+	}
+	if deleted == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &result, nil
+}
+
+// DeleteExpired removes logins that were never completed.
+func (s *SSOLoginStore) DeleteExpired(ctx *gofr.Context, now time.Time) (int64, error) {
+	return ctx.Mongo.DeleteMany(ctx, "sso_logins", bson.M{"expires_at": bson.M{"$lt": now}})
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -15,8 +16,26 @@ func NewURLStore() *URLStore {
 	return &URLStore{}
 }
 
+// stamp returns the current time as MongoDB stores it, to the millisecond,
+// so a link's updated_at reads back exactly as it was written.
+func stamp() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+// unchanged matches the link only while it is as it was read.
+func unchanged(link *model.URL) bson.M {
+	filter := bson.M{"owner": link.Owner, "short_code": link.ShortCode, "updated_at": link.UpdatedAt}
+	if link.UpdatedAt.IsZero() {
+		// Links created before updated_at was kept.
+		filter["updated_at"] = bson.M{"$exists": false}
+	}
+	return filter
+}
+
 func (s *URLStore) Insert(ctx *gofr.Context, url *model.URL) error {
-	url.CreatedAt = time.Now().UTC()
+	url.CreatedAt = stamp()
+	url.UpdatedAt = url.CreatedAt
+	url.Revision = 1
 	_, err := ctx.Mongo.InsertOne(ctx, "urls", url)
 	return err
 }
@@ -29,3 +48,247 @@ func (s *URLStore) FindByShortCode(ctx *gofr.Context, code string) (*model.URL,
 	}
 	return &result, nil
 }
+
+// DeleteByShortCode removes a link whoever owns it and reports how many
+// links were removed.
+func (s *URLStore) DeleteByShortCode(ctx *gofr.Context, code string) (int64, error) {
+	return ctx.Mongo.DeleteOne(ctx, "urls", bson.M{"short_code": code})
+}
+
+// DeleteByOwner removes the links of owner outside any workspace.
+func (s *URLStore) DeleteByOwner(ctx *gofr.Context, owner string) (int64, error) {
+	return ctx.Mongo.DeleteMany(ctx, "urls", bson.M{"owner": owner, "workspace": bson.M{"$exists": false}})
+}
+
+// SetOwner hands the links in codes to owner.
+func (s *URLStore) SetOwner(ctx *gofr.Context, codes []string, owner string) (int64, error) {
+	return ctx.Mongo.UpdateMany(ctx, "urls", bson.M{"short_code": bson.M{"$in": codes}},
+		bson.M{"$set": bson.M{"owner": owner}})
+}
+
+// SetScreening stores the latest screening verdict of a link; nil removes it.
+func (s *URLStore) SetScreening(ctx *gofr.Context, code string, screening *model.Screening) error {
+	update := bson.M{"$set": bson.M{"screening": screening}}
+	if screening == nil {
+		update = bson.M{"$unset": bson.M{"screening": ""}}
+	}
+	return ctx.Mongo.UpdateOne(ctx, "urls", bson.M{"short_code": code}, update)
+}
+
+// FindScreenedBefore returns the links whose short code starts with prefix
+// and whose destination was last screened before cutoff, or never.
+func (s *URLStore) FindScreenedBefore(ctx *gofr.Context, prefix string, cutoff time.Time) ([]model.URL, error) {
+	var results []model.URL
+	err := ctx.Mongo.Find(ctx, "urls", bson.M{
+		"short_code": bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)},
+		"$or": bson.A{
+			bson.M{"screening": bson.M{"$exists": false}},
+			bson.M{"screening.checked_at": bson.M{"$lt": cutoff}},
+		},
+	}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// SetModeration stores the latest moderation outcome of a link; nil removes it.
+func (s *URLStore) SetModeration(ctx *gofr.Context, code string, moderation *model.Moderation) error {
+	update := bson.M{"$set": bson.M{"moderation": moderation, "updated_at": stamp()}}
+	if moderation == nil {
+		update = bson.M{"$unset": bson.M{"moderation": ""}, "$set": bson.M{"updated_at": stamp()}}
+	}
+	return ctx.Mongo.UpdateOne(ctx, "urls", bson.M{"short_code": code}, update)
+}
+
+// FindFlagged returns the links screening flagged that no admin has reviewed.
+func (s *URLStore) FindFlagged(ctx *gofr.Context) ([]model.URL, error) {
+	var results []model.URL
+	err := ctx.Mongo.Find(ctx, "urls", bson.M{
+		"screening.verdict": bson.M{"$in": []string{model.VerdictSuspicious, model.VerdictMalicious}},
+		"moderation":        bson.M{"$exists": false},
+	}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (s *URLStore) FindByShortCodes(ctx *gofr.Context, codes []string) ([]model.URL, error) {
+	var results []model.URL
+	err := ctx.Mongo.Find(ctx, "urls", bson.M{"short_code": bson.M{"$in": codes}}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (s *URLStore) FindByCampaign(ctx *gofr.Context, campaignID string) ([]model.URL, error) {
+	var results []model.URL
+	err := ctx.Mongo.Find(ctx, "urls", bson.M{"campaign_id": campaignID}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (s *URLStore) IncrementClicks(ctx *gofr.Context, code string, people, bots int64) error {
+	return ctx.Mongo.UpdateOne(ctx, "urls", bson.M{"short_code": code},
+		bson.M{"$inc": bson.M{"click_count": people, "bot_click_count": bots}})
+}
+
+func (s *URLStore) Find(ctx *gofr.Context, filter model.URLFilter) ([]model.URL, error) {
+	query := bson.M{"owner": filter.Owner}
+	if filter.Workspace != "" {
+		query = bson.M{"workspace": filter.Workspace}
+	}
+	if len(filter.Tags) > 0 {
+		query["tags"] = bson.M{"$all": filter.Tags}
+	}
+	if filter.Folder != "" {
+		if filter.Recursive {
+			query["$or"] = bson.A{
+				bson.M{"folder": filter.Folder},
+				bson.M{"folder": bson.M{"$regex": "^" + regexp.QuoteMeta(filter.Folder) + "/"}},
+			}
+		} else {
+			query["folder"] = filter.Folder
+		}
+	}
+	var results []model.URL
+	err := ctx.Mongo.Find(ctx, "urls", query, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// CountForAccount counts the links of a workspace, or the links of owner
+// outside any workspace when workspace is empty. With neither it counts the
+// links made without signing in.
+func (s *URLStore) CountForAccount(ctx *gofr.Context, owner, workspace string) (int64, error) {
+	if workspace != "" {
+		return ctx.Mongo.CountDocuments(ctx, "urls", bson.M{"workspace": workspace})
+	}
+	if owner == "" {
+		return ctx.Mongo.CountDocuments(ctx, "urls",
+			bson.M{"owner": bson.M{"$exists": false}, "workspace": bson.M{"$exists": false}})
+	}
+	return ctx.Mongo.CountDocuments(ctx, "urls", bson.M{"owner": owner, "workspace": bson.M{"$exists": false}})
+}
+
+func (s *URLStore) CountInFolder(ctx *gofr.Context, owner, folder string) (int64, error) {
+	return ctx.Mongo.CountDocuments(ctx, "urls", bson.M{"owner": owner, "folder": folder})
+}
+
+// SetTags replaces the tags of link if it has not changed since it was read.
+// It reports whether the link matched.
+func (s *URLStore) SetTags(ctx *gofr.Context, link *model.URL, tags []string) (bool, error) {
+	return s.setUnchanged(ctx, link, bson.M{"tags": tags})
+}
+
+// SetFolder moves link to folder if it has not changed since it was read.
+// It reports whether the link matched.
+func (s *URLStore) SetFolder(ctx *gofr.Context, link *model.URL, folder string) (bool, error) {
+	return s.setUnchanged(ctx, link, bson.M{"folder": folder})
+}
+
+func (s *URLStore) setUnchanged(ctx *gofr.Context, link *model.URL, fields bson.M) (bool, error) {
+	updated := stamp()
+	fields["updated_at"] = updated
+	n, err := ctx.Mongo.UpdateMany(ctx, "urls", unchanged(link), bson.M{"$set": fields})
+	if err != nil || n == 0 {
+		return false, err
+	}
+	link.UpdatedAt = updated
+	return true, nil
+}
+
+// BulkTag adds and removes tags on the links in codes that the owner made
+// outside a workspace or that belong to one of workspaces. MongoDB rejects
+// $addToSet and $pull on the same field in one update, so they run separately.
+func (s *URLStore) BulkTag(ctx *gofr.Context, owner string, workspaces, codes, add, remove []string) (int64, error) {
+	filter := bson.M{"owner": owner, "workspace": bson.M{"$exists": false}, "short_code": bson.M{"$in": codes}}
+	if len(workspaces) > 0 {
+		filter = bson.M{
+			"short_code": bson.M{"$in": codes},
+			"$or": bson.A{
+				bson.M{"owner": owner, "workspace": bson.M{"$exists": false}},
+				bson.M{"workspace": bson.M{"$in": workspaces}},
+			},
+		}
+	}
+	// Adding and removing are separate updates, so neither count tells how
+	// many distinct links the request applied to.
+	matched, err := ctx.Mongo.CountDocuments(ctx, "urls", filter)
+	if err != nil {
+		return 0, err
+	}
+	if len(add) > 0 {
+		_, err := ctx.Mongo.UpdateMany(ctx, "urls", filter, bson.M{
+			"$addToSet": bson.M{"tags": bson.M{"$each": add}},
+			"$set":      bson.M{"updated_at": stamp()},
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	if len(remove) > 0 {
+		_, err := ctx.Mongo.UpdateMany(ctx, "urls", filter, bson.M{
+			"$pull": bson.M{"tags": bson.M{"$in": remove}},
+			"$set":  bson.M{"updated_at": stamp()},
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return matched, nil
+}
+
+// UpdateSettings replaces the settings of link if it is still at the revision
+// it was read at, bumping its revision. When the destination changes, its
+// screening verdict is replaced in the same update, nil removing it, and an
+// admin's clearing is dropped. It reports whether the link matched.
+func (s *URLStore) UpdateSettings(
+	ctx *gofr.Context, link *model.URL, settings model.LinkSettings, screening *model.Screening,
+) (bool, error) {
+	updated := stamp()
+	filter := unchanged(link)
+	filter["revision"] = link.Revision
+	if link.Revision == 0 {
+		// Links created before revisions were kept.
+		filter["revision"] = bson.M{"$exists": false}
+	}
+	set := bson.M{
+		"original_url":   settings.Original,
+		"forward_query":  settings.ForwardQuery,
+		"query_conflict": settings.QueryConflict,
+		"wildcard":       settings.Wildcard,
+		"campaign_id":    settings.CampaignID,
+		"no_tracking":    settings.NoTracking,
+		"updated_at":     updated,
+	}
+	update := bson.M{"$set": set, "$inc": bson.M{"revision": 1}}
+	// The verdict on the old destination no longer applies to the new one,
+	// and neither does an admin clearing it. A disabled link stays disabled.
+	if settings.Original != link.Original {
+		unset := bson.M{}
+		if screening != nil {
+			set["screening"] = screening
+		} else {
+			unset["screening"] = ""
+		}
+		if link.Moderation != nil && link.Moderation.Status == model.ModerationCleared {
+			unset["moderation"] = ""
+		}
+		if len(unset) > 0 {
+			update["$unset"] = unset
+		}
+	}
+	n, err := ctx.Mongo.UpdateMany(ctx, "urls", filter, update)
+	if err != nil || n == 0 {
+		return false, err
+	}
+	link.UpdatedAt = updated
+	return true, nil
+}
@@ -0,0 +1,141 @@
+package store
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+type WebhookStore struct{}
+
+func NewWebhookStore() *WebhookStore {
+	return &WebhookStore{}
+}
+
+func (s *WebhookStore) Insert(ctx *gofr.Context, webhook *model.Webhook) error {
+	webhook.ID = primitive.NewObjectID().Hex()
+	webhook.CreatedAt = time.Now().UTC()
+	_, err := ctx.Mongo.InsertOne(ctx, "webhooks", webhook)
+	return err
+}
+
+func (s *WebhookStore) FindByOwner(ctx *gofr.Context, owner string) ([]model.Webhook, error) {
+	var results []model.Webhook
+	err := ctx.Mongo.Find(ctx, "webhooks", bson.M{"owner": owner}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (s *WebhookStore) FindByID(ctx *gofr.Context, owner, id string) (*model.Webhook, error) {
+	var result model.Webhook
+	err := ctx.Mongo.FindOne(ctx, "webhooks", bson.M{"owner": owner, "_id": id}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// FindSubscribed returns the owner's active webhooks listening for eventType.
+func (s *WebhookStore) FindSubscribed(ctx *gofr.Context, owner, eventType string) ([]model.Webhook, error) {
+	var results []model.Webhook
+	err := ctx.Mongo.Find(ctx, "webhooks", bson.M{"owner": owner, "active": true, "events": eventType}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (s *WebhookStore) Delete(ctx *gofr.Context, owner, id string) error {
+	_, err := ctx.Mongo.DeleteOne(ctx, "webhooks", bson.M{"owner": owner, "_id": id})
+	return err
+}
+
+func (s *WebhookStore) DeleteByOwner(ctx *gofr.Context, owner string) (int64, error) {
+	return ctx.Mongo.DeleteMany(ctx, "webhooks", bson.M{"owner": owner})
+}
+
+// DeleteDeliveries removes the delivery log of the webhooks in ids.
+func (s *WebhookStore) DeleteDeliveries(ctx *gofr.Context, ids []string) (int64, error) {
+	return ctx.Mongo.DeleteMany(ctx, "webhook_deliveries", bson.M{"webhook_id": bson.M{"$in": ids}})
+}
+
+func (s *WebhookStore) InsertDelivery(ctx *gofr.Context, delivery *model.WebhookDelivery) error {
+	delivery.ID = primitive.NewObjectID().Hex()
+	delivery.CreatedAt = time.Now().UTC()
+	delivery.UpdatedAt = delivery.CreatedAt
+	_, err := ctx.Mongo.InsertOne(ctx, "webhook_deliveries", delivery)
+	return err
+}
+
+func (s *WebhookStore) UpdateDelivery(ctx *gofr.Context, delivery *model.WebhookDelivery) error {
+	delivery.UpdatedAt = time.Now().UTC()
+	return ctx.Mongo.UpdateOne(ctx, "webhook_deliveries", bson.M{"_id": delivery.ID}, bson.M{"$set": bson.M{
+		"status":           delivery.Status,
+		"attempts":         delivery.Attempts,
+		"last_status_code": delivery.LastStatusCode,
+		"last_error":       delivery.LastError,
+		"next_attempt_at":  delivery.NextAttemptAt,
+		"updated_at":       delivery.UpdatedAt,
+	}})
+}
+
+func (s *WebhookStore) FindDeliveries(ctx *gofr.Context, webhookID string) ([]model.WebhookDelivery, error) {
+	var results []model.WebhookDelivery
+	err := ctx.Mongo.Find(ctx, "webhook_deliveries", bson.M{"webhook_id": webhookID}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (s *WebhookStore) FindDelivery(ctx *gofr.Context, webhookID, id string) (*model.WebhookDelivery, error) {
+	var result model.WebhookDelivery
+	err := ctx.Mongo.FindOne(ctx, "webhook_deliveries", bson.M{"webhook_id": webhookID, "_id": id}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// FindDueDeliveries returns the pending deliveries whose next attempt is due at now.
+func (s *WebhookStore) FindDueDeliveries(ctx *gofr.Context, now time.Time) ([]model.WebhookDelivery, error) {
+	var results []model.WebhookDelivery
+	err := ctx.Mongo.Find(ctx, "webhook_deliveries", bson.M{
+		"status":          model.DeliveryPending,
+		"next_attempt_at": bson.M{"$lte": now},
+	}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ClaimDelivery moves the next attempt of delivery to until, so no other
+// instance attempts it meanwhile. It reports false when another instance
+// claimed or attempted the delivery first.
+func (s *WebhookStore) ClaimDelivery(ctx *gofr.Context, delivery *model.WebhookDelivery, until time.Time) (bool, error) {
+	n, err := ctx.Mongo.UpdateMany(ctx, "webhook_deliveries",
+		bson.M{"_id": delivery.ID, "status": model.DeliveryPending, "next_attempt_at": delivery.NextAttemptAt},
+		bson.M{"$set": bson.M{"next_attempt_at": until}})
+	if err != nil || n == 0 {
+		return false, err
+	}
+	delivery.NextAttemptAt = until
+	return true, nil
+}
+
+// FindByIDs returns the webhooks in ids, whatever their owner.
+func (s *WebhookStore) FindByIDs(ctx *gofr.Context, ids []string) ([]model.Webhook, error) {
+	var results []model.Webhook
+	err := ctx.Mongo.Find(ctx, "webhooks", bson.M{"_id": bson.M{"$in": ids}}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,166 @@
+package store
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gofr.dev/pkg/gofr"
+
+	"github.com/sksmagr23/url-shortener-gofr/model"
+)
+
+// WorkspaceStore keeps workspaces, their members and their pending invitations.
+type WorkspaceStore struct{}
+
+func NewWorkspaceStore() *WorkspaceStore {
+	return &WorkspaceStore{}
+}
+
+func (s *WorkspaceStore) Insert(ctx *gofr.Context, workspace *model.Workspace) error {
+	workspace.ID = primitive.NewObjectID().Hex()
+	workspace.CreatedAt = time.Now().UTC()
+	_, err := ctx.Mongo.InsertOne(ctx, "workspaces", workspace)
+	return err
+}
+
+func (s *WorkspaceStore) FindByID(ctx *gofr.Context, id string) (*model.Workspace, error) {
+	var result model.Workspace
+	err := ctx.Mongo.FindOne(ctx, "workspaces", bson.M{"_id": id}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *WorkspaceStore) FindByIDs(ctx *gofr.Context, ids []string) ([]model.Workspace, error) {
+	var results []model.Workspace
+	err := ctx.Mongo.Find(ctx, "workspaces", bson.M{"_id": bson.M{"$in": ids}}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (s *WorkspaceStore) AddMember(ctx *gofr.Context, member *model.Membership) error {
+	member.JoinedAt = time.Now().UTC()
+	_, err := ctx.Mongo.InsertOne(ctx, "workspace_members", member)
+	return err
+}
+
+func (s *WorkspaceStore) FindMember(ctx *gofr.Context, workspaceID, userID string) (*model.Membership, error) {
+	var result model.Membership
+	err := ctx.Mongo.FindOne(ctx, "workspace_members", bson.M{"workspace_id": workspaceID, "user_id": userID}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *WorkspaceStore) FindMembers(ctx *gofr.Context, workspaceID string) ([]model.Membership, error) {
+	var results []model.Membership
+	err := ctx.Mongo.Find(ctx, "workspace_members", bson.M{"workspace_id": workspaceID}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// FindMemberships returns every workspace membership of userID.
+func (s *WorkspaceStore) FindMemberships(ctx *gofr.Context, userID string) ([]model.Membership, error) {
+	var results []model.Membership
+	err := ctx.Mongo.Find(ctx, "workspace_members", bson.M{"user_id": userID}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// AddOwner counts one more owner of the workspace.
+func (s *WorkspaceStore) AddOwner(ctx *gofr.Context, workspaceID string) error {
+	return ctx.Mongo.UpdateOne(ctx, "workspaces", bson.M{"_id": workspaceID}, bson.M{"$inc": bson.M{"owners": 1}})
+}
+
+// ReleaseOwner counts one owner less unless the workspace is down to its
+// last one. It reports false then, so owners stepping down at the same time
+// cannot leave the workspace without one.
+func (s *WorkspaceStore) ReleaseOwner(ctx *gofr.Context, workspaceID string) (bool, error) {
+	n, err := ctx.Mongo.UpdateMany(ctx, "workspaces",
+		bson.M{"_id": workspaceID, "owners": bson.M{"$gt": 1}},
+		bson.M{"$inc": bson.M{"owners": -1}})
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (s *WorkspaceStore) SetRole(ctx *gofr.Context, workspaceID, userID, role string) error {
+	return ctx.Mongo.UpdateOne(ctx, "workspace_members",
+		bson.M{"workspace_id": workspaceID, "user_id": userID},
+		bson.M{"$set": bson.M{"role": role}})
+}
+
+func (s *WorkspaceStore) RemoveMember(ctx *gofr.Context, workspaceID, userID string) error {
+	_, err := ctx.Mongo.DeleteOne(ctx, "workspace_members", bson.M{"workspace_id": workspaceID, "user_id": userID})
+	return err
+}
+
+// DeleteMemberships removes userID from every workspace.
+func (s *WorkspaceStore) DeleteMemberships(ctx *gofr.Context, userID string) (int64, error) {
+	return ctx.Mongo.DeleteMany(ctx, "workspace_members", bson.M{"user_id": userID})
+}
+
+// SetCreatedBy credits the workspaces userID created to replacement instead.
+func (s *WorkspaceStore) SetCreatedBy(ctx *gofr.Context, userID, replacement string) (int64, error) {
+	return ctx.Mongo.UpdateMany(ctx, "workspaces", bson.M{"created_by": userID},
+		bson.M{"$set": bson.M{"created_by": replacement}})
+}
+
+// SetInvitedBy credits the invitations userID sent to replacement instead.
+func (s *WorkspaceStore) SetInvitedBy(ctx *gofr.Context, userID, replacement string) (int64, error) {
+	return ctx.Mongo.UpdateMany(ctx, "workspace_invitations", bson.M{"invited_by": userID},
+		bson.M{"$set": bson.M{"invited_by": replacement}})
+}
+
+// DeleteInvitationsOf removes the invitations addressed to or accepted by userID.
+func (s *WorkspaceStore) DeleteInvitationsOf(ctx *gofr.Context, userID string) (int64, error) {
+	return ctx.Mongo.DeleteMany(ctx, "workspace_invitations",
+		bson.M{"$or": bson.A{bson.M{"user_id": userID}, bson.M{"accepted_by": userID}}})
+}
+
+func (s *WorkspaceStore) InsertInvitation(ctx *gofr.Context, invitation *model.Invitation) error {
+	invitation.ID = primitive.NewObjectID().Hex()
+	invitation.CreatedAt = time.Now().UTC()
+	_, err := ctx.Mongo.InsertOne(ctx, "workspace_invitations", invitation)
+	return err
+}
+
+func (s *WorkspaceStore) FindInvitation(ctx *gofr.Context, tokenHash string) (*model.Invitation, error) {
+	var result model.Invitation
+	err := ctx.Mongo.FindOne(ctx, "workspace_invitations", bson.M{"token_hash": tokenHash}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *WorkspaceStore) FindInvitations(ctx *gofr.Context, workspaceID string) ([]model.Invitation, error) {
+	var results []model.Invitation
+	err := ctx.Mongo.Find(ctx, "workspace_invitations", bson.M{"workspace_id": workspaceID}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// AcceptInvitation marks an invitation used by userID. It reports false when
+// the invitation was used already, so concurrent accepts cannot both succeed.
+func (s *WorkspaceStore) AcceptInvitation(ctx *gofr.Context, id, userID string, at time.Time) (bool, error) {
+	n, err := ctx.Mongo.UpdateMany(ctx, "workspace_invitations",
+		bson.M{"_id": id, "accepted_by": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"accepted_by": userID, "accepted_at": at}})
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
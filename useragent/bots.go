@@ -0,0 +1,136 @@
+package useragent
+
+import (
+	"regexp"
+	"strings"
+)
+
+// botPattern maps a user agent token (lower case) to the bot it identifies.
+// Tokens only match as whole words, so "bingbot" does not match "xbingbotx".
+type botPattern struct {
+	token string
+	name  string
+}
+
+// botPatterns lists link unfurlers first since they account for most bot
+// traffic on shared links, then search engines, uptime monitors and HTTP
+// libraries.
+var botPatterns = []botPattern{
+	{"slackbot", "Slackbot"},
+	{"slack-imgproxy", "Slackbot"},
+	{"twitterbot", "Twitterbot"},
+	{"facebookexternalhit", "Facebook"},
+	{"facebookcatalog", "Facebook"},
+	{"linkedinbot", "LinkedInBot"},
+	{"discordbot", "Discordbot"},
+	{"telegrambot", "TelegramBot"},
+	{"whatsapp", "WhatsApp"},
+	{"skypeuripreview", "Skype"},
+	{"microsoftpreview", "Microsoft Preview"},
+	{"redditbot", "Redditbot"},
+	{"pinterestbot", "Pinterestbot"},
+	{"embedly", "Embedly"},
+	{"googlebot", "Googlebot"},
+	{"googleother", "Googlebot"},
+	{"adsbot-google", "Googlebot"},
+	{"bingbot", "Bingbot"},
+	{"bingpreview", "Bingbot"},
+	{"yandexbot", "YandexBot"},
+	{"baiduspider", "Baiduspider"},
+	{"duckduckbot", "DuckDuckBot"},
+	{"applebot", "Applebot"},
+	{"petalbot", "PetalBot"},
+	{"ahrefsbot", "AhrefsBot"},
+	{"semrushbot", "SemrushBot"},
+	{"mj12bot", "MJ12bot"},
+	{"pingdom", "Pingdom"},
+	{"uptimerobot", "UptimeRobot"},
+	{"statuscake", "StatusCake"},
+	{"site24x7", "Site24x7"},
+	{"newrelicpinger", "New Relic"},
+	{"datadogsynthetics", "Datadog"},
+	{"headlesschrome", "Headless Chrome"},
+	// Product names without the "/" before the version, which would leave
+	// a digit right after the token and never match as a whole word.
+	{"curl", "curl"},
+	{"wget", "Wget"},
+	{"python-requests", "python-requests"},
+	{"go-http-client", "Go HTTP client"},
+	{"okhttp", "OkHttp"},
+}
+
+// genericBot catches the remaining bots that name themselves one: the word
+// "bot", "crawler" or "spider" on its own, or ending a product token such as
+// "ExampleCrawler/3.1". A bare substring would also match phone makers like
+// "Cubot".
+var genericBot = regexp.MustCompile(`\b(bot|crawler|spider)\b|[a-z0-9](bot|crawler|spider)/`)
+
+// OtherBot is reported for bots that are not on the pattern list.
+const OtherBot = "Other bot"
+
+// BotMatcher recognises bots by their user agent.
+type BotMatcher struct {
+	patterns []botPattern
+}
+
+var defaultBots = NewBotMatcher()
+
+// NewBotMatcher returns a matcher using the built-in pattern list plus extra
+// user agent tokens, which are reported under their own name.
+func NewBotMatcher(extra ...string) *BotMatcher {
+	m := &BotMatcher{}
+	for _, token := range extra {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		m.patterns = append(m.patterns, botPattern{token: strings.ToLower(token), name: token})
+	}
+	m.patterns = append(m.patterns, botPatterns...)
+	return m
+}
+
+// Match returns the name of the bot header identifies, or "" when it names
+// none. An empty header is not matched.
+func (m *BotMatcher) Match(header string) string {
+	lower := strings.ToLower(header)
+	for _, pattern := range m.patterns {
+		if containsWord(lower, pattern.token) {
+			return pattern.name
+		}
+	}
+	if genericBot.MatchString(lower) {
+		return OtherBot
+	}
+	return ""
+}
+
+// Bot returns the name of the bot header identifies using the built-in
+// pattern list, or "" when it names none.
+func Bot(header string) string {
+	return defaultBots.Match(header)
+}
+
+// containsWord reports whether token occurs in s with no letter or digit
+// directly before or after it.
+func containsWord(s, token string) bool {
+	for offset := 0; ; {
+		i := strings.Index(s[offset:], token)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(token)
+		if !alphanumericAt(s, start-1) && !alphanumericAt(s, end) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+func alphanumericAt(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return false
+	}
+	c := s[i]
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,120 @@
+// Package useragent extracts the browser, operating system and device class
+// from User-Agent headers.
+package useragent
+
+import "strings"
+
+// Device classes.
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+)
+
+// Other is reported for browsers and operating systems that are not recognised.
+const Other = "Other"
+
+type UserAgent struct {
+	Browser        string
+	BrowserVersion string // major version only
+	OS             string
+	Device         string
+}
+
+// browserRules are checked in order: many browsers also announce the engine
+// tokens of the browsers they derive from (Edge says "Chrome", Chrome says
+// "Safari"), so the more specific tokens come first.
+var browserRules = []struct {
+	token string
+	name  string
+}{
+	{"Edg/", "Edge"},
+	{"EdgA/", "Edge"},
+	{"EdgiOS/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Opera/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"YaBrowser/", "Yandex Browser"},
+	{"FxiOS/", "Firefox"},
+	{"Firefox/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Version/", "Safari"},
+	{"MSIE ", "Internet Explorer"},
+	{"rv:", "Internet Explorer"},
+}
+
+var osRules = []struct {
+	token string
+	name  string
+}{
+	{"Windows", "Windows"},
+	{"iPhone", "iOS"},
+	{"iPad", "iOS"},
+	{"iPod", "iOS"},
+	{"Android", "Android"},
+	{"CrOS", "ChromeOS"},
+	{"Macintosh", "macOS"},
+	{"Mac OS X", "macOS"},
+	{"Linux", "Linux"},
+}
+
+// Parse extracts what it can from a User-Agent header.
+func Parse(header string) UserAgent {
+	ua := UserAgent{Browser: Other, OS: Other, Device: DeviceDesktop}
+	for _, rule := range osRules {
+		if strings.Contains(header, rule.token) {
+			ua.OS = rule.name
+			break
+		}
+	}
+	ua.Device = device(header)
+	if ua.Device == DeviceBot {
+		return ua
+	}
+	for _, rule := range browserRules {
+		version, ok := versionAfter(header, rule.token)
+		if !ok {
+			continue
+		}
+		if rule.name == "Safari" && !strings.Contains(header, "Safari/") {
+			continue
+		}
+		if rule.token == "rv:" && !strings.Contains(header, "Trident/") {
+			continue
+		}
+		ua.Browser, ua.BrowserVersion = rule.name, version
+		break
+	}
+	return ua
+}
+
+func device(header string) string {
+	if header == "" || Bot(header) != "" {
+		return DeviceBot
+	}
+	lower := strings.ToLower(header)
+	switch {
+	case strings.Contains(header, "iPad"), strings.Contains(lower, "tablet"),
+		strings.Contains(header, "Android") && !strings.Contains(header, "Mobile"):
+		return DeviceTablet
+	case strings.Contains(header, "Mobi"), strings.Contains(header, "iPhone"), strings.Contains(header, "iPod"):
+		return DeviceMobile
+	}
+	return DeviceDesktop
+}
+
+// versionAfter returns the major version following token, e.g. "124" for
+// token "Chrome/" in "Chrome/124.0.6367.91".
+func versionAfter(header, token string) (string, bool) {
+	_, rest, found := strings.Cut(header, token)
+	if !found {
+		return "", false
+	}
+	end := strings.IndexFunc(rest, func(r rune) bool { return r < '0' || r > '9' })
+	if end == -1 {
+		end = len(rest)
+	}
+	return rest[:end], true
+}
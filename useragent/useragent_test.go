@@ -0,0 +1,78 @@
+package useragent_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sksmagr23/url-shortener-gofr/useragent"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected useragent.UserAgent
+	}{
+		{
+			name:     "Chrome on Windows",
+			header:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			expected: useragent.UserAgent{Browser: "Chrome", BrowserVersion: "124", OS: "Windows", Device: useragent.DeviceDesktop},
+		},
+		{
+			name:     "Edge on Windows",
+			header:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.51",
+			expected: useragent.UserAgent{Browser: "Edge", BrowserVersion: "124", OS: "Windows", Device: useragent.DeviceDesktop},
+		},
+		{
+			name:     "Safari on macOS",
+			header:   "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_4_1) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4.1 Safari/605.1.15",
+			expected: useragent.UserAgent{Browser: "Safari", BrowserVersion: "17", OS: "macOS", Device: useragent.DeviceDesktop},
+		},
+		{
+			name:     "Firefox on Linux",
+			header:   "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0",
+			expected: useragent.UserAgent{Browser: "Firefox", BrowserVersion: "125", OS: "Linux", Device: useragent.DeviceDesktop},
+		},
+		{
+			name:     "Safari on iPhone",
+			header:   "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+			expected: useragent.UserAgent{Browser: "Safari", BrowserVersion: "17", OS: "iOS", Device: useragent.DeviceMobile},
+		},
+		{
+			name:     "Chrome on iPad",
+			header:   "Mozilla/5.0 (iPad; CPU OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/124.0.6367.88 Mobile/15E148 Safari/604.1",
+			expected: useragent.UserAgent{Browser: "Chrome", BrowserVersion: "124", OS: "iOS", Device: useragent.DeviceTablet},
+		},
+		{
+			name:     "Samsung Internet on Android Phone",
+			header:   "Mozilla/5.0 (Linux; Android 14; SM-S918B) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/24.0 Chrome/117.0.0.0 Mobile Safari/537.36",
+			expected: useragent.UserAgent{Browser: "Samsung Internet", BrowserVersion: "24", OS: "Android", Device: useragent.DeviceMobile},
+		},
+		{
+			name:     "Chrome on Android Tablet",
+			header:   "Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			expected: useragent.UserAgent{Browser: "Chrome", BrowserVersion: "124", OS: "Android", Device: useragent.DeviceTablet},
+		},
+		{
+			name:     "Internet Explorer 11",
+			header:   "Mozilla/5.0 (Windows NT 10.0; WOW64; Trident/7.0; rv:11.0) like Gecko",
+			expected: useragent.UserAgent{Browser: "Internet Explorer", BrowserVersion: "11", OS: "Windows", Device: useragent.DeviceDesktop},
+		},
+		{
+			name:     "Crawler",
+			header:   "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			expected: useragent.UserAgent{Browser: useragent.Other, OS: useragent.Other, Device: useragent.DeviceBot},
+		},
+		{
+			name:     "Empty",
+			expected: useragent.UserAgent{Browser: useragent.Other, OS: useragent.Other, Device: useragent.DeviceBot},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, useragent.Parse(tt.header))
+		})
+	}
+}